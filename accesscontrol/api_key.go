@@ -0,0 +1,172 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &APIKey{}
+
+type apiKeyData struct {
+	metadata    map[string]interface{}
+	permissions []string
+}
+
+// APIKey represents an AC-APIKey object
+type APIKey struct {
+	keys   map[[sha256.Size]byte]apiKeyData
+	name   string
+	source *TokenSource
+}
+
+// NewAPIKey creates a new AC-APIKey object. The given keys map plain API keys to their metadata,
+// the optional file maps hex-encoded SHA-256 hashes of API keys to their metadata.
+func NewAPIKey(name string, source *TokenSource, keys map[string]map[string]interface{}, file string,
+	permissionsMap map[string][]string) (*APIKey, error) {
+	ak := &APIKey{
+		keys:   make(map[[sha256.Size]byte]apiKeyData),
+		name:   name,
+		source: source,
+	}
+
+	for key, metadata := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("keys: empty key")
+		}
+
+		if err := ak.add(sha256.Sum256([]byte(key)), metadata, permissionsMap); err != nil {
+			return nil, fmt.Errorf("keys: %w", err)
+		}
+	}
+
+	if file == "" {
+		return ak, nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashedKeys map[string]map[string]interface{}
+	if err = json.Unmarshal(b, &hashedKeys); err != nil {
+		return nil, fmt.Errorf("keys_file: invalid file content: %w", err)
+	}
+
+	for hash, metadata := range hashedKeys {
+		var digest [sha256.Size]byte
+		if len(hash) != hex.EncodedLen(sha256.Size) {
+			return nil, fmt.Errorf("keys_file: invalid SHA-256 hash: %q", hash)
+		}
+		if _, derr := hex.Decode(digest[:], []byte(hash)); derr != nil {
+			return nil, fmt.Errorf("keys_file: invalid SHA-256 hash: %q", hash)
+		}
+
+		if err = ak.add(digest, metadata, permissionsMap); err != nil {
+			return nil, fmt.Errorf("keys_file: %w", err)
+		}
+	}
+
+	return ak, nil
+}
+
+func (ak *APIKey) add(digest [sha256.Size]byte, metadata map[string]interface{}, permissionsMap map[string][]string) error {
+	if _, exist := ak.keys[digest]; exist {
+		return fmt.Errorf("duplicate key: %s", hex.EncodeToString(digest[:]))
+	}
+
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+
+	permissions, err := getMetadataPermissions(metadata["permissions"])
+	if err != nil {
+		return err
+	}
+
+	ak.keys[digest] = apiKeyData{
+		metadata:    metadata,
+		permissions: addMappedPermissions(permissionsMap, permissions, permissions),
+	}
+	return nil
+}
+
+// getMetadataPermissions reads the permissions metadata field which must either be a string
+// containing a space-separated list of permissions or a list of string permissions.
+func getMetadataPermissions(value interface{}) ([]string, error) {
+	var permissions []string
+
+	switch v := value.(type) {
+	case nil:
+	case string:
+		for _, p := range strings.Split(v, " ") {
+			permissions, _ = addPermission(permissions, p)
+		}
+	case []interface{}:
+		for _, item := range v {
+			p, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid permissions value: %#v", value)
+			}
+			permissions, _ = addPermission(permissions, p)
+		}
+	default:
+		return nil, fmt.Errorf("invalid permissions value: %#v", value)
+	}
+
+	return permissions, nil
+}
+
+// Validate implements the AccessControl interface
+func (ak *APIKey) Validate(req *http.Request) error {
+	key, err := ak.source.TokenValue(req)
+	if err != nil {
+		return errors.ApiKeyMissing.With(err)
+	}
+
+	// the hash comparison does not leak the timing of the key comparison
+	data, exist := ak.keys[sha256.Sum256([]byte(key))]
+	if !exist {
+		return errors.ApiKey.Message("invalid api key")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[ak.name] = data.metadata
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	// the key must not be passed upstream
+	switch ak.source.tsType {
+	case cookieType:
+		cookies, _ := ctx.Value(request.CredentialCookies).([]string)
+		ctx = context.WithValue(ctx, request.CredentialCookies, append(cookies, ak.source.name))
+	case headerType:
+		headers, _ := ctx.Value(request.CredentialHeaders).([]string)
+		ctx = context.WithValue(ctx, request.CredentialHeaders, append(headers, ak.source.name))
+	case queryType:
+		params, _ := ctx.Value(request.CredentialQueryParams).([]string)
+		ctx = context.WithValue(ctx, request.CredentialQueryParams, append(params, ak.source.name))
+	}
+
+	if len(data.permissions) > 0 {
+		alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
+		grantedPermissions := append(alreadyGrantedPermissions, data.permissions...)
+		ctx = context.WithValue(ctx, request.GrantedPermissions, grantedPermissions)
+	}
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_NewAPIKey(t *testing.T) {
+	source, err := ac.NewKeySource("", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		keys      map[string]map[string]interface{}
+		file      string
+		expErrMsg string
+	}{
+		{"inline", map[string]map[string]interface{}{"key": nil}, "", ""},
+		{"file", nil, "testdata/api_keys.json", ""},
+		{"empty key", map[string]map[string]interface{}{"": nil}, "", "keys: empty key"},
+		{"duplicate", map[string]map[string]interface{}{"file-key": nil}, "testdata/api_keys.json", "keys_file: duplicate key: a0cc538968c3de95e887314617b79289de0f999e78c8f7eef949463a96055b52"},
+		{"invalid hash", nil, "testdata/api_keys_invalid.json", `keys_file: invalid SHA-256 hash: "not-a-hash"`},
+		{"invalid permissions", map[string]map[string]interface{}{"key": {"permissions": 1}}, "", "keys: invalid permissions value: 1"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewAPIKey("ak", source, tc.keys, tc.file, nil)
+			if tc.expErrMsg == "" && err != nil {
+				st.Fatal(err)
+			}
+			if tc.expErrMsg != "" && (err == nil || err.Error() != tc.expErrMsg) {
+				st.Errorf("want error %q, got %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
+
+func Test_NewKeySource(t *testing.T) {
+	if _, err := ac.NewKeySource("c", "h", ""); err == nil || err.Error() != "only one of cookie, header or query_param attributes is allowed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIKey_Validate(t *testing.T) {
+	keys := map[string]map[string]interface{}{
+		"inline-key": {"client": "inline-client", "permissions": []interface{}{"read"}},
+	}
+	permissionsMap := map[string][]string{"write": {"delete"}}
+
+	for _, tc := range []struct {
+		name           string
+		cookie, header string
+		query          string
+		setup          func(*http.Request)
+		expErr         *errors.Error
+		expMetadata    map[string]interface{}
+		expPermissions []string
+	}{
+		{"default header", "", "", "", func(r *http.Request) { r.Header.Set("X-Api-Key", "inline-key") }, nil,
+			map[string]interface{}{"client": "inline-client", "permissions": []interface{}{"read"}}, []string{"read"}},
+		{"hashed key", "", "", "", func(r *http.Request) { r.Header.Set("X-Api-Key", "file-key") }, nil,
+			map[string]interface{}{"client": "file-client", "permissions": "read write"}, []string{"read", "write", "delete"}},
+		{"query param", "", "", "api_key", func(r *http.Request) { r.URL.RawQuery = "api_key=inline-key" }, nil,
+			map[string]interface{}{"client": "inline-client", "permissions": []interface{}{"read"}}, []string{"read"}},
+		{"cookie", "key", "", "", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "key", Value: "file-key"}) }, nil,
+			map[string]interface{}{"client": "file-client", "permissions": "read write"}, []string{"read", "write", "delete"}},
+		{"missing", "", "Api-Key", "", func(r *http.Request) { r.Header.Set("X-Api-Key", "inline-key") }, errors.ApiKeyMissing, nil, nil},
+		{"invalid", "", "", "", func(r *http.Request) { r.Header.Set("X-Api-Key", "unknown") }, errors.ApiKey, nil, nil},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			source, err := ac.NewKeySource(tc.cookie, tc.header, tc.query)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			apiKey, err := ac.NewAPIKey("ak", source, keys, "testdata/api_keys.json", permissionsMap)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.Background())
+			tc.setup(req)
+
+			err = apiKey.Validate(req)
+			if tc.expErr != nil {
+				gerr, ok := err.(*errors.Error)
+				if !ok || gerr.Kinds()[0] != tc.expErr.Kinds()[0] {
+					st.Errorf("want error kind %v, got %v", tc.expErr.Kinds(), err)
+				}
+				return
+			}
+
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			if !reflect.DeepEqual(acMap["ak"], tc.expMetadata) {
+				st.Errorf("want metadata %#v, got %#v", tc.expMetadata, acMap["ak"])
+			}
+
+			permissions, _ := req.Context().Value(request.GrantedPermissions).([]string)
+			if !reflect.DeepEqual(permissions, tc.expPermissions) {
+				st.Errorf("want permissions %v, got %v", tc.expPermissions, permissions)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+)
+
+var _ AccessControl = &Authorization{}
+
+const (
+	authorizationEffectAllow = "allow"
+	authorizationEffectDeny  = "deny"
+)
+
+type authorizationRule struct {
+	allow     bool
+	condition hcl.Expression
+	name      string
+}
+
+// Authorization represents an AC-Authorization object which decides about access
+// by evaluating rules over the client request and the context of preceding access controls.
+type Authorization struct {
+	defaultAllow bool
+	logDecisions bool
+	name         string
+	order        string
+	rules        []authorizationRule
+}
+
+// NewAuthorization creates a new AC-Authorization object.
+func NewAuthorization(conf *config.Authorization) (*Authorization, error) {
+	if len(conf.Rules) == 0 {
+		return nil, fmt.Errorf("at least one rule block is required")
+	}
+
+	defaultAllow, err := isAllowEffect("default_effect", conf.DefaultEffect, authorizationEffectDeny)
+	if err != nil {
+		return nil, err
+	}
+
+	order := conf.Order
+	switch order {
+	case "":
+		order = config.AuthorizationOrderFirstMatch
+	case config.AuthorizationOrderAllowFirst, config.AuthorizationOrderDenyFirst, config.AuthorizationOrderFirstMatch:
+	default:
+		return nil, fmt.Errorf("order: unsupported value %q", order)
+	}
+
+	var rules []authorizationRule
+	ruleNames := make(map[string]struct{})
+	for _, ruleConf := range conf.Rules {
+		if _, exists := ruleNames[ruleConf.Name]; exists {
+			return nil, fmt.Errorf("rule %q: duplicate rule name", ruleConf.Name)
+		}
+		ruleNames[ruleConf.Name] = struct{}{}
+
+		allow, err := isAllowEffect(fmt.Sprintf("rule %q: effect", ruleConf.Name), ruleConf.Effect, "")
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, authorizationRule{
+			allow:     allow,
+			condition: ruleConf.Condition,
+			name:      ruleConf.Name,
+		})
+	}
+
+	return &Authorization{
+		defaultAllow: defaultAllow,
+		logDecisions: conf.LogDecisions,
+		name:         conf.Name,
+		order:        order,
+		rules:        rules,
+	}, nil
+}
+
+func isAllowEffect(attrName, effect, defaultEffect string) (bool, error) {
+	if effect == "" {
+		effect = defaultEffect
+	}
+
+	switch effect {
+	case authorizationEffectAllow:
+		return true, nil
+	case authorizationEffectDeny:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s: unsupported value %q", attrName, effect)
+	}
+}
+
+// Validate implements the AccessControl interface
+func (a *Authorization) Validate(req *http.Request) error {
+	match, err := a.match(req)
+	if err != nil {
+		return err
+	}
+
+	allow := a.defaultAllow
+	info := make(map[string]interface{})
+	if match != nil {
+		allow = match.allow
+		info["rule"] = match.name
+	}
+	if allow {
+		info["decision"] = authorizationEffectAllow
+	} else {
+		info["decision"] = authorizationEffectDeny
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[a.name] = info
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+
+	// the decision is also provided to error handlers
+	evalCtx := eval.ContextFromRequest(req)
+	*req = *req.WithContext(evalCtx.WithClientRequest(req))
+
+	if a.logDecisions {
+		a.logDecision(req, info)
+	}
+
+	if allow {
+		return nil
+	}
+
+	if match != nil {
+		return errors.Authorization.Messagef("access denied by rule %q", match.name)
+	}
+	return errors.Authorization.Message("access denied, no rule matched")
+}
+
+// match returns the rule deciding about the request depending on the order:
+// With "first_match" the first matching rule decides. With "deny_first"
+// ("allow_first") a matching deny (allow) rule overrides all allow (deny) rules.
+func (a *Authorization) match(req *http.Request) (*authorizationRule, error) {
+	hclCtx := eval.ContextFromRequest(req).HCLContext()
+
+	var match *authorizationRule
+	for i := range a.rules {
+		rule := &a.rules[i]
+
+		val, err := eval.Value(hclCtx, rule.condition)
+		if err != nil {
+			return nil, errors.Evaluation.Messagef("rule %q", rule.name).With(err)
+		}
+
+		// e.g. a missing claim must not skip a deny rule
+		if !val.IsKnown() || val.IsNull() {
+			return nil, errors.Evaluation.Messagef("rule %q: condition must evaluate to bool, got null", rule.name)
+		}
+		if val.Type() != cty.Bool {
+			return nil, errors.Evaluation.Messagef("rule %q: condition must evaluate to bool, got %s", rule.name, val.Type().FriendlyName())
+		}
+		if val.False() {
+			continue
+		}
+
+		switch a.order {
+		case config.AuthorizationOrderFirstMatch:
+			return rule, nil
+		case config.AuthorizationOrderAllowFirst:
+			if rule.allow {
+				return rule, nil
+			}
+		case config.AuthorizationOrderDenyFirst:
+			if !rule.allow {
+				return rule, nil
+			}
+		}
+
+		if match == nil {
+			match = rule
+		}
+	}
+
+	return match, nil
+}
+
+func (a *Authorization) logDecision(req *http.Request, info map[string]interface{}) {
+	log, ok := req.Context().Value(request.LogEntry).(*logrus.Entry)
+	if !ok {
+		return
+	}
+
+	fields := logrus.Fields{
+		"name":     a.name,
+		"decision": info["decision"],
+		"method":   req.Method,
+		"path":     req.URL.Path,
+	}
+	if rule, exists := info["rule"]; exists {
+		fields["rule"] = rule
+	}
+
+	log.WithContext(req.Context()).WithField("authorization", fields).Info("policy decision")
+}
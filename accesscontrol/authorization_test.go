@@ -0,0 +1,182 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+)
+
+func TestNewAuthorization(t *testing.T) {
+	condition, diags := hclsyntax.ParseExpression([]byte(`true`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		conf   *config.Authorization
+		expErr string
+	}{
+		{"valid", &config.Authorization{Rules: []*config.AuthorizationRule{{Name: "a", Effect: "allow", Condition: condition}}}, ""},
+		{"no rules", &config.Authorization{}, "at least one rule block is required"},
+		{"invalid order", &config.Authorization{Order: "random", Rules: []*config.AuthorizationRule{{Name: "a", Effect: "allow", Condition: condition}}}, `order: unsupported value "random"`},
+		{"invalid default effect", &config.Authorization{DefaultEffect: "maybe", Rules: []*config.AuthorizationRule{{Name: "a", Effect: "allow", Condition: condition}}}, `default_effect: unsupported value "maybe"`},
+		{"invalid effect", &config.Authorization{Rules: []*config.AuthorizationRule{{Name: "a", Effect: "permit", Condition: condition}}}, `rule "a": effect: unsupported value "permit"`},
+		{"duplicate rule", &config.Authorization{Rules: []*config.AuthorizationRule{
+			{Name: "a", Effect: "allow", Condition: condition},
+			{Name: "a", Effect: "deny", Condition: condition},
+		}}, `rule "a": duplicate rule name`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewAuthorization(tc.conf)
+			if tc.expErr == "" {
+				if err != nil {
+					st.Error(err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("want error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestAuthorization_Validate(t *testing.T) {
+	type rule struct{ name, effect, condition string }
+
+	// all rules match a request with the query parameter "match"
+	allowRule := rule{"allow", "allow", `request.query.match[0] == "allow" || request.query.match[0] == "both"`}
+	denyRule := rule{"deny", "deny", `request.query.match[0] == "deny" || request.query.match[0] == "both"`}
+
+	for _, tc := range []struct {
+		name          string
+		order         string
+		defaultEffect string
+		rules         []rule
+		match         string
+		expDecision   string
+		expRule       string
+	}{
+		{"first match: allow", "", "", []rule{allowRule, denyRule}, "allow", "allow", "allow"},
+		{"first match: deny", "", "", []rule{allowRule, denyRule}, "deny", "deny", "deny"},
+		{"first match: both", "", "", []rule{allowRule, denyRule}, "both", "allow", "allow"},
+		{"first match: both, reversed", "", "", []rule{denyRule, allowRule}, "both", "deny", "deny"},
+		{"first match: none", "", "", []rule{allowRule, denyRule}, "none", "deny", ""},
+		{"first match: none, default allow", "", "allow", []rule{allowRule, denyRule}, "none", "allow", ""},
+		{"deny first: both", "deny_first", "", []rule{allowRule, denyRule}, "both", "deny", "deny"},
+		{"deny first: allow", "deny_first", "", []rule{allowRule, denyRule}, "allow", "allow", "allow"},
+		{"allow first: both", "allow_first", "", []rule{denyRule, allowRule}, "both", "allow", "allow"},
+		{"allow first: deny", "allow_first", "", []rule{denyRule, allowRule}, "deny", "deny", "deny"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			conf := &config.Authorization{
+				DefaultEffect: tc.defaultEffect,
+				Name:          "authz",
+				Order:         tc.order,
+			}
+			for _, r := range tc.rules {
+				condition, diags := hclsyntax.ParseExpression([]byte(r.condition), "test.hcl", hcl.InitialPos)
+				if diags.HasErrors() {
+					st.Fatal(diags)
+				}
+				conf.Rules = append(conf.Rules, &config.AuthorizationRule{Condition: condition, Effect: r.effect, Name: r.name})
+			}
+
+			authz, err := ac.NewAuthorization(conf)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/?match="+tc.match, nil)
+			*req = *req.WithContext(eval.NewContext(nil, nil, "").WithClientRequest(req))
+
+			err = authz.Validate(req)
+			if tc.expDecision == "allow" && err != nil {
+				st.Fatalf("expected no error, got %v", err)
+			}
+			if tc.expDecision == "deny" {
+				if gErr, ok := err.(errors.GoError); !ok || !strings.Contains(gErr.LogError(), "access denied") {
+					st.Fatalf("expected authorization error, got %v", err)
+				}
+			}
+
+			acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+			info := acMap["authz"].(map[string]interface{})
+			if info["decision"] != tc.expDecision {
+				st.Errorf("want decision %q, got %q", tc.expDecision, info["decision"])
+			}
+			if rule, _ := info["rule"].(string); rule != tc.expRule {
+				st.Errorf("want rule %q, got %q", tc.expRule, rule)
+			}
+		})
+	}
+}
+
+func TestAuthorization_ValidateNullCondition(t *testing.T) {
+	deny, diags := hclsyntax.ParseExpression([]byte(`request.context.token.blocked`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	authz, err := ac.NewAuthorization(&config.Authorization{
+		DefaultEffect: "allow",
+		Name:          "authz",
+		Rules:         []*config.AuthorizationRule{{Name: "blocked", Effect: "deny", Condition: deny}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the token has no "blocked" claim
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := context.WithValue(req.Context(), request.AccessControls, map[string]interface{}{
+		"token": map[string]interface{}{"sub": "me"},
+	})
+	*req = *req.WithContext(eval.NewContext(nil, nil, "").WithClientRequest(req.WithContext(ctx)))
+
+	err = authz.Validate(req)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if exp := `expression evaluation error: rule "blocked": condition must evaluate to bool, got null`; err.(errors.GoError).LogError() != exp {
+		t.Errorf("\nwant:\t%q\ngot:\t%q", exp, err.(errors.GoError).LogError())
+	}
+}
+
+func TestAuthorization_ValidateNonBoolCondition(t *testing.T) {
+	condition, diags := hclsyntax.ParseExpression([]byte(`request.method`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	authz, err := ac.NewAuthorization(&config.Authorization{
+		Name:  "authz",
+		Rules: []*config.AuthorizationRule{{Name: "method", Effect: "allow", Condition: condition}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	*req = *req.WithContext(eval.NewContext(nil, nil, "").WithClientRequest(req))
+
+	err = authz.Validate(req)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if exp := `expression evaluation error: rule "method": condition must evaluate to bool, got string`; err.(errors.GoError).LogError() != exp {
+		t.Errorf("\nwant:\t%q\ngot:\t%q", exp, err.(errors.GoError).LogError())
+	}
+}
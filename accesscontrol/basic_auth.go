@@ -9,6 +9,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
@@ -16,9 +18,12 @@ import (
 
 var _ AccessControl = &BasicAuth{}
 
+// htFileCheckInterval is the minimum duration between two checks for changes of the htpasswd file.
+var htFileCheckInterval = time.Second
+
 // BasicAuth represents an AC-BasicAuth object
 type BasicAuth struct {
-	htFile htData
+	htFile *htFile
 	name   string
 	user   string
 	pass   string
@@ -27,22 +32,84 @@ type BasicAuth struct {
 // NewBasicAuth creates a new AC-BasicAuth object
 func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 	ba := &BasicAuth{
-		htFile: make(htData),
-		name:   name,
-		user:   user,
-		pass:   pass,
+		name: name,
+		user: user,
+		pass: pass,
 	}
 
 	if file == "" {
 		return ba, nil
 	}
 
-	fp, err := os.Open(file)
+	ba.htFile = &htFile{path: file}
+	if err := ba.htFile.load(); err != nil {
+		return nil, err
+	}
+
+	return ba, nil
+}
+
+// htFile holds the data of an htpasswd file which is reloaded on changes.
+type htFile struct {
+	data      htData
+	err       error
+	lastCheck time.Time
+	modTime   time.Time
+	mu        sync.RWMutex
+	path      string
+	size      int64
+}
+
+// Data returns the current htpasswd data and reloads the file if its
+// modification time or size has changed.
+func (h *htFile) Data() (htData, error) {
+	h.mu.RLock()
+	if time.Since(h.lastCheck) < htFileCheckInterval {
+		defer h.mu.RUnlock()
+		return h.data, h.err
+	}
+	h.mu.RUnlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Since(h.lastCheck) < htFileCheckInterval { // concurrent reload
+		return h.data, h.err
+	}
+
+	info, err := os.Stat(h.path)
 	if err != nil {
+		h.data, h.err, h.lastCheck = nil, err, time.Now()
 		return nil, err
 	}
+
+	if h.err == nil && info.ModTime().Equal(h.modTime) && info.Size() == h.size {
+		h.lastCheck = time.Now()
+		return h.data, nil
+	}
+
+	if err = h.load(); err != nil {
+		// do not keep former credentials with a broken file
+		h.data, h.err = nil, err
+	}
+	return h.data, h.err
+}
+
+func (h *htFile) load() error {
+	h.lastCheck = time.Now()
+
+	fp, err := os.Open(h.path)
+	if err != nil {
+		return err
+	}
 	defer fp.Close()
 
+	info, err := fp.Stat()
+	if err != nil {
+		return err
+	}
+
+	data := make(htData)
 	scanner := bufio.NewScanner(fp)
 	var lineNr int
 	for scanner.Scan() {
@@ -53,52 +120,36 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 		}
 
 		if len(line) > 255 {
-			return nil, fmt.Errorf("parse error: line length exceeded: 255")
+			return fmt.Errorf("parse error: line length exceeded: 255")
 		}
 
 		up := strings.SplitN(line, ":", 2)
 		if len(up) != 2 {
-			return nil, fmt.Errorf("parse error: invalid line: " + strconv.Itoa(lineNr))
+			return fmt.Errorf("parse error: invalid line: " + strconv.Itoa(lineNr))
 		}
 
 		username, password := up[0], up[1]
 
-		if _, ok := ba.htFile[username]; ok {
-			return nil, fmt.Errorf("multiple user: " + username)
+		if _, ok := data[username]; ok {
+			return fmt.Errorf("multiple user: " + username)
 		}
 
-		switch pwdType := getPwdType(password); pwdType {
-		case pwdTypeApr1:
-			fallthrough
-		case pwdTypeMD5:
-			prefix := pwdPrefixApr1
-			if pwdType == pwdTypeMD5 {
-				prefix = pwdPrefixMD5
-			}
-
-			parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
-			if len(parts) != 2 {
-				return nil, fmt.Errorf("parse error: malformed password for user: " + username)
-			}
-
-			ba.htFile[username] = pwd{
-				pwdOrig:   []byte(password),
-				pwdPrefix: prefix,
-				pwdSalt:   parts[0],
-				pwdType:   pwdType,
-			}
-		case pwdTypeBcrypt:
-			ba.htFile[username] = pwd{
-				pwdOrig: []byte(password),
-				pwdType: pwdType,
-			}
-		default:
-			return nil, fmt.Errorf("parse error: algorithm not supported")
+		p, err := newPwd(password)
+		if err == errMalformedPwd {
+			return fmt.Errorf("parse error: malformed password for user: " + username)
+		} else if err != nil {
+			return err
 		}
+		data[username] = p
 	}
 
-	err = scanner.Err()
-	return ba, err
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	h.data, h.err = data, nil
+	h.modTime, h.size = info.ModTime(), info.Size()
+	return nil
 }
 
 // Validate implements the AccessControl interface
@@ -116,26 +167,32 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return errors.BasicAuth.Message("reading authorization failed")
 	}
 
-	if ba.user == user {
-		if ba.pass != "" {
-			if subtle.ConstantTimeCompare([]byte(ba.pass), []byte(pass)) == 1 {
-				return ba.withUsername(req, user)
-			}
-			return errors.BasicAuth.Message("credential mismatch")
+	// the inline credentials are independent of the state of the htpasswd file
+	if ba.user == user && ba.pass != "" {
+		if subtle.ConstantTimeCompare([]byte(ba.pass), []byte(pass)) == 1 {
+			return ba.withUsername(req, user)
 		}
+		return errors.BasicAuth.Message("credential mismatch")
+	}
 
-		if len(ba.htFile) == 0 {
-			return errors.BasicAuth.Message("no password configured")
+	var data htData
+	if ba.htFile != nil {
+		var err error
+		if data, err = ba.htFile.Data(); err != nil {
+			return errors.BasicAuth.Message("file").With(err)
 		}
 	}
 
-	if len(ba.htFile) > 0 {
-		if validateAccessData(user, pass, ba.htFile) {
+	if len(data) > 0 {
+		if validateAccessData(user, pass, data) {
 			return ba.withUsername(req, user)
 		}
 		return errors.BasicAuth.Message("file: credential mismatch")
 	}
 
+	if ba.user == user {
+		return errors.BasicAuth.Message("no password configured")
+	}
 	return errors.BasicAuth.Message("credential mismatch")
 }
 
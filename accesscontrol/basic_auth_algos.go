@@ -3,26 +3,49 @@ package accesscontrol
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
 	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
-	pwdPrefixApr1     = "$apr1$"
-	pwdPrefixBcrypt2a = "$2a$"
-	pwdPrefixBcrypt2b = "$2b$"
-	pwdPrefixBcrypt2x = "$2x$"
-	pwdPrefixBcrypt2y = "$2y$"
-	pwdPrefixMD5      = "$1$"
+	pwdPrefixApr1         = "$apr1$"
+	pwdPrefixArgon2i      = "$argon2i$"
+	pwdPrefixArgon2id     = "$argon2id$"
+	pwdPrefixBcrypt2a     = "$2a$"
+	pwdPrefixBcrypt2b     = "$2b$"
+	pwdPrefixBcrypt2x     = "$2x$"
+	pwdPrefixBcrypt2y     = "$2y$"
+	pwdPrefixMD5          = "$1$"
+	pwdPrefixPBKDF2SHA1   = "$pbkdf2$"
+	pwdPrefixPBKDF2SHA256 = "$pbkdf2-sha256$"
+	pwdPrefixPBKDF2SHA512 = "$pbkdf2-sha512$"
+	pwdPrefixSHA          = "{SHA}"
+	pwdPrefixSHA256Crypt  = "$5$"
+	pwdPrefixSHA512Crypt  = "$6$"
 )
 
 const (
 	pwdTypeUnknown = iota
 	pwdTypeApr1
+	pwdTypeArgon2i
+	pwdTypeArgon2id
 	pwdTypeBcrypt
 	pwdTypeMD5
+	pwdTypePBKDF2
+	pwdTypeSHA
+	pwdTypeSHA256Crypt
+	pwdTypeSHA512Crypt
 )
 
 const (
@@ -31,32 +54,191 @@ const (
 	aprMuddleRounds  = 1000
 )
 
+const (
+	shaCryptMaxRounds     = 999999999
+	shaCryptMaxSaltLength = 16
+	shaCryptMinRounds     = 1000
+	shaCryptRounds        = 5000
+	shaCryptRoundsPrefix  = "rounds="
+)
+
 var pwdPrefixes = map[string]int{
-	pwdPrefixApr1:     pwdTypeApr1,
-	pwdPrefixBcrypt2a: pwdTypeBcrypt,
-	pwdPrefixBcrypt2b: pwdTypeBcrypt,
-	pwdPrefixBcrypt2x: pwdTypeBcrypt,
-	pwdPrefixBcrypt2y: pwdTypeBcrypt,
-	pwdPrefixMD5:      pwdTypeMD5,
+	pwdPrefixApr1:         pwdTypeApr1,
+	pwdPrefixArgon2i:      pwdTypeArgon2i,
+	pwdPrefixArgon2id:     pwdTypeArgon2id,
+	pwdPrefixBcrypt2a:     pwdTypeBcrypt,
+	pwdPrefixBcrypt2b:     pwdTypeBcrypt,
+	pwdPrefixBcrypt2x:     pwdTypeBcrypt,
+	pwdPrefixBcrypt2y:     pwdTypeBcrypt,
+	pwdPrefixMD5:          pwdTypeMD5,
+	pwdPrefixPBKDF2SHA1:   pwdTypePBKDF2,
+	pwdPrefixPBKDF2SHA256: pwdTypePBKDF2,
+	pwdPrefixPBKDF2SHA512: pwdTypePBKDF2,
+	pwdPrefixSHA:          pwdTypeSHA,
+	pwdPrefixSHA256Crypt:  pwdTypeSHA256Crypt,
+	pwdPrefixSHA512Crypt:  pwdTypeSHA512Crypt,
+}
+
+var pbkdf2HashFuncs = map[string]func() hash.Hash{
+	pwdPrefixPBKDF2SHA1:   sha1.New,
+	pwdPrefixPBKDF2SHA256: sha256.New,
+	pwdPrefixPBKDF2SHA512: sha512.New,
 }
 
+// abase64 is the base64 variant of passlib's PBKDF2 hashes: "." instead of "+" and no padding.
+var abase64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
 type htData map[string]pwd
 
 type pwd struct {
-	pwdOrig   []byte
-	pwdPrefix string
-	pwdSalt   string
-	pwdType   int
+	pwdHash     []byte // decoded hash of argon2, PBKDF2 and {SHA} passwords
+	pwdHashFunc func() hash.Hash
+	pwdMemory   uint32
+	pwdOrig     []byte
+	pwdPrefix   string
+	pwdRounds   int
+	pwdSalt     string
+	pwdThreads  uint8
+	pwdType     int
 }
 
-func getPwdType(pass string) int {
+func getPwdType(pass string) (string, int) {
 	for p, t := range pwdPrefixes {
 		if strings.HasPrefix(pass, p) {
-			return t
+			return p, t
+		}
+	}
+
+	return "", pwdTypeUnknown
+}
+
+// newPwd parses the given htpasswd password field.
+func newPwd(password string) (pwd, error) {
+	prefix, pwdType := getPwdType(password)
+	p := pwd{
+		pwdOrig:   []byte(password),
+		pwdPrefix: prefix,
+		pwdType:   pwdType,
+	}
+
+	var err error
+	switch pwdType {
+	case pwdTypeApr1:
+		fallthrough
+	case pwdTypeMD5:
+		parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
+		if len(parts) != 2 {
+			return p, errMalformedPwd
+		}
+		p.pwdSalt = parts[0]
+	case pwdTypeArgon2i:
+		fallthrough
+	case pwdTypeArgon2id:
+		err = parseArgon2(&p)
+	case pwdTypeBcrypt:
+	case pwdTypePBKDF2:
+		err = parsePBKDF2(&p)
+	case pwdTypeSHA:
+		p.pwdHash, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(password, prefix))
+		if err != nil || len(p.pwdHash) != sha1.Size {
+			err = errMalformedPwd
+		}
+	case pwdTypeSHA256Crypt:
+		fallthrough
+	case pwdTypeSHA512Crypt:
+		err = parseSHACrypt(&p)
+	default:
+		return p, fmt.Errorf("parse error: algorithm not supported")
+	}
+
+	return p, err
+}
+
+var errMalformedPwd = fmt.Errorf("malformed password")
+
+// parseArgon2 parses the PHC string format: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+func parseArgon2(p *pwd) error {
+	parts := strings.Split(strings.TrimPrefix(string(p.pwdOrig), p.pwdPrefix), "$")
+	if len(parts) != 4 || parts[0] != fmt.Sprintf("v=%d", argon2.Version) {
+		return errMalformedPwd
+	}
+
+	var threads uint8
+	if n, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &p.pwdMemory, &p.pwdRounds, &threads); err != nil || n != 3 ||
+		p.pwdRounds < 1 || threads < 1 {
+		return errMalformedPwd
+	}
+	p.pwdThreads = threads
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errMalformedPwd
+	}
+	p.pwdSalt = string(salt)
+
+	p.pwdHash, err = base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(p.pwdHash) == 0 {
+		return errMalformedPwd
+	}
+
+	return nil
+}
+
+// parsePBKDF2 parses the passlib format: $pbkdf2-<digest>$<rounds>$<salt>$<hash>
+func parsePBKDF2(p *pwd) error {
+	parts := strings.Split(strings.TrimPrefix(string(p.pwdOrig), p.pwdPrefix), "$")
+	if len(parts) != 3 {
+		return errMalformedPwd
+	}
+
+	var err error
+	if p.pwdRounds, err = strconv.Atoi(parts[0]); err != nil || p.pwdRounds < 1 {
+		return errMalformedPwd
+	}
+
+	salt, err := abase64.DecodeString(parts[1])
+	if err != nil {
+		return errMalformedPwd
+	}
+	p.pwdSalt = string(salt)
+
+	p.pwdHash, err = abase64.DecodeString(parts[2])
+	if err != nil || len(p.pwdHash) == 0 {
+		return errMalformedPwd
+	}
+
+	p.pwdHashFunc = pbkdf2HashFuncs[p.pwdPrefix]
+	return nil
+}
+
+// parseSHACrypt parses the SHA-crypt format: $5$[rounds=<rounds>$]<salt>$<hash>
+// The optional rounds parameter becomes part of the prefix. Like glibc, longer salts
+// are truncated to 16 characters.
+func parseSHACrypt(p *pwd) error {
+	parts := strings.Split(strings.TrimPrefix(string(p.pwdOrig), p.pwdPrefix), "$")
+	p.pwdRounds = shaCryptRounds
+	if len(parts) == 3 && strings.HasPrefix(parts[0], shaCryptRoundsPrefix) {
+		rounds, err := strconv.Atoi(strings.TrimPrefix(parts[0], shaCryptRoundsPrefix))
+		if err != nil || rounds < shaCryptMinRounds || rounds > shaCryptMaxRounds {
+			return errMalformedPwd
 		}
+		p.pwdPrefix += parts[0] + "$"
+		p.pwdRounds = rounds
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 {
+		return errMalformedPwd
+	}
+
+	p.pwdSalt = parts[0]
+	if len(p.pwdSalt) > shaCryptMaxSaltLength {
+		p.pwdSalt = p.pwdSalt[:shaCryptMaxSaltLength]
+		// the computed hash contains the truncated salt
+		p.pwdOrig = []byte(p.pwdPrefix + p.pwdSalt + "$" + parts[1])
 	}
 
-	return pwdTypeUnknown
+	return nil
 }
 
 func validateAccessData(plainUser, plainPass string, data htData) bool {
@@ -73,6 +255,34 @@ func validateAccessData(plainUser, plainPass string, data htData) bool {
 				if err := bcrypt.CompareHashAndPassword(pass.pwdOrig, []byte(plainPass)); err == nil {
 					return true
 				}
+			case pwdTypeArgon2i:
+				sum := argon2.Key([]byte(plainPass), []byte(pass.pwdSalt), uint32(pass.pwdRounds), pass.pwdMemory, pass.pwdThreads, uint32(len(pass.pwdHash)))
+				if subtle.ConstantTimeCompare(sum, pass.pwdHash) == 1 {
+					return true
+				}
+			case pwdTypeArgon2id:
+				sum := argon2.IDKey([]byte(plainPass), []byte(pass.pwdSalt), uint32(pass.pwdRounds), pass.pwdMemory, pass.pwdThreads, uint32(len(pass.pwdHash)))
+				if subtle.ConstantTimeCompare(sum, pass.pwdHash) == 1 {
+					return true
+				}
+			case pwdTypePBKDF2:
+				sum := pbkdf2.Key([]byte(plainPass), []byte(pass.pwdSalt), pass.pwdRounds, len(pass.pwdHash), pass.pwdHashFunc)
+				if subtle.ConstantTimeCompare(sum, pass.pwdHash) == 1 {
+					return true
+				}
+			case pwdTypeSHA:
+				sum := sha1.Sum([]byte(plainPass))
+				if subtle.ConstantTimeCompare(sum[:], pass.pwdHash) == 1 {
+					return true
+				}
+			case pwdTypeSHA256Crypt:
+				if subtle.ConstantTimeCompare(shaCrypt(sha256.New, plainPass, pass.pwdSalt, pass.pwdPrefix, pass.pwdRounds), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA512Crypt:
+				if subtle.ConstantTimeCompare(shaCrypt(sha512.New, plainPass, pass.pwdSalt, pass.pwdPrefix, pass.pwdRounds), pass.pwdOrig) == 1 {
+					return true
+				}
 			}
 		}
 	}
@@ -164,3 +374,109 @@ func apr1MD5(pass, salt, pref string) []byte {
 
 	return buf.Bytes()
 }
+
+// shaCrypt implements the SHA-256 and SHA-512 based crypt algorithms,
+// see https://www.akkadia.org/drepper/SHA-crypt.txt.
+func shaCrypt(newHash func() hash.Hash, pass, salt, pref string, rounds int) []byte {
+	p, s := []byte(pass), []byte(salt)
+
+	h := newHash()
+	h.Write(p)
+	h.Write(s)
+	h.Write(p)
+	altSum := h.Sum(nil)
+	size := len(altSum)
+
+	h.Reset()
+	h.Write(p)
+	h.Write(s)
+	for i := len(p); i > 0; i -= size {
+		if i > size {
+			h.Write(altSum)
+		} else {
+			h.Write(altSum[0:i])
+		}
+	}
+	for i := len(p); i > 0; i >>= 1 {
+		if (i & 1) == 1 {
+			h.Write(altSum)
+		} else {
+			h.Write(p)
+		}
+	}
+	sum := h.Sum(nil)
+
+	h.Reset()
+	for i := 0; i < len(p); i++ {
+		h.Write(p)
+	}
+	pSeq := repeatBytes(h.Sum(nil), len(p))
+
+	h.Reset()
+	for i := 0; i < 16+int(sum[0]); i++ {
+		h.Write(s)
+	}
+	sSeq := repeatBytes(h.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+
+		if (i & 1) == 1 {
+			h.Write(pSeq)
+		} else {
+			h.Write(sum)
+		}
+
+		if (i % 3) != 0 {
+			h.Write(sSeq)
+		}
+
+		if (i % 7) != 0 {
+			h.Write(pSeq)
+		}
+
+		if (i & 1) == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(pSeq)
+		}
+
+		sum = h.Sum(sum[:0])
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(pref)
+	buf.WriteString(salt)
+	buf.WriteByte('$')
+
+	add := func(a, b, c byte, n int) {
+		v := (uint(a) << 16) + (uint(b) << 8) + uint(c)
+		for i := 0; i < n; i++ {
+			buf.WriteByte(aprCharacters[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	if size == sha256.Size {
+		for i := 0; i < 10; i++ {
+			add(sum[(i*21)%30], sum[(i*21+10)%30], sum[(i*21+20)%30], 4)
+		}
+		add(0, sum[31], sum[30], 3)
+	} else {
+		for i := 0; i < 21; i++ {
+			add(sum[(i*22)%63], sum[(i*22+21)%63], sum[(i*22+42)%63], 4)
+		}
+		add(0, 0, sum[63], 2)
+	}
+
+	return buf.Bytes()
+}
+
+// repeatBytes repeats b up to the length n.
+func repeatBytes(b []byte, n int) []byte {
+	seq := make([]byte, 0, n)
+	for len(seq) < n {
+		seq = append(seq, b[:min(len(b), n-len(seq))]...)
+	}
+	return seq
+}
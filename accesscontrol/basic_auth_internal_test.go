@@ -1,6 +1,15 @@
 package accesscontrol
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/errors"
+)
 
 func Test_Apr1MD5(t *testing.T) {
 	var exp, res string
@@ -65,3 +74,122 @@ func Test_ValidateAccessData(t *testing.T) {
 		t.Error("Unexpected validation failure")
 	}
 }
+
+func Test_ValidateAccessData_Algorithms(t *testing.T) {
+	for _, tc := range []struct {
+		name, pass, hash string
+	}{
+		// python3 -c 'import crypt; print(crypt.crypt("Hello world!", "$5$saltstring"))'
+		{"sha256-crypt", "Hello world!", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{"sha256-crypt rounds", "Hello world!", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+		{"sha512-crypt", "Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"sha512-crypt rounds", "my-pass", "$6$rounds=1000$roundstoolow$YPYKxwY5QXI9q0ePnnRNqxPQzFfQCxRvL4IPsjSwBEUklwu/LWPHRtCmSS..ev2LCbXVOzZeMXCyqtW26qxIu0"},
+		{"sha512-crypt long salt", "my-pass", "$6$Wd8qe5CwrAXgU2Zs$ZXx05txPSiPcLNeTq7cRUo4K79mydZnOImoTKfPZVYF0z3gdR3yYz.gX/J2cFyMlIha0VK7Voozi5bmaDk0v41"},
+		// salts longer than 16 characters are truncated, see https://www.akkadia.org/drepper/SHA-crypt.txt
+		{"sha256-crypt too long salt", "This is just a test", "$5$rounds=5000$toolongsaltstring$Un/5jzAHMgOGZ5.mWJpuVolil07guHPvOW8mGRcvxa5"},
+		{"sha512-crypt too long salt", "This is just a test", "$6$rounds=5000$toolongsaltstring$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+		// argon2.IDKey([]byte("password"), []byte("somesalt"), 2, 65536, 4, 24)
+		{"argon2id", "password", "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$F1jG2CV3/Nr+yRuIsPKw0J9r4s7cJHBU"},
+		// echo -n "password" | argon2 somesalt -i -t 2 -m 16 -p 4 -l 24
+		{"argon2i", "password", "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG"},
+		// python3 -c 'import hashlib; print(hashlib.pbkdf2_hmac("sha256", b"my-pass", b"\x01\x02salty\xfe\xff", 29000))'
+		{"pbkdf2-sha1", "my-pass", "$pbkdf2$131000$AQJzYWx0ef7/$YAGgxUeY/z06INanB8/YoBOYyrc"},
+		{"pbkdf2-sha256", "my-pass", "$pbkdf2-sha256$29000$AQJzYWx0ef7/$ux7s//cwAkB8EytQBSm/PmqJgELwMULpScl04y7pU7w"},
+		{"pbkdf2-sha512", "my-pass", "$pbkdf2-sha512$25000$AQJzYWx0ef7/$9EgJxovkViYdjKwcj6iOPFBAWA61vFaoFktrK.kpMjl.MxnAeZhn5M0stg6C2eGFPgc8shesbM/KbMzA0mDX4Q"},
+		// htpasswd -nbs john my-pass
+		{"sha", "my-pass", "{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc="},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			p, err := newPwd(tc.hash)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			data := htData{"john": p}
+			if !validateAccessData("john", tc.pass, data) {
+				subT.Error("Unexpected validation failure")
+			}
+			if validateAccessData("john", tc.pass+"x", data) {
+				subT.Error("Unexpected validation success")
+			}
+		})
+	}
+}
+
+func Test_NewPwd_Malformed(t *testing.T) {
+	for _, hash := range []string{
+		"$5$rounds=999$salt$hash",
+		"$6$salt",
+		"$argon2id$v=16$m=65536,t=2,p=4$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8",
+		"$argon2id$v=19$m=65536,t=0,p=4$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8",
+		"$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ",
+		"$pbkdf2-sha256$abc$AQJzYWx0ef7/$ux7s",
+		"$pbkdf2-sha256$29000$AQJzYWx0ef7/$",
+		"{SHA}bXktcGFzcw==",
+	} {
+		if _, err := newPwd(hash); err != errMalformedPwd {
+			t.Errorf("%s: expected malformed password error, got: %v", hash, err)
+		}
+	}
+}
+
+func Test_BasicAuth_Reload(t *testing.T) {
+	defer func(interval time.Duration) {
+		htFileCheckInterval = interval
+	}(htFileCheckInterval)
+	htFileCheckInterval = 0
+
+	file := filepath.Join(t.TempDir(), "htpasswd")
+	write := func(content string, modTime time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	write("john:{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc=\n", now.Add(-time.Minute))
+
+	ba, err := NewBasicAuth("ba", "admin", "secret", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(user, pass string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, pass)
+		return ba.Validate(req)
+	}
+
+	if err = validate("john", "my-pass"); err != nil {
+		t.Fatal(err)
+	}
+
+	// jane replaces john
+	write("jane:$2y$05$/uonQYUtwmVv.6AF38IhGeqlvIMPIM5jevzIQ.8RBENUgkCqbJYTm\n", now)
+	if err = validate("jane", "my-pass"); err != nil {
+		t.Errorf("expected reloaded user, got: %v", err)
+	}
+	if err = validate("john", "my-pass"); err == nil {
+		t.Error("expected removed user to fail")
+	}
+
+	// a broken file rejects all credentials of the file
+	write("jane\n", now.Add(time.Minute))
+	if err = validate("jane", "my-pass"); err == nil || err.(*errors.Error).LogError() != "access control error: file: parse error: invalid line: 1" {
+		t.Errorf("expected parse error, got: %v", err)
+	}
+	if err = validate("admin", "secret"); err != nil {
+		t.Errorf("expected inline credentials to be valid with a broken file, got: %v", err)
+	}
+	if err = validate("admin", "wrong"); err == nil || err.(*errors.Error).LogError() != "access control error: credential mismatch" {
+		t.Errorf("expected credential mismatch, got: %v", err)
+	}
+
+	write("jane:$2y$05$/uonQYUtwmVv.6AF38IhGeqlvIMPIM5jevzIQ.8RBENUgkCqbJYTm\n", now.Add(2*time.Minute))
+	if err = validate("jane", "my-pass"); err != nil {
+		t.Errorf("expected fixed file to be reloaded, got: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &ClientCertificate{}
+
+type patternPermissions struct {
+	pattern     *regexp.Regexp
+	permissions []string
+}
+
+// ClientCertificate represents an AC-ClientCertificate object. The client certificate
+// itself has already been verified during the TLS handshake of the server.
+type ClientCertificate struct {
+	allowedSANs        []*regexp.Regexp
+	allowedSubjects    []*regexp.Regexp
+	name               string
+	permissionsMap     map[string][]string
+	sanPermissions     []patternPermissions
+	subjectPermissions []patternPermissions
+}
+
+// NewClientCertificate creates a new AC-ClientCertificate object.
+func NewClientCertificate(name string, allowedSubjects, allowedSANs []string,
+	subjectPermissions, sanPermissions, permissionsMap map[string][]string) (*ClientCertificate, error) {
+	cc := &ClientCertificate{
+		name:           name,
+		permissionsMap: permissionsMap,
+	}
+
+	var err error
+	if cc.allowedSubjects, err = compilePatterns(allowedSubjects); err != nil {
+		return nil, fmt.Errorf("allowed_subjects: %w", err)
+	}
+
+	if cc.allowedSANs, err = compilePatterns(allowedSANs); err != nil {
+		return nil, fmt.Errorf("allowed_sans: %w", err)
+	}
+
+	if cc.subjectPermissions, err = compilePatternPermissions(subjectPermissions); err != nil {
+		return nil, fmt.Errorf("subject_permissions: %w", err)
+	}
+
+	if cc.sanPermissions, err = compilePatternPermissions(sanPermissions); err != nil {
+		return nil, fmt.Errorf("san_permissions: %w", err)
+	}
+
+	return cc, nil
+}
+
+// compilePattern creates an anchored regular expression from the given pattern
+// where "*" matches any sequence of characters.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	return regexp.Compile("^" + quoted + "$")
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func compilePatternPermissions(patterns map[string][]string) ([]patternPermissions, error) {
+	keys := make([]string, 0, len(patterns))
+	for p := range patterns {
+		keys = append(keys, p)
+	}
+	sort.Strings(keys) // predictable order of the granted permissions
+
+	var compiled []patternPermissions
+	for _, p := range keys {
+		re, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, patternPermissions{pattern: re, permissions: patterns[p]})
+	}
+	return compiled, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, values ...string) bool {
+	for _, re := range patterns {
+		for _, v := range values {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate implements the AccessControl interface
+func (c *ClientCertificate) Validate(req *http.Request) error {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return errors.ClientCertificateMissing.Message("no client certificate presented")
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+	subject := cert.Subject.String()
+	sans := subjectAltNames(cert)
+
+	if len(c.allowedSubjects) > 0 && !matchAny(c.allowedSubjects, subject) {
+		return errors.ClientCertificate.Messagef("subject not allowed: %q", subject)
+	}
+
+	if len(c.allowedSANs) > 0 && !matchAny(c.allowedSANs, sans...) {
+		return errors.ClientCertificate.Message("no allowed subject alternative name")
+	}
+
+	var permissions []string
+	for _, sp := range c.subjectPermissions {
+		if matchAny([]*regexp.Regexp{sp.pattern}, subject) {
+			for _, p := range sp.permissions {
+				permissions, _ = addPermission(permissions, p)
+			}
+		}
+	}
+	for _, sp := range c.sanPermissions {
+		if matchAny([]*regexp.Regexp{sp.pattern}, sans...) {
+			for _, p := range sp.permissions {
+				permissions, _ = addPermission(permissions, p)
+			}
+		}
+	}
+	permissions = addMappedPermissions(c.permissionsMap, permissions, permissions)
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[c.name] = certificateInfo(cert, subject)
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	if len(permissions) > 0 {
+		alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
+		grantedPermissions := append(alreadyGrantedPermissions, permissions...)
+		ctx = context.WithValue(ctx, request.GrantedPermissions, grantedPermissions)
+	}
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// subjectAltNames returns the DNS names, email addresses, IP addresses and URIs of the given certificate.
+func subjectAltNames(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+func certificateInfo(cert *x509.Certificate, subject string) map[string]interface{} {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	ips := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+
+	uris := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return map[string]interface{}{
+		"common_name":     cert.Subject.CommonName,
+		"dns_names":       append([]string{}, cert.DNSNames...),
+		"email_addresses": append([]string{}, cert.EmailAddresses...),
+		"fingerprint":     hex.EncodeToString(fingerprint[:]),
+		"ip_addresses":    ips,
+		"issuer":          cert.Issuer.String(),
+		"not_after":       cert.NotAfter.Unix(),
+		"not_before":      cert.NotBefore.Unix(),
+		"serial_number":   cert.SerialNumber.Text(16),
+		"subject":         subject,
+		"uris":            uris,
+	}
+}
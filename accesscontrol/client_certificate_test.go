@@ -0,0 +1,140 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_NewClientCertificate(t *testing.T) {
+	for _, tc := range []struct {
+		name               string
+		allowedSubjects    []string
+		allowedSANs        []string
+		subjectPermissions map[string][]string
+		expErr             string
+	}{
+		{"valid", []string{"CN=*,O=Couper"}, []string{"*.example.com"}, map[string][]string{"CN=admin": {"admin"}}, ""},
+		{"empty subject pattern", []string{""}, nil, nil, "allowed_subjects: empty pattern"},
+		{"empty san pattern", nil, []string{""}, nil, "allowed_sans: empty pattern"},
+		{"empty permissions pattern", nil, nil, map[string][]string{"": {"admin"}}, "subject_permissions: empty pattern"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewClientCertificate("cc", tc.allowedSubjects, tc.allowedSANs, tc.subjectPermissions, nil, nil)
+			if tc.expErr == "" && err != nil {
+				st.Fatal(err)
+			}
+			if tc.expErr != "" && (err == nil || err.Error() != tc.expErr) {
+				st.Errorf("want error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestClientCertificate_Validate(t *testing.T) {
+	uri, _ := url.Parse("spiffe://example.com/service")
+	cert := &x509.Certificate{
+		DNSNames:       []string{"api.example.com"},
+		EmailAddresses: []string{"ops@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		Issuer:         pkix.Name{CommonName: "Example CA"},
+		Raw:            []byte("raw"),
+		SerialNumber:   big.NewInt(255),
+		Subject:        pkix.Name{CommonName: "service", Organization: []string{"Example"}},
+		URIs:           []*url.URL{uri},
+	}
+
+	subjectPermissions := map[string][]string{
+		"CN=service,O=*": {"read"},
+		"CN=admin,O=*":   {"admin"},
+	}
+	sanPermissions := map[string][]string{
+		"spiffe://example.com/*": {"write"},
+	}
+	permissionsMap := map[string][]string{
+		"write": {"delete"},
+	}
+
+	for _, tc := range []struct {
+		name            string
+		allowedSubjects []string
+		allowedSANs     []string
+		noCertificate   bool
+		expErr          *errors.Error
+		expPermissions  []string
+	}{
+		{"any certificate", nil, nil, false, nil, []string{"read", "write", "delete"}},
+		{"allowed subject", []string{"CN=service,O=Example"}, nil, false, nil, []string{"read", "write", "delete"}},
+		{"allowed dns name", nil, []string{"*.example.com"}, false, nil, []string{"read", "write", "delete"}},
+		{"allowed ip address", nil, []string{"10.0.0.*"}, false, nil, []string{"read", "write", "delete"}},
+		{"allowed subject and san", []string{"CN=*"}, []string{"ops@example.com"}, false, nil, []string{"read", "write", "delete"}},
+		{"subject not allowed", []string{"CN=other,O=Example"}, nil, false, errors.ClientCertificate, nil},
+		{"san not allowed", []string{"CN=service,O=Example"}, []string{"*.example.org"}, false, errors.ClientCertificate, nil},
+		{"missing certificate", nil, nil, true, errors.ClientCertificateMissing, nil},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			clientCertificate, err := ac.NewClientCertificate("cc", tc.allowedSubjects, tc.allowedSANs,
+				subjectPermissions, sanPermissions, permissionsMap)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.Background())
+			if tc.noCertificate {
+				req.TLS = &tls.ConnectionState{}
+			} else {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			}
+
+			err = clientCertificate.Validate(req)
+			if tc.expErr != nil {
+				gerr, ok := err.(*errors.Error)
+				if !ok || gerr.Kinds()[0] != tc.expErr.Kinds()[0] {
+					st.Errorf("want error kind %v, got %v", tc.expErr.Kinds(), err)
+				}
+				return
+			}
+
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			info, _ := acMap["cc"].(map[string]interface{})
+			expInfo := map[string]interface{}{
+				"common_name":     "service",
+				"dns_names":       []string{"api.example.com"},
+				"email_addresses": []string{"ops@example.com"},
+				"fingerprint":     "d7439bee24773bcbfa2d0a97947ee36227b10d1022b1a55847e928965bb6bfde",
+				"ip_addresses":    []string{"10.0.0.1"},
+				"issuer":          "CN=Example CA",
+				"not_after":       int64(-62135596800),
+				"not_before":      int64(-62135596800),
+				"serial_number":   "ff",
+				"subject":         "CN=service,O=Example",
+				"uris":            []string{"spiffe://example.com/service"},
+			}
+			if !reflect.DeepEqual(info, expInfo) {
+				st.Errorf("want context\n%#v\ngot\n%#v", expInfo, info)
+			}
+
+			permissions, _ := req.Context().Value(request.GrantedPermissions).([]string)
+			if !reflect.DeepEqual(permissions, tc.expPermissions) {
+				st.Errorf("want permissions %v, got %v", tc.expPermissions, permissions)
+			}
+		})
+	}
+}
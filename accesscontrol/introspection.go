@@ -0,0 +1,154 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/oauth2"
+)
+
+var _ AccessControl = &Introspection{}
+
+// Introspection represents an AC-Introspection object for opaque tokens, see RFC 7662.
+type Introspection struct {
+	client           *oauth2.IntrospectionClient
+	memStore         cache.Store
+	name             string
+	permissionsClaim string
+	permissionsMap   map[string][]string
+	source           *TokenSource
+	ttl              int64
+}
+
+// NewIntrospection creates a new AC-Introspection object.
+func NewIntrospection(conf *config.Introspection, client *oauth2.IntrospectionClient,
+	permissionsMap map[string][]string, memStore cache.Store) (*Introspection, error) {
+	source, err := NewTokenSource(false, conf.Cookie, conf.Header, conf.TokenValue)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := config.ParseDuration("ttl", conf.TTL, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsClaim := conf.PermissionsClaim
+	if permissionsClaim == "" {
+		permissionsClaim = "scope"
+	}
+
+	return &Introspection{
+		client:           client,
+		memStore:         memStore,
+		name:             conf.Name,
+		permissionsClaim: permissionsClaim,
+		permissionsMap:   permissionsMap,
+		source:           source,
+		ttl:              int64(ttl.Seconds()),
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (i *Introspection) Validate(req *http.Request) error {
+	tokenValue, err := i.source.TokenValue(req)
+	if err != nil {
+		return errors.IntrospectionTokenMissing.With(err)
+	}
+
+	data, err := i.introspect(req.Context(), tokenValue)
+	if err != nil {
+		return errors.Introspection.With(err)
+	}
+
+	if active, _ := data["active"].(bool); !active {
+		return errors.Introspection.Message("token inactive")
+	}
+
+	if exp, ok := data["exp"].(float64); ok && int64(exp) <= time.Now().Unix() {
+		return errors.Introspection.Message("token expired")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[i.name] = data
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	permissions, perr := getMetadataPermissions(data[i.permissionsClaim])
+	if perr != nil {
+		if log, ok := ctx.Value(request.LogEntry).(*logrus.Entry); ok {
+			log.WithContext(ctx).Warn(fmt.Sprintf(warnInvalidValueMsg, "permissions", data[i.permissionsClaim]))
+		}
+	}
+	permissions = addMappedPermissions(i.permissionsMap, permissions, permissions)
+
+	if len(permissions) > 0 {
+		alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
+		grantedPermissions := append(alreadyGrantedPermissions, permissions...)
+		ctx = context.WithValue(ctx, request.GrantedPermissions, grantedPermissions)
+	}
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// introspect returns the (cached) introspection response for the given token.
+func (i *Introspection) introspect(ctx context.Context, tokenValue string) (map[string]interface{}, error) {
+	hash := sha256.Sum256([]byte(tokenValue))
+	key := "introspection:" + i.name + ":" + hex.EncodeToString(hash[:])
+
+	if cached, ok := i.memStore.Get(key).(string); ok {
+		return parseIntrospectionResponse([]byte(cached))
+	}
+
+	body, err := i.client.Introspect(ctx, tokenValue)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := parseIntrospectionResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := i.ttl
+	if exp, ok := data["exp"].(float64); ok && data["active"] == true {
+		if expiresIn := int64(exp) - time.Now().Unix(); expiresIn < ttl {
+			ttl = expiresIn
+		}
+	}
+
+	if ttl > 0 {
+		i.memStore.Set(key, string(body), ttl)
+	}
+
+	return data, nil
+}
+
+func parseIntrospectionResponse(body []byte) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %w", err)
+	}
+
+	if _, ok := data["active"].(bool); !ok {
+		return nil, fmt.Errorf("invalid introspection response: missing active member")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,126 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/oauth2"
+)
+
+func TestIntrospection_Validate(t *testing.T) {
+	var requests int32
+	exp := time.Now().Add(time.Hour).Unix()
+
+	as := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if user, pass, ok := req.BasicAuth(); !ok || user != "my-client" || pass != "my-secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.PostFormValue("token") {
+		case "active":
+			fmt.Fprintf(rw, `{"active":true,"sub":"me","scope":"read write","exp":%d}`, exp)
+		case "expired":
+			fmt.Fprintf(rw, `{"active":true,"sub":"me","exp":%d}`, time.Now().Add(-time.Minute).Unix())
+		case "invalid":
+			fmt.Fprint(rw, `{"sub":"me"}`)
+		default:
+			fmt.Fprint(rw, `{"active":false}`)
+		}
+	}))
+	defer as.Close()
+
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	memStore := cache.New(logrus.NewEntry(log), quitCh)
+
+	conf := &config.Introspection{
+		ClientID:              "my-client",
+		ClientSecret:          "my-secret",
+		IntrospectionEndpoint: as.URL,
+		Name:                  "ti",
+	}
+
+	client, err := oauth2.NewIntrospectionClient(nil, conf, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	introspection, err := ac.NewIntrospection(conf, client, map[string][]string{"write": {"delete"}}, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		token          string
+		expErr         *errors.Error
+		expRequests    int32
+		expPermissions []string
+	}{
+		{"active", "active", nil, 1, []string{"read", "write", "delete"}},
+		{"active cached", "active", nil, 0, []string{"read", "write", "delete"}},
+		{"inactive", "inactive", errors.Introspection, 1, nil},
+		{"inactive cached", "inactive", errors.Introspection, 0, nil},
+		{"expired", "expired", errors.Introspection, 1, nil},
+		{"invalid response", "invalid", errors.Introspection, 1, nil},
+		{"missing token", "", errors.IntrospectionTokenMissing, 0, nil},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.Background())
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			err = introspection.Validate(req)
+
+			if n := atomic.LoadInt32(&requests); n != tc.expRequests {
+				st.Errorf("want %d introspection requests, got %d", tc.expRequests, n)
+			}
+
+			if tc.expErr != nil {
+				gerr, ok := err.(*errors.Error)
+				if !ok || gerr.Kinds()[0] != tc.expErr.Kinds()[0] {
+					st.Errorf("want error kind %v, got %v", tc.expErr.Kinds(), err)
+				}
+				return
+			}
+
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data, _ := acMap["ti"].(map[string]interface{})
+			if data["sub"] != "me" {
+				st.Errorf("want sub %q, got %#v", "me", data["sub"])
+			}
+
+			permissions, _ := req.Context().Value(request.GrantedPermissions).([]string)
+			if !reflect.DeepEqual(permissions, tc.expPermissions) {
+				st.Errorf("want permissions %v, got %v", tc.expPermissions, permissions)
+			}
+		})
+	}
+}
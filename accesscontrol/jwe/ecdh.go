@@ -0,0 +1,158 @@
+package jwe
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+var ecdhCurves = map[string]ecdh.Curve{
+	"P-256": ecdh.P256(),
+	"P-384": ecdh.P384(),
+	"P-521": ecdh.P521(),
+}
+
+// deriveSenderKey creates an ephemeral key pair and derives the content encryption key
+// from the key agreement with the recipient's public key. The ephemeral public key
+// is returned as JWK for the "epk" header parameter.
+func deriveSenderKey(pub *ecdsa.PublicKey, enc string, size int) ([]byte, map[string]interface{}, error) {
+	crv := pub.Curve.Params().Name
+	curve, ok := ecdhCurves[crv]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported curve: %q", crv)
+	}
+
+	recipient, err := pub.ECDH()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ephemeral, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	z, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// uncompressed point: 0x04 || x || y
+	point := ephemeral.PublicKey().Bytes()[1:]
+	epk := map[string]interface{}{
+		"kty": "EC",
+		"crv": crv,
+		"x":   base64.RawURLEncoding.EncodeToString(point[:len(point)/2]),
+		"y":   base64.RawURLEncoding.EncodeToString(point[len(point)/2:]),
+	}
+
+	return concatKDF(z, enc, nil, nil, size), epk, nil
+}
+
+// deriveRecipientKey derives the content encryption key from the key agreement
+// with the ephemeral public key given in the "epk" header parameter.
+func deriveRecipientKey(priv *ecdsa.PrivateKey, header map[string]interface{}, enc string, size int) ([]byte, error) {
+	crv := priv.Curve.Params().Name
+	curve, ok := ecdhCurves[crv]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve: %q", crv)
+	}
+
+	epk, ok := header["epk"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing epk header parameter")
+	}
+
+	if epk["kty"] != "EC" || epk["crv"] != crv {
+		return nil, fmt.Errorf("epk does not match the %s decryption key", crv)
+	}
+
+	coordinateSize := (priv.Curve.Params().BitSize + 7) / 8
+	x, err := decodeCoordinate(epk["x"], coordinateSize)
+	if err != nil {
+		return nil, fmt.Errorf("epk x: %w", err)
+	}
+	y, err := decodeCoordinate(epk["y"], coordinateSize)
+	if err != nil {
+		return nil, fmt.Errorf("epk y: %w", err)
+	}
+
+	ephemeral, err := curve.NewPublicKey(append(append([]byte{4}, x...), y...))
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, err := priv.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	z, err := recipient.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	apu, err := decodeOptional(header["apu"])
+	if err != nil {
+		return nil, fmt.Errorf("apu: %w", err)
+	}
+	apv, err := decodeOptional(header["apv"])
+	if err != nil {
+		return nil, fmt.Errorf("apv: %w", err)
+	}
+
+	return concatKDF(z, enc, apu, apv, size), nil
+}
+
+// concatKDF implements the Concat KDF with SHA-256 for key sizes up to 256 bits,
+// see RFC 7518, section 4.6.2.
+func concatKDF(z []byte, algID string, apu, apv []byte, size int) []byte {
+	h := sha256.New()
+
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], 1) // round
+	h.Write(buf[:])
+	h.Write(z)
+	for _, data := range [][]byte{[]byte(algID), apu, apv} {
+		binary.BigEndian.PutUint32(buf[:], uint32(len(data)))
+		h.Write(buf[:])
+		h.Write(data)
+	}
+	binary.BigEndian.PutUint32(buf[:], uint32(size*8))
+	h.Write(buf[:])
+
+	return h.Sum(nil)[:size]
+}
+
+func decodeCoordinate(value interface{}, size int) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("missing coordinate")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) != size {
+		return nil, fmt.Errorf("invalid coordinate size")
+	}
+	return b, nil
+}
+
+func decodeOptional(value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid value type")
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,273 @@
+// Package jwe implements the compact serialization of JSON Web Encryption (RFC 7516)
+// with the key management algorithms RSA-OAEP, RSA-OAEP-256, ECDH-ES and dir
+// and the AES GCM content encryption algorithms.
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRSAOAEP    = "RSA-OAEP"
+	AlgorithmRSAOAEP256 = "RSA-OAEP-256"
+	AlgorithmECDHES     = "ECDH-ES"
+	AlgorithmDirect     = "dir"
+)
+
+// contentKeySizes maps the supported content encryption algorithms to their key sizes in bytes.
+var contentKeySizes = map[string]int{
+	"A128GCM": 16,
+	"A192GCM": 24,
+	"A256GCM": 32,
+}
+
+// IsEncrypted reports whether the given token uses the JWE compact serialization.
+func IsEncrypted(token string) bool {
+	return strings.Count(token, ".") == 4
+}
+
+// Encrypter creates JWE tokens for a single recipient.
+type Encrypter struct {
+	alg string
+	enc string
+	key interface{}
+}
+
+// NewEncrypter creates an Encrypter. The key is a public key (in PEM format)
+// for RSA-OAEP* and ECDH-ES or the shared symmetric key for dir.
+func NewEncrypter(alg, enc string, key []byte) (*Encrypter, error) {
+	size, ok := contentKeySizes[enc]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encryption algorithm: %q", enc)
+	}
+
+	e := &Encrypter{alg: alg, enc: enc}
+
+	var err error
+	switch alg {
+	case AlgorithmRSAOAEP, AlgorithmRSAOAEP256:
+		e.key, err = jwt.ParseRSAPublicKeyFromPEM(key)
+	case AlgorithmECDHES:
+		e.key, err = jwt.ParseECPublicKeyFromPEM(key)
+	case AlgorithmDirect:
+		if len(key) != size {
+			return nil, fmt.Errorf("key for %s must have a size of %d bytes", enc, size)
+		}
+		e.key = key
+	default:
+		return nil, fmt.Errorf("unsupported key management algorithm: %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Encrypt encrypts the plaintext. The given header fields are added to the
+// protected header, "alg" and "enc" cannot be overridden.
+func (e *Encrypter) Encrypt(plaintext []byte, header map[string]interface{}) (string, error) {
+	protectedHeader := make(map[string]interface{})
+	for k, v := range header {
+		protectedHeader[k] = v
+	}
+	protectedHeader["alg"] = e.alg
+	protectedHeader["enc"] = e.enc
+
+	size := contentKeySizes[e.enc]
+
+	var (
+		cek, encryptedKey []byte
+		err               error
+	)
+	switch key := e.key.(type) {
+	case *rsa.PublicKey:
+		cek = make([]byte, size)
+		if _, err = rand.Read(cek); err != nil {
+			return "", err
+		}
+		encryptedKey, err = rsa.EncryptOAEP(oaepHash(e.alg), rand.Reader, key, cek, nil)
+	case *ecdsa.PublicKey:
+		var epk map[string]interface{}
+		cek, epk, err = deriveSenderKey(key, e.enc, size)
+		protectedHeader["epk"] = epk
+	case []byte:
+		cek = key
+	}
+	if err != nil {
+		return "", err
+	}
+
+	headerBytes, err := json.Marshal(protectedHeader)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	tagStart := len(sealed) - gcm.Overhead()
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(sealed[:tagStart]),
+		base64.RawURLEncoding.EncodeToString(sealed[tagStart:]),
+	}, "."), nil
+}
+
+// Decrypter decrypts JWE tokens with a single private or shared key.
+type Decrypter struct {
+	key interface{}
+}
+
+// NewDecrypter creates a Decrypter. The key is a private key (in PEM format)
+// for RSA-OAEP* and ECDH-ES or the shared symmetric key for dir.
+func NewDecrypter(key []byte) (*Decrypter, error) {
+	if block, _ := pem.Decode(key); block == nil {
+		for _, size := range contentKeySizes {
+			if len(key) == size {
+				return &Decrypter{key: key}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid decryption key: neither PEM encoded nor a 16, 24 or 32 bytes shared key")
+	}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(key); err == nil {
+		return &Decrypter{key: rsaKey}, nil
+	}
+
+	if ecKey, err := jwt.ParseECPrivateKeyFromPEM(key); err == nil {
+		return &Decrypter{key: ecKey}, nil
+	}
+
+	return nil, fmt.Errorf("invalid RSA/EC private key")
+}
+
+// Decrypt decrypts the given token and returns the plaintext and the protected header.
+func (d *Decrypter) Decrypt(token string) ([]byte, map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, fmt.Errorf("token is not encrypted")
+	}
+
+	var decoded [5][]byte
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid token part %d: %w", i+1, err)
+		}
+		decoded[i] = b
+	}
+	encryptedKey, iv, ciphertext, tag := decoded[1], decoded[2], decoded[3], decoded[4]
+
+	var header map[string]interface{}
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+
+	if _, exists := header["zip"]; exists {
+		return nil, nil, fmt.Errorf("compressed plaintext is not supported")
+	}
+
+	if _, exists := header["crit"]; exists {
+		return nil, nil, fmt.Errorf("critical header parameters are not supported")
+	}
+
+	alg, _ := header["alg"].(string)
+	enc, _ := header["enc"].(string)
+	size, ok := contentKeySizes[enc]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported content encryption algorithm: %q", enc)
+	}
+
+	var (
+		cek []byte
+		err error
+	)
+	switch key := d.key.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgorithmRSAOAEP && alg != AlgorithmRSAOAEP256 {
+			return nil, nil, fmt.Errorf("unsupported key management algorithm: %q", alg)
+		}
+		cek, err = rsa.DecryptOAEP(oaepHash(alg), rand.Reader, key, encryptedKey, nil)
+	case *ecdsa.PrivateKey:
+		if alg != AlgorithmECDHES {
+			return nil, nil, fmt.Errorf("unsupported key management algorithm: %q", alg)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, nil, fmt.Errorf("unexpected encrypted key for %s", alg)
+		}
+		cek, err = deriveRecipientKey(key, header, enc, size)
+	case []byte:
+		if alg != AlgorithmDirect {
+			return nil, nil, fmt.Errorf("unsupported key management algorithm: %q", alg)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, nil, fmt.Errorf("unexpected encrypted key for %s", alg)
+		}
+		cek = key
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("content encryption key: %w", err)
+	}
+
+	if len(cek) != size {
+		return nil, nil, fmt.Errorf("invalid content encryption key size for %s", enc)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, nil, fmt.Errorf("invalid initialization vector or authentication tag")
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("decryption failed")
+	}
+
+	return plaintext, header, nil
+}
+
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func oaepHash(alg string) hash.Hash {
+	if alg == AlgorithmRSAOAEP256 {
+		return sha256.New()
+	}
+	return sha1.New()
+}
@@ -0,0 +1,51 @@
+package jwe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"math/big"
+	"testing"
+)
+
+// Test_deriveRecipientKey uses the ECDH-ES example of RFC 7518, appendix C.
+func Test_deriveRecipientKey(t *testing.T) {
+	decode := func(s string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+
+	bob := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     decode("weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ"),
+			Y:     decode("e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck"),
+		},
+		D: decode("VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw"),
+	}
+
+	header := map[string]interface{}{
+		"alg": "ECDH-ES",
+		"enc": "A128GCM",
+		"apu": "QWxpY2U",
+		"apv": "Qm9i",
+		"epk": map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   "gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0",
+			"y":   "SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps",
+		},
+	}
+
+	cek, err := deriveRecipientKey(bob, header, "A128GCM", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := base64.RawURLEncoding.EncodeToString(cek); got != "VqqN6vgjbSBcIijNcacQGg" {
+		t.Errorf("want derived key %q, got %q", "VqqN6vgjbSBcIijNcacQGg", got)
+	}
+}
@@ -0,0 +1,145 @@
+package jwe_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/accesscontrol/jwe"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaPub, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	ecPub, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	ecPriv, _ := x509.MarshalECPrivateKey(ecKey)
+
+	rsaPublicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPub})
+	rsaPrivatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	ecPublicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPub})
+	ecPrivatePEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecPriv})
+
+	for _, tc := range []struct {
+		alg        string
+		enc        string
+		encryptKey []byte
+		decryptKey []byte
+	}{
+		{"RSA-OAEP", "A128GCM", rsaPublicPEM, rsaPrivatePEM},
+		{"RSA-OAEP-256", "A256GCM", rsaPublicPEM, rsaPrivatePEM},
+		{"ECDH-ES", "A192GCM", ecPublicPEM, ecPrivatePEM},
+		{"ECDH-ES", "A256GCM", ecPublicPEM, ecPrivatePEM},
+		{"dir", "A128GCM", []byte("0123456789abcdef"), []byte("0123456789abcdef")},
+		{"dir", "A256GCM", []byte("0123456789abcdef0123456789abcdef"), []byte("0123456789abcdef0123456789abcdef")},
+	} {
+		t.Run(tc.alg+"/"+tc.enc, func(st *testing.T) {
+			encrypter, err := jwe.NewEncrypter(tc.alg, tc.enc, tc.encryptKey)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			token, err := encrypter.Encrypt([]byte("my.nested.jwt"), map[string]interface{}{"cty": "JWT", "alg": "none"})
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			if !jwe.IsEncrypted(token) {
+				st.Fatalf("expected JWE compact serialization, got %q", token)
+			}
+
+			decrypter, err := jwe.NewDecrypter(tc.decryptKey)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			plaintext, header, err := decrypter.Decrypt(token)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			if string(plaintext) != "my.nested.jwt" {
+				st.Errorf("want plaintext %q, got %q", "my.nested.jwt", plaintext)
+			}
+
+			if header["alg"] != tc.alg || header["enc"] != tc.enc || header["cty"] != "JWT" {
+				st.Errorf("unexpected header: %#v", header)
+			}
+
+			parts := strings.Split(token, ".")
+			parts[3] = "x" + parts[3][1:]
+			if _, _, err = decrypter.Decrypt(strings.Join(parts, ".")); err == nil {
+				st.Error("expected error for tampered ciphertext")
+			}
+		})
+	}
+}
+
+func TestNewEncrypter(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		alg    string
+		enc    string
+		key    []byte
+		expErr string
+	}{
+		{"unsupported alg", "A128KW", "A128GCM", []byte("0123456789abcdef"), `unsupported key management algorithm: "A128KW"`},
+		{"unsupported enc", "dir", "A128CBC-HS256", []byte("0123456789abcdef"), `unsupported content encryption algorithm: "A128CBC-HS256"`},
+		{"invalid dir key size", "dir", "A256GCM", []byte("0123456789abcdef"), "key for A256GCM must have a size of 32 bytes"},
+		{"invalid public key", "RSA-OAEP", "A256GCM", []byte("no-pem"), "invalid key: Key must be a PEM encoded PKCS1 or PKCS8 key"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := jwe.NewEncrypter(tc.alg, tc.enc, tc.key)
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("want error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestDecrypter_Decrypt(t *testing.T) {
+	encrypter, err := jwe.NewEncrypter("dir", "A128GCM", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := encrypter.Encrypt([]byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypter, err := jwe.NewDecrypter([]byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		expErr string
+	}{
+		{"wrong key", token, "decryption failed"},
+		{"not encrypted", "a.b.c", "token is not encrypted"},
+		{"algorithm mismatch", "eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkExMjhHQ00ifQ.." + strings.Join(strings.Split(token, ".")[2:], "."), `unsupported key management algorithm: "RSA-OAEP"`},
+		{"invalid header", "bm9uZQ.." + strings.Join(strings.Split(token, ".")[2:], "."), "invalid protected header: invalid character 'o' in literal null (expecting 'u')"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, _, err = decrypter.Decrypt(tc.token)
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("want error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
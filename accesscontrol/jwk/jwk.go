@@ -2,8 +2,10 @@ package jwk
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
@@ -28,7 +30,7 @@ type rawJWK struct {
 	E   *base64URLEncodedField `json:"e"`
 	N   *base64URLEncodedField `json:"n"`
 	X5c []*base64EncodedField  `json:"x5c"`
-	// ECDSA public key
+	// ECDSA and EdDSA public key
 	Crv string                 `json:"crv"`
 	X   *base64URLEncodedField `json:"x"`
 	Y   *base64URLEncodedField `json:"y"`
@@ -92,6 +94,19 @@ func (j *JWK) UnmarshalJSON(data []byte) error {
 				return nil
 			}
 		}
+	case "OKP":
+		key, err = getPublicKeyFromX5c(raw.X5c)
+		if err != nil {
+			return nil
+		}
+
+		if key != nil {
+			jwk.Key = key
+		} else if raw.Crv == "Ed25519" && raw.X != nil && len(raw.X.data) == ed25519.PublicKeySize {
+			jwk.Key = ed25519.PublicKey(raw.X.data)
+		} else {
+			return nil
+		}
 	default:
 		// TODO log warning properly
 		fmt.Printf("Found unsupported %s key: %q\n", raw.Kty, raw.Kid)
@@ -200,3 +215,30 @@ func getPublicKeyFromX5c(x5c []*base64EncodedField) (interface{}, error) {
 	}
 	return certificate.PublicKey, nil
 }
+
+// Thumbprint returns the base64url encoded SHA-256 JWK thumbprint (RFC 7638) of the given public key.
+func Thumbprint(key interface{}) (string, error) {
+	var members string
+
+	// the required members in lexicographic order, see RFC 7638, section 3.2
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		e := big.NewInt(int64(k.E)).Bytes()
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, encode(e), encode(k.N.Bytes()))
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`,
+			k.Curve.Params().Name, encode(k.X.FillBytes(make([]byte, size))), encode(k.Y.FillBytes(make([]byte, size))))
+	case ed25519.PublicKey:
+		members = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, encode(k))
+	default:
+		return "", fmt.Errorf("unsupported key type: %T", key)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return encode(sum[:]), nil
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
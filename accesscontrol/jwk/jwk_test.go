@@ -0,0 +1,49 @@
+package jwk_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+)
+
+func Test_Thumbprint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		key  string
+		exp  string
+	}{
+		{
+			// RFC 7638, section 3.1
+			"RSA",
+			`{"kty":"RSA","e":"AQAB","alg":"RS256","kid":"2011-04-29","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"}`,
+			"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// RFC 8037, appendix A.3
+			"OKP",
+			`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+			"kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			var key jwk.JWK
+			if err := json.Unmarshal([]byte(tc.key), &key); err != nil {
+				subT.Fatal(err)
+			}
+
+			thumbprint, err := jwk.Thumbprint(key.Key)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if thumbprint != tc.exp {
+				subT.Errorf("expected thumbprint %q, got %q", tc.exp, thumbprint)
+			}
+		})
+	}
+
+	if _, err := jwk.Thumbprint([]byte("secret")); err == nil || err.Error() != "unsupported key type: []uint8" {
+		t.Errorf("expected unsupported key type error, got %v", err)
+	}
+}
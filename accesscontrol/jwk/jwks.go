@@ -21,6 +21,10 @@ var alg2kty = map[string]string{
 	"ES256": "EC",
 	"ES384": "EC",
 	"ES512": "EC",
+	"PS256": "RSA",
+	"PS384": "RSA",
+	"PS512": "RSA",
+	"EdDSA": "OKP",
 }
 
 type JWKSData struct {
@@ -61,6 +61,7 @@ func Test_JWKS_Load(t *testing.T) {
 	}{
 		{"RSA", "testdata/jwks.json", true},
 		{"oct", "testdata/jwks_oct.json", true},
+		{"OKP", "testdata/jwks_okp.json", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(subT *testing.T) {
@@ -141,6 +142,9 @@ func Test_JWKS_GetKey(t *testing.T) {
 		{"missing x", "testdata/jwks_ecdsa.json", "missing-x", "ES256", "sig", false},
 		{"missing y", "testdata/jwks_ecdsa.json", "missing-y", "ES256", "sig", false},
 		{"ok", "testdata/jwks_ecdsa.json", "ok", "ES256", "sig", true},
+		{"okp: invalid crv", "testdata/jwks_okp.json", "invalid-crv", "EdDSA", "sig", false},
+		{"okp: missing x", "testdata/jwks_okp.json", "missing-x", "EdDSA", "sig", false},
+		{"okp: ok", "testdata/jwks_okp.json", "ok", "EdDSA", "sig", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(subT *testing.T) {
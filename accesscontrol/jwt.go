@@ -3,8 +3,12 @@ package accesscontrol
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/pem"
 	goerrors "errors"
 	"fmt"
@@ -17,6 +21,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/sirupsen/logrus"
 
+	"github.com/coupergateway/couper/accesscontrol/jwe"
 	"github.com/coupergateway/couper/accesscontrol/jwk"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/cache"
@@ -34,9 +39,12 @@ var (
 
 type JWT struct {
 	algos                 []string
+	certificateBound      bool
 	claims                hcl.Expression
 	claimsRequired        []string
+	decrypter             *jwe.Decrypter
 	disablePrivateCaching bool
+	dpop                  bool
 	source                *TokenSource
 	hmacSecret            []byte
 	name                  string
@@ -46,11 +54,12 @@ type JWT struct {
 	permissionsClaim      string
 	permissionsMap        map[string][]string
 	jwks                  *jwk.JWKS
-	memStore              *cache.MemoryStore
+	revocationList        *acjwt.RevocationList
+	memStore              cache.Store
 }
 
 // NewJWT parses the key and creates Validation obj which can be referenced in related handlers.
-func NewJWT(jwtConf *config.JWT, key []byte, memStore *cache.MemoryStore) (*JWT, error) {
+func NewJWT(jwtConf *config.JWT, key []byte, memStore cache.Store) (*JWT, error) {
 	jwtAC, err := newJWT(jwtConf, memStore)
 	if err != nil {
 		return nil, err
@@ -98,8 +107,11 @@ func parsePublicPEMKey(key []byte) (pub interface{}, err error) {
 			if k, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
 				return k, nil
 			}
+			if k, ok := cert.PublicKey.(ed25519.PublicKey); ok {
+				return k, nil
+			}
 
-			return nil, fmt.Errorf("invalid RSA/ECDSA public key")
+			return nil, fmt.Errorf("invalid RSA/ECDSA/EdDSA public key")
 		}
 
 		if k, ok := pkixKey.(*rsa.PublicKey); ok {
@@ -110,12 +122,16 @@ func parsePublicPEMKey(key []byte) (pub interface{}, err error) {
 			return k, nil
 		}
 
-		return nil, fmt.Errorf("invalid RSA/ECDSA public key")
+		if k, ok := pkixKey.(ed25519.PublicKey); ok {
+			return k, nil
+		}
+
+		return nil, fmt.Errorf("invalid RSA/ECDSA/EdDSA public key")
 	}
 	return pubKey, nil
 }
 
-func NewJWTFromJWKS(jwtConf *config.JWT, jwks *jwk.JWKS, memStore *cache.MemoryStore) (*JWT, error) {
+func NewJWTFromJWKS(jwtConf *config.JWT, jwks *jwk.JWKS, memStore cache.Store) (*JWT, error) {
 	if jwks == nil {
 		return nil, fmt.Errorf("invalid JWKS")
 	}
@@ -125,9 +141,8 @@ func NewJWTFromJWKS(jwtConf *config.JWT, jwks *jwk.JWKS, memStore *cache.MemoryS
 		return nil, err
 	}
 
-	algorithms := append(acjwt.RSAAlgorithms, acjwt.ECDSAlgorithms...)
 	var algos []string
-	for _, a := range algorithms {
+	for _, a := range acjwt.PublicKeyAlgorithms {
 		algos = append(algos, a.String())
 	}
 
@@ -162,10 +177,16 @@ func (p parserConfig) newParser() *jwt.Parser {
 	return jwt.NewParser(options...)
 }
 
-func newJWT(jwtConf *config.JWT, memStore *cache.MemoryStore) (*JWT, error) {
-	source, err := NewTokenSource(jwtConf.Bearer, jwtConf.Cookie, jwtConf.Header, jwtConf.TokenValue)
-	if err != nil {
-		return nil, err
+func newJWT(jwtConf *config.JWT, memStore cache.Store) (*JWT, error) {
+	var source *TokenSource
+	if jwtConf.DPoP {
+		source = NewDPoPTokenSource()
+	} else {
+		var err error
+		source, err = NewTokenSource(jwtConf.Bearer, jwtConf.Cookie, jwtConf.Header, jwtConf.TokenValue)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if jwtConf.RolesClaim != "" && jwtConf.RolesMap == nil {
@@ -173,9 +194,11 @@ func newJWT(jwtConf *config.JWT, memStore *cache.MemoryStore) (*JWT, error) {
 	}
 
 	jwtAC := &JWT{
+		certificateBound:      jwtConf.CertificateBound,
 		claims:                jwtConf.Claims,
 		claimsRequired:        jwtConf.ClaimsRequired,
 		disablePrivateCaching: jwtConf.DisablePrivateCaching,
+		dpop:                  jwtConf.DPoP,
 		memStore:              memStore,
 		name:                  jwtConf.Name,
 		rolesClaim:            jwtConf.RolesClaim,
@@ -187,6 +210,17 @@ func newJWT(jwtConf *config.JWT, memStore *cache.MemoryStore) (*JWT, error) {
 	return jwtAC, nil
 }
 
+// SetDecrypter enables the decryption of encrypted tokens (JWE). Tokens which
+// are not encrypted are rejected afterwards.
+func (j *JWT) SetDecrypter(decrypter *jwe.Decrypter) {
+	j.decrypter = decrypter
+}
+
+// SetRevocationList enables the rejection of revoked tokens.
+func (j *JWT) SetRevocationList(revocationList *acjwt.RevocationList) {
+	j.revocationList = revocationList
+}
+
 func (j *JWT) DisablePrivateCaching() bool {
 	return j.disablePrivateCaching
 }
@@ -209,6 +243,15 @@ func (j *JWT) Validate(req *http.Request) error {
 	if err != nil {
 		return errors.JwtTokenMissing.With(err)
 	}
+	accessToken := tokenValue
+
+	if j.decrypter != nil {
+		plaintext, _, derr := j.decrypter.Decrypt(tokenValue)
+		if derr != nil {
+			return errors.JwtTokenInvalid.With(derr)
+		}
+		tokenValue = string(plaintext)
+	}
 
 	expectedClaims, err := j.getConfiguredClaims(req)
 	if err != nil {
@@ -250,6 +293,29 @@ func (j *JWT) Validate(req *http.Request) error {
 		return errors.JwtTokenInvalid.With(err)
 	}
 
+	if j.certificateBound {
+		if err = validateCertificateBinding(req, tokenClaims); err != nil {
+			return err
+		}
+	}
+
+	if j.dpop {
+		if err = j.validateDPoP(req, accessToken, tokenClaims); err != nil {
+			return err
+		}
+	}
+
+	if j.revocationList != nil {
+		revocationData, rerr := j.revocationList.Data()
+		if rerr != nil {
+			return errors.JwtTokenInvalid.With(rerr)
+		}
+
+		if reason, revoked := revocationData.Revoked(tokenClaims); revoked {
+			return errors.JwtTokenRevoked.Message(reason)
+		}
+	}
+
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
@@ -273,6 +339,60 @@ func (j *JWT) Validate(req *http.Request) error {
 	return nil
 }
 
+// validateCertificateBinding validates the binding of the token to the client certificate
+// presented on the connection via the "cnf" claim, see RFC 8705, section 3.
+func validateCertificateBinding(req *http.Request, tokenClaims jwt.MapClaims) error {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return errors.JwtCertificateBindingInvalid.Message("no client certificate presented")
+	}
+
+	cnf, _ := tokenClaims["cnf"].(map[string]interface{})
+	x5t, _ := cnf["x5t#S256"].(string)
+	if x5t == "" {
+		return errors.JwtCertificateBindingInvalid.Message("missing cnf.x5t#S256 claim")
+	}
+
+	thumbprint := sha256.Sum256(req.TLS.PeerCertificates[0].Raw)
+	if x5t != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+		return errors.JwtCertificateBindingInvalid.Message("client certificate does not match the token binding")
+	}
+
+	return nil
+}
+
+// validateDPoP validates the DPoP proof sent with the access token and its binding
+// to the token via the "cnf" claim, see RFC 9449.
+func (j *JWT) validateDPoP(req *http.Request, accessToken string, tokenClaims jwt.MapClaims) error {
+	proofs := req.Header.Values("DPoP")
+	if len(proofs) != 1 {
+		return errors.JwtDpopProofInvalid.Message("exactly one DPoP proof required")
+	}
+
+	now := time.Now()
+	proof, err := acjwt.ParseDPoPProof(proofs[0], req.Method, req.URL, accessToken, now)
+	if err != nil {
+		return errors.JwtDpopProofInvalid.With(err)
+	}
+
+	cnf, _ := tokenClaims["cnf"].(map[string]interface{})
+	jkt, _ := cnf["jkt"].(string)
+	if jkt == "" {
+		return errors.JwtTokenInvalid.Message("missing cnf.jkt claim")
+	}
+	if jkt != proof.Thumbprint {
+		return errors.JwtDpopProofInvalid.Message("proof key does not match the token binding")
+	}
+
+	hash := sha256.Sum256([]byte(proof.Thumbprint + ":" + proof.JTI))
+	key := "dpop:" + j.name + ":" + hex.EncodeToString(hash[:])
+	// remember the proof as long as it could be accepted
+	if !j.memStore.SetIfAbsent(key, now.Format(time.RFC3339), int64((acjwt.DPoPProofMaxAge + acjwt.DPoPProofLeeway).Seconds())) {
+		return errors.JwtDpopProofInvalid.Message("proof has already been used")
+	}
+
+	return nil
+}
+
 func (j *JWT) getValidationKey(token *jwt.Token) (interface{}, error) {
 	if j.jwks != nil {
 		return j.jwks.GetSigKeyForToken(token)
@@ -361,7 +481,7 @@ func (j *JWT) getGrantedPermissions(tokenClaims jwt.MapClaims, log *logrus.Entry
 
 	grantedPermissions = j.addPermissionsFromRoles(tokenClaims, grantedPermissions, log)
 
-	grantedPermissions = j.addMappedPermissions(grantedPermissions, grantedPermissions)
+	grantedPermissions = addMappedPermissions(j.permissionsMap, grantedPermissions, grantedPermissions)
 
 	return grantedPermissions
 }
@@ -458,13 +578,14 @@ func (j *JWT) addPermissionsFromRoles(tokenClaims jwt.MapClaims, permissions []s
 	return permissions
 }
 
-func (j *JWT) addMappedPermissions(source, target []string) []string {
-	if j.permissionsMap == nil {
+// addMappedPermissions adds the permissions mapped from the source permissions to the target.
+func addMappedPermissions(permissionsMap map[string][]string, source, target []string) []string {
+	if permissionsMap == nil {
 		return target
 	}
 
 	for _, val := range source {
-		mappedValues, exist := j.permissionsMap[val]
+		mappedValues, exist := permissionsMap[val]
 		if !exist {
 			// no mapping for value
 			continue
@@ -481,7 +602,7 @@ func (j *JWT) addMappedPermissions(source, target []string) []string {
 			l = append(l, mv)
 		}
 		// recursion: call only with values not already in target
-		target = j.addMappedPermissions(l, target)
+		target = addMappedPermissions(permissionsMap, l, target)
 	}
 	return target
 }
@@ -16,11 +16,23 @@ const (
 	AlgorithmECDSA256
 	AlgorithmECDSA384
 	AlgorithmECDSA512
+	AlgorithmRSAPSS256
+	AlgorithmRSAPSS384
+	AlgorithmRSAPSS512
+	AlgorithmEdDSA
 )
 
 var RSAAlgorithms = []Algorithm{AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512}
 var ECDSAlgorithms = []Algorithm{AlgorithmECDSA256, AlgorithmECDSA384, AlgorithmECDSA512}
 
+// PublicKeyAlgorithms lists all supported algorithms verifying signatures with a public key.
+var PublicKeyAlgorithms = []Algorithm{
+	AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512,
+	AlgorithmRSAPSS256, AlgorithmRSAPSS384, AlgorithmRSAPSS512,
+	AlgorithmECDSA256, AlgorithmECDSA384, AlgorithmECDSA512,
+	AlgorithmEdDSA,
+}
+
 func NewAlgorithm(a string) Algorithm {
 	switch a {
 	case "RS256":
@@ -41,6 +53,14 @@ func NewAlgorithm(a string) Algorithm {
 		return AlgorithmECDSA384
 	case "ES512":
 		return AlgorithmECDSA512
+	case "PS256":
+		return AlgorithmRSAPSS256
+	case "PS384":
+		return AlgorithmRSAPSS384
+	case "PS512":
+		return AlgorithmRSAPSS512
+	case "EdDSA":
+		return AlgorithmEdDSA
 	default:
 		return AlgorithmUnknown
 	}
@@ -75,6 +95,14 @@ func (a Algorithm) String() string {
 		return "ES384"
 	case AlgorithmECDSA512:
 		return "ES512"
+	case AlgorithmRSAPSS256:
+		return "PS256"
+	case AlgorithmRSAPSS384:
+		return "PS384"
+	case AlgorithmRSAPSS512:
+		return "PS512"
+	case AlgorithmEdDSA:
+		return "EdDSA"
 	default:
 		return "Unknown"
 	}
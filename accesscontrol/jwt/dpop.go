@@ -0,0 +1,131 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+)
+
+const (
+	// DPoPProofType is the required "typ" header value of a DPoP proof.
+	DPoPProofType = "dpop+jwt"
+	// DPoPProofMaxAge is the time period a DPoP proof is accepted after its creation.
+	DPoPProofMaxAge = 5 * time.Minute
+	// DPoPProofLeeway compensates clock skew for proofs created "in the future".
+	DPoPProofLeeway = time.Minute
+)
+
+var dpopParser = newDPoPParser()
+
+func newDPoPParser() *jwt.Parser {
+	var algos []string
+	for _, a := range PublicKeyAlgorithms {
+		algos = append(algos, a.String())
+	}
+	// iat is checked against the proof's max age
+	return jwt.NewParser(jwt.WithValidMethods(algos), jwt.WithoutClaimsValidation())
+}
+
+// DPoPProof represents a validated DPoP proof, see RFC 9449.
+type DPoPProof struct {
+	// JTI is the unique identifier of the proof.
+	JTI string
+	// Thumbprint is the JWK SHA-256 thumbprint of the public key the proof is signed with.
+	Thumbprint string
+}
+
+// ParseDPoPProof validates the given DPoP proof JWT against the request method, the
+// request URL and the access token the proof has been sent with, see RFC 9449, section 4.3.
+func ParseDPoPProof(proof, method string, u *url.URL, accessToken string, now time.Time) (*DPoPProof, error) {
+	var thumbprint string
+	claims := jwt.MapClaims{}
+	_, err := dpopParser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != DPoPProofType {
+			return nil, fmt.Errorf("typ header must be %q", DPoPProofType)
+		}
+
+		rawJWK, ok := token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("missing jwk header")
+		}
+		if _, exists := rawJWK["d"]; exists {
+			return nil, fmt.Errorf("jwk header must not contain a private key")
+		}
+
+		b, err := json.Marshal(rawJWK)
+		if err != nil {
+			return nil, err
+		}
+
+		var key jwk.JWK
+		if err = json.Unmarshal(b, &key); err != nil || key.Key == nil {
+			return nil, fmt.Errorf("invalid jwk header")
+		}
+
+		thumbprint, err = jwk.Thumbprint(key.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		return key.Key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, fmt.Errorf("missing jti claim")
+	}
+
+	if htm, _ := claims["htm"].(string); htm != method {
+		return nil, fmt.Errorf("htm claim does not match the request method")
+	}
+
+	htu, _ := claims["htu"].(string)
+	if target, perr := url.Parse(htu); perr != nil || htu == "" || normalizeURL(target) != normalizeURL(u) {
+		return nil, fmt.Errorf("htu claim does not match the request URL")
+	}
+
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("missing iat claim")
+	}
+	issuedAt := time.Unix(int64(iat), 0)
+	if issuedAt.Before(now.Add(-DPoPProofMaxAge)) || issuedAt.After(now.Add(DPoPProofLeeway)) {
+		return nil, fmt.Errorf("iat claim is outside of the acceptable time window")
+	}
+
+	hash := sha256.Sum256([]byte(accessToken))
+	if ath, _ := claims["ath"].(string); ath != base64.RawURLEncoding.EncodeToString(hash[:]) {
+		return nil, fmt.Errorf("ath claim does not match the access token")
+	}
+
+	return &DPoPProof{JTI: jti, Thumbprint: thumbprint}, nil
+}
+
+// normalizeURL returns the URL without query and fragment and with
+// a lower-case scheme and host, omitting the default port.
+func normalizeURL(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host = net.JoinHostPort(host, port)
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return scheme + "://" + host + path
+}
@@ -0,0 +1,118 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+)
+
+func TestParseDPoPProof(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicJWK := map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	privateJWK := map[string]interface{}{"d": "secret"}
+	for k, v := range publicJWK {
+		privateJWK[k] = v
+	}
+
+	expThumbprint, err := jwk.Thumbprint(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const accessToken = "the.access.token"
+	hash := sha256.Sum256([]byte(accessToken))
+	ath := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	now := time.Now()
+	u, _ := url.Parse("https://api.example.com/resource?foo=bar")
+
+	newProof := func(header, claims map[string]interface{}) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims(claims))
+		for k, v := range header {
+			token.Header[k] = v
+		}
+		proof, serr := token.SignedString(key)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		return proof
+	}
+	validHeader := map[string]interface{}{"typ": "dpop+jwt", "jwk": publicJWK}
+	validClaims := func(override map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"jti": "proof-id",
+			"htm": "POST",
+			"htu": "https://API.example.com:443/resource",
+			"iat": now.Unix(),
+			"ath": ath,
+		}
+		for k, v := range override {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+
+	for _, tc := range []struct {
+		name   string
+		proof  string
+		expErr string
+	}{
+		{"valid", newProof(validHeader, validClaims(nil)), ""},
+		{"missing typ", newProof(map[string]interface{}{"jwk": publicJWK}, validClaims(nil)), `token is unverifiable: error while executing keyfunc: typ header must be "dpop+jwt"`},
+		{"missing jwk", newProof(map[string]interface{}{"typ": "dpop+jwt"}, validClaims(nil)), "token is unverifiable: error while executing keyfunc: missing jwk header"},
+		{"private jwk", newProof(map[string]interface{}{"typ": "dpop+jwt", "jwk": privateJWK}, validClaims(nil)), "token is unverifiable: error while executing keyfunc: jwk header must not contain a private key"},
+		{"missing jti", newProof(validHeader, validClaims(map[string]interface{}{"jti": nil})), "missing jti claim"},
+		{"wrong htm", newProof(validHeader, validClaims(map[string]interface{}{"htm": "GET"})), "htm claim does not match the request method"},
+		{"wrong htu", newProof(validHeader, validClaims(map[string]interface{}{"htu": "https://api.example.com/other"})), "htu claim does not match the request URL"},
+		{"missing iat", newProof(validHeader, validClaims(map[string]interface{}{"iat": nil})), "missing iat claim"},
+		{"outdated iat", newProof(validHeader, validClaims(map[string]interface{}{"iat": now.Add(-time.Hour).Unix()})), "iat claim is outside of the acceptable time window"},
+		{"future iat", newProof(validHeader, validClaims(map[string]interface{}{"iat": now.Add(time.Hour).Unix()})), "iat claim is outside of the acceptable time window"},
+		{"wrong ath", newProof(validHeader, validClaims(map[string]interface{}{"ath": "foo"})), "ath claim does not match the access token"},
+		{"symmetric", func() string {
+			proof, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(validClaims(nil))).SignedString([]byte("secret"))
+			return proof
+		}(), "token signature is invalid: signing method HS256 is invalid"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			proof, perr := acjwt.ParseDPoPProof(tc.proof, "POST", u, accessToken, now)
+			if tc.expErr != "" {
+				if perr == nil || perr.Error() != tc.expErr {
+					st.Errorf("want error %q, got %v", tc.expErr, perr)
+				}
+				return
+			}
+
+			if perr != nil {
+				st.Fatal(perr)
+			}
+			if proof.JTI != "proof-id" {
+				st.Errorf("want jti %q, got %q", "proof-id", proof.JTI)
+			}
+			if proof.Thumbprint != expThumbprint {
+				st.Errorf("want thumbprint %q, got %q", expThumbprint, proof.Thumbprint)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	jsn "github.com/coupergateway/couper/json"
+)
+
+// RevocationListData represents a list of revoked tokens.
+type RevocationListData struct {
+	// IssuedBefore revokes all tokens issued before the given unix timestamp.
+	IssuedBefore int64 `json:"iat_before"`
+	// JTIs revokes tokens by their "jti" claim.
+	JTIs []string `json:"jti"`
+	// Subjects revokes all tokens of the given subjects.
+	Subjects []string `json:"sub"`
+	// SubjectsIssuedBefore revokes the tokens of a subject issued before the given unix timestamp.
+	SubjectsIssuedBefore map[string]int64 `json:"sub_iat_before"`
+
+	// internally used
+	jtis     map[string]struct{}
+	subjects map[string]struct{}
+}
+
+// Revoked checks the given token claims against the revocation list and
+// returns the reason if the token has been revoked.
+func (d *RevocationListData) Revoked(claims map[string]interface{}) (string, bool) {
+	if jti, ok := claims["jti"].(string); ok {
+		if _, revoked := d.jtis[jti]; revoked {
+			return "token revoked by jti", true
+		}
+	}
+
+	sub, _ := claims["sub"].(string)
+	if _, revoked := d.subjects[sub]; revoked && sub != "" {
+		return "token revoked by sub", true
+	}
+
+	issuedBefore := d.IssuedBefore
+	if subIssuedBefore := d.SubjectsIssuedBefore[sub]; sub != "" && subIssuedBefore > issuedBefore {
+		issuedBefore = subIssuedBefore
+	}
+
+	if issuedBefore > 0 {
+		// tokens without iat claim cannot prove to be issued afterwards
+		iat, ok := claims["iat"].(float64)
+		if !ok || int64(iat) < issuedBefore {
+			return "token revoked by iat", true
+		}
+	}
+
+	return "", false
+}
+
+// RevocationList provides the (synced) revocation list data.
+type RevocationList struct {
+	syncedJSON *jsn.SyncedJSON
+	storeList  *storeRevocationList
+}
+
+// storeRevocationList reads the revocation list from a key of the cache store,
+// which may be shared with other instances.
+type storeRevocationList struct {
+	data      *RevocationListData
+	err       error
+	fetchedAt time.Time
+	key       string
+	lastCheck time.Time
+	maxStale  time.Duration
+	mu        sync.Mutex
+	raw       string
+	store     cache.Store
+	ttl       time.Duration
+}
+
+func NewRevocationList(ctx context.Context, uri string, ttl string, maxStale string, transport http.RoundTripper, store cache.Store) (*RevocationList, error) {
+	timetolive, err := config.ParseDuration("revocation_ttl", ttl, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	maxStaleTime, err := config.ParseDuration("revocation_max_stale", maxStale, time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	var file string
+	if strings.HasPrefix(uri, "store:") {
+		if uri == "store:" || store == nil {
+			return nil, fmt.Errorf("invalid revocation store URI: %q", uri)
+		}
+
+		return &RevocationList{storeList: &storeRevocationList{
+			key:      uri[6:],
+			maxStale: maxStaleTime,
+			store:    store,
+			ttl:      timetolive,
+		}}, nil
+	} else if strings.HasPrefix(uri, "file:") {
+		file = uri[5:]
+	} else if !strings.HasPrefix(uri, "http:") && !strings.HasPrefix(uri, "https:") {
+		return nil, fmt.Errorf("unsupported revocation URI scheme: %q", uri)
+	}
+
+	rl := &RevocationList{}
+	rl.syncedJSON, err = jsn.NewSyncedJSON(ctx, file, "revocation_url", uri, transport, "revocation", timetolive, maxStaleTime, rl)
+	return rl, err
+}
+
+func (r *RevocationList) Data() (*RevocationListData, error) {
+	if r.storeList != nil {
+		return r.storeList.Data()
+	}
+
+	data, err := r.syncedJSON.Data()
+	// Ignore backend errors as long as we still get cached (stale) data.
+	rlData, ok := data.(*RevocationListData)
+	if !ok {
+		return nil, fmt.Errorf("received no valid revocation list data: %#v, %w", data, err)
+	}
+
+	return rlData, nil
+}
+
+func (r *RevocationList) Unmarshal(rawJSON []byte) (interface{}, error) {
+	return parseRevocationList(rawJSON)
+}
+
+func parseRevocationList(rawJSON []byte) (*RevocationListData, error) {
+	jsonData := &RevocationListData{}
+	if err := json.Unmarshal(rawJSON, jsonData); err != nil {
+		return nil, err
+	}
+
+	jsonData.jtis = make(map[string]struct{}, len(jsonData.JTIs))
+	for _, jti := range jsonData.JTIs {
+		jsonData.jtis[jti] = struct{}{}
+	}
+
+	jsonData.subjects = make(map[string]struct{}, len(jsonData.Subjects))
+	for _, sub := range jsonData.Subjects {
+		jsonData.subjects[sub] = struct{}{}
+	}
+
+	return jsonData, nil
+}
+
+// Data returns the revocation list data which is read from the store at most once per TTL.
+// A missing list keeps the former data until the max-stale time has passed; afterwards
+// an error is returned, so tokens are not accepted without a current list.
+func (s *storeRevocationList) Data() (*RevocationListData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastCheck) < s.ttl {
+		return s.data, s.err
+	}
+	s.lastCheck = time.Now()
+
+	raw, _ := s.store.Get(s.key).(string)
+	if raw == "" {
+		if s.fetchedAt.IsZero() { // never written so far
+			s.data, s.err = &RevocationListData{}, nil
+		} else if time.Since(s.fetchedAt) > s.maxStale {
+			s.err, s.raw = fmt.Errorf("revocation list missing in store: %q", s.key), ""
+		}
+		return s.data, s.err
+	}
+
+	s.fetchedAt = s.lastCheck
+	if raw == s.raw {
+		return s.data, s.err
+	}
+
+	data, err := parseRevocationList([]byte(raw))
+	if err != nil {
+		s.data, s.err, s.raw = nil, fmt.Errorf("invalid revocation list in store: %w", err), raw
+		return nil, s.err
+	}
+
+	s.data, s.err, s.raw = data, nil, raw
+	return s.data, nil
+}
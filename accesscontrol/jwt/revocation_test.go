@@ -0,0 +1,202 @@
+package jwt_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/cache"
+	jsn "github.com/coupergateway/couper/json"
+)
+
+func Test_NewRevocationList(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		uri    string
+		expErr string
+	}{
+		{"unsupported scheme", "ftp://example.com/revoked.json", `unsupported revocation URI scheme: "ftp://example.com/revoked.json"`},
+		{"missing file", "file:testdata/not-there.json", "not-there.json: no such file or directory"},
+		{"ok file", "file:testdata/revocation.json", ""},
+		{"missing store", "store:revocations", `invalid revocation store URI: "store:revocations"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := acjwt.NewRevocationList(context.TODO(), tc.uri, "", "", nil, nil)
+			if tc.expErr == "" && err != nil {
+				st.Fatal(err)
+			}
+			if tc.expErr != "" && (err == nil || !hasSuffix(err, tc.expErr)) {
+				st.Errorf("want error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestRevocationListData_Revoked(t *testing.T) {
+	rl, err := acjwt.NewRevocationList(context.TODO(), "file:testdata/revocation.json", "", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := rl.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		claims    map[string]interface{}
+		expReason string
+	}{
+		{"not revoked", map[string]interface{}{"jti": "id", "sub": "me", "iat": float64(1650000000)}, ""},
+		{"revoked jti", map[string]interface{}{"jti": "revoked-id", "sub": "me", "iat": float64(1650000000)}, "token revoked by jti"},
+		{"revoked sub", map[string]interface{}{"sub": "revoked-sub", "iat": float64(1650000000)}, "token revoked by sub"},
+		{"issued before", map[string]interface{}{"sub": "me", "iat": float64(1500000000)}, "token revoked by iat"},
+		{"missing iat", map[string]interface{}{"sub": "me"}, "token revoked by iat"},
+		{"sub issued before", map[string]interface{}{"sub": "logged-out", "iat": float64(1650000000)}, "token revoked by iat"},
+		{"sub issued after", map[string]interface{}{"sub": "logged-out", "iat": float64(1750000000)}, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			reason, revoked := data.Revoked(tc.claims)
+			if revoked != (tc.expReason != "") || reason != tc.expReason {
+				st.Errorf("want reason %q, got %q (revoked: %t)", tc.expReason, reason, revoked)
+			}
+		})
+	}
+}
+
+func TestRevocationList_Store(t *testing.T) {
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	store := cache.New(logrus.NewEntry(logrus.New()), quitCh)
+	rl, err := acjwt.NewRevocationList(context.TODO(), "store:revocations", "0s", "1h", nil, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]interface{}{"jti": "revoked-id"}
+	for _, tc := range []struct {
+		name       string
+		value      string
+		expRevoked bool
+		expErr     bool
+	}{
+		{"missing list", "", false, false},
+		{"revoked jti", `{"jti":["revoked-id"]}`, true, false},
+		{"deleted list keeps stale data", "", true, false},
+		{"other jti", `{"jti":["other-id"]}`, false, false},
+		{"invalid list", `{"jti":`, false, true},
+	} {
+		if tc.value == "" {
+			store.Del("revocations")
+		} else {
+			store.Set("revocations", tc.value, 60)
+		}
+
+		data, derr := rl.Data()
+		if (derr != nil) != tc.expErr {
+			t.Errorf("%s: unexpected error: %v", tc.name, derr)
+			continue
+		}
+		if derr != nil {
+			continue
+		}
+
+		if _, revoked := data.Revoked(claims); revoked != tc.expRevoked {
+			t.Errorf("%s: want revoked %t, got %t", tc.name, tc.expRevoked, revoked)
+		}
+	}
+}
+
+func TestRevocationList_StoreMaxStale(t *testing.T) {
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	store := cache.New(logrus.NewEntry(logrus.New()), quitCh)
+	rl, err := acjwt.NewRevocationList(context.TODO(), "store:revocations", "0s", "50ms", nil, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Set("revocations", `{"jti":["revoked-id"]}`, 60)
+	if _, err = rl.Data(); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Del("revocations")
+	data, err := rl.Data()
+	if err != nil {
+		t.Fatalf("unexpected error within max-stale time: %v", err)
+	}
+	if _, revoked := data.Revoked(map[string]interface{}{"jti": "revoked-id"}); !revoked {
+		t.Error("expected stale list to revoke the token")
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	expErr := `revocation list missing in store: "revocations"`
+	if _, err = rl.Data(); err == nil || err.Error() != expErr {
+		t.Errorf("want error %q, got %v", expErr, err)
+	}
+
+	store.Set("revocations", `{"jti":["revoked-id"]}`, 60)
+	if _, err = rl.Data(); err != nil {
+		t.Errorf("expected recovery with the restored list, got %v", err)
+	}
+}
+
+func TestRevocationList_FileReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "revocation.json")
+	if err := os.WriteFile(file, []byte(`{"jti":["a"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	interval := jsn.FileCheckInterval
+	jsn.FileCheckInterval = 0
+	defer func() { jsn.FileCheckInterval = interval }()
+
+	rl, err := acjwt.NewRevocationList(context.TODO(), "file:"+file, "", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(file, []byte(`{"jti":["a","b"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := rl.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, revoked := data.Revoked(map[string]interface{}{"jti": "b"}); !revoked {
+		t.Error("expected changed revocation list to be reloaded")
+	}
+
+	if err = os.WriteFile(file, []byte(`{"jti":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rl.Data(); err == nil {
+		t.Error("expected an error for an invalid revocation list")
+	}
+}
+
+func hasSuffix(err error, suffix string) bool {
+	type logError interface {
+		LogError() string
+	}
+
+	msg := err.Error()
+	if lerr, ok := err.(logError); ok {
+		msg = lerr.LogError()
+	}
+	return strings.HasSuffix(msg, suffix)
+}
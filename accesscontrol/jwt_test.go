@@ -2,9 +2,15 @@ package accesscontrol_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"net/http"
@@ -13,6 +19,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,6 +30,8 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/accesscontrol/jwe"
+	"github.com/coupergateway/couper/accesscontrol/jwk"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
@@ -169,10 +178,18 @@ func Test_JWT_Validate(t *testing.T) {
 
 	for _, signingMethod := range []jwt.SigningMethod{
 		jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512,
+		jwt.SigningMethodPS256, jwt.SigningMethodPS384, jwt.SigningMethodPS512,
 		jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512,
+		jwt.SigningMethodEdDSA,
 	} {
 
-		pubKeyBytes, privKey := newRSAKeyPair()
+		var pubKeyBytes []byte
+		var privKey interface{}
+		if signingMethod == jwt.SigningMethodEdDSA {
+			pubKeyBytes, privKey = newEd25519KeyPair()
+		} else {
+			pubKeyBytes, privKey = newRSAKeyPair()
+		}
 
 		tok := jwt.NewWithClaims(signingMethod, jwt.MapClaims{
 			"aud":     "peter",
@@ -368,6 +385,322 @@ func Test_JWT_Validate(t *testing.T) {
 	}
 }
 
+func Test_JWT_Validate_Encrypted(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	logger := log.WithContext(context.Background())
+	memStore := cache.New(logger, tmpStoreCh)
+
+	secret := []byte("mySecretK3y")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "me"}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypt := func(key string) string {
+		encrypter, eerr := jwe.NewEncrypter("dir", "A256GCM", []byte(key))
+		if eerr != nil {
+			t.Fatal(eerr)
+		}
+		encrypted, eerr := encrypter.Encrypt([]byte(token), map[string]interface{}{"cty": "JWT"})
+		if eerr != nil {
+			t.Fatal(eerr)
+		}
+		return encrypted
+	}
+
+	j, err := ac.NewJWT(&config.JWT{
+		Bearer:             true,
+		Name:               "test_ac",
+		SignatureAlgorithm: "HS256",
+	}, secret, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypter, err := jwe.NewDecrypter([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.SetDecrypter(decrypter)
+
+	for _, tc := range []struct {
+		name        string
+		token       string
+		wantErrKind string
+	}{
+		{"encrypted", encrypt("0123456789abcdef0123456789abcdef"), ""},
+		{"encrypted with other key", encrypt("fedcba9876543210fedcba9876543210"), "jwt_token_invalid"},
+		{"not encrypted", token, "jwt_token_invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, logger))
+
+			errKind := ""
+			if verr := j.Validate(req); verr != nil {
+				errKind = verr.(*errors.Error).Kinds()[0]
+			}
+			if errKind != tc.wantErrKind {
+				subT.Errorf("Validate() error kind does not match; want: %q, got: %q", tc.wantErrKind, errKind)
+			}
+
+			if tc.wantErrKind == "" {
+				acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+				if claims, _ := acMap["test_ac"].(map[string]interface{}); claims["sub"] != "me" {
+					subT.Errorf("want sub claim %q, got %#v", "me", claims)
+				}
+			}
+		})
+	}
+}
+
+func Test_JWT_Validate_Revoked(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	logger := log.WithContext(context.Background())
+	memStore := cache.New(logger, tmpStoreCh)
+
+	secret := []byte("mySecretK3y")
+	j, err := ac.NewJWT(&config.JWT{
+		Bearer:             true,
+		Name:               "test_ac",
+		SignatureAlgorithm: "HS256",
+	}, secret, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revocationList, err := acjwt.NewRevocationList(context.TODO(), "file:jwt/testdata/revocation.json", "", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.SetRevocationList(revocationList)
+
+	iat := time.Now().Unix()
+	for _, tc := range []struct {
+		name        string
+		claims      jwt.MapClaims
+		wantErrKind string
+	}{
+		{"valid", jwt.MapClaims{"jti": "id", "sub": "me", "iat": iat}, ""},
+		{"revoked jti", jwt.MapClaims{"jti": "revoked-id", "sub": "me", "iat": iat}, "jwt_token_revoked"},
+		{"revoked sub", jwt.MapClaims{"sub": "revoked-sub", "iat": iat}, "jwt_token_revoked"},
+		{"missing iat", jwt.MapClaims{"sub": "me"}, "jwt_token_revoked"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			token, terr := jwt.NewWithClaims(jwt.SigningMethodHS256, tc.claims).SignedString(secret)
+			if terr != nil {
+				subT.Fatal(terr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, logger))
+
+			errKind := ""
+			if verr := j.Validate(req); verr != nil {
+				errKind = verr.(*errors.Error).Kinds()[0]
+			}
+			if errKind != tc.wantErrKind {
+				subT.Errorf("Validate() error kind does not match; want: %q, got: %q", tc.wantErrKind, errKind)
+			}
+		})
+	}
+}
+
+func Test_JWT_Validate_CertificateBound(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	logger := log.WithContext(context.Background())
+	memStore := cache.New(logger, tmpStoreCh)
+
+	secret := []byte("mySecretK3y")
+	j, err := ac.NewJWT(&config.JWT{
+		Bearer:             true,
+		CertificateBound:   true,
+		Name:               "test_ac",
+		SignatureAlgorithm: "HS256",
+	}, secret, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := &x509.Certificate{Raw: []byte("raw")}
+	thumbprint := sha256.Sum256(cert.Raw)
+	x5t := base64.RawURLEncoding.EncodeToString(thumbprint[:])
+
+	for _, tc := range []struct {
+		name        string
+		claims      jwt.MapClaims
+		tlsState    *tls.ConnectionState
+		wantErrKind string
+	}{
+		{"bound", jwt.MapClaims{"cnf": map[string]interface{}{"x5t#S256": x5t}}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, ""},
+		{"other certificate", jwt.MapClaims{"cnf": map[string]interface{}{"x5t#S256": "other"}}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, "jwt_certificate_binding_invalid"},
+		{"missing cnf", jwt.MapClaims{"sub": "me"}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, "jwt_certificate_binding_invalid"},
+		{"no client certificate", jwt.MapClaims{"cnf": map[string]interface{}{"x5t#S256": x5t}}, &tls.ConnectionState{}, "jwt_certificate_binding_invalid"},
+		{"no tls", jwt.MapClaims{"cnf": map[string]interface{}{"x5t#S256": x5t}}, nil, "jwt_certificate_binding_invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			token, terr := jwt.NewWithClaims(jwt.SigningMethodHS256, tc.claims).SignedString(secret)
+			if terr != nil {
+				subT.Fatal(terr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.TLS = tc.tlsState
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, logger))
+
+			errKind := ""
+			if verr := j.Validate(req); verr != nil {
+				errKind = verr.(*errors.Error).Kinds()[0]
+			}
+			if errKind != tc.wantErrKind {
+				subT.Errorf("Validate() error kind does not match; want: %q, got: %q", tc.wantErrKind, errKind)
+			}
+		})
+	}
+}
+
+// slowReadStore widens the window between reading and writing a key to reveal non-atomic checks.
+type slowReadStore struct {
+	*cache.MemoryStore
+}
+
+func (s *slowReadStore) Get(k string) interface{} {
+	v := s.MemoryStore.Get(k)
+	time.Sleep(time.Millisecond * 10)
+	return v
+}
+
+func Test_JWT_Validate_DPoP(t *testing.T) {
+	log, _ := test.NewLogger()
+	tmpStoreCh := make(chan struct{})
+	defer close(tmpStoreCh)
+	logger := log.WithContext(context.Background())
+	memStore := &slowReadStore{cache.New(logger, tmpStoreCh)}
+
+	secret := []byte("mySecretK3y")
+	j, err := ac.NewJWT(&config.JWT{
+		DPoP:               true,
+		Name:               "test_ac",
+		SignatureAlgorithm: "HS256",
+	}, secret, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proofKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jkt, err := jwk.Thumbprint(&proofKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newToken := func(claims jwt.MapClaims) string {
+		token, terr := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if terr != nil {
+			t.Fatal(terr)
+		}
+		return token
+	}
+	newProof := func(key *ecdsa.PrivateKey, jti, accessToken string) string {
+		hash := sha256.Sum256([]byte(accessToken))
+		proof := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"jti": jti,
+			"htm": http.MethodGet,
+			"htu": "https://api.example.com/resource",
+			"iat": time.Now().Unix(),
+			"ath": base64.RawURLEncoding.EncodeToString(hash[:]),
+		})
+		proof.Header["typ"] = "dpop+jwt"
+		proof.Header["jwk"] = map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}
+		signed, serr := proof.SignedString(key)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		return signed
+	}
+
+	boundToken := newToken(jwt.MapClaims{"sub": "me", "cnf": map[string]interface{}{"jkt": jkt}})
+	unboundToken := newToken(jwt.MapClaims{"sub": "me"})
+	replayedProof := newProof(proofKey, "replayed", boundToken)
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		proofs        []string
+		wantErrKind   string
+	}{
+		{"valid", "DPoP " + boundToken, []string{newProof(proofKey, "1", boundToken)}, ""},
+		{"first use", "DPoP " + boundToken, []string{replayedProof}, ""},
+		{"replay", "DPoP " + boundToken, []string{replayedProof}, "jwt_dpop_proof_invalid"},
+		{"bearer", "Bearer " + boundToken, []string{newProof(proofKey, "2", boundToken)}, "jwt_token_missing"},
+		{"missing proof", "DPoP " + boundToken, nil, "jwt_dpop_proof_invalid"},
+		{"multiple proofs", "DPoP " + boundToken, []string{newProof(proofKey, "3", boundToken), newProof(proofKey, "4", boundToken)}, "jwt_dpop_proof_invalid"},
+		{"invalid proof", "DPoP " + boundToken, []string{newProof(proofKey, "5", unboundToken)}, "jwt_dpop_proof_invalid"},
+		{"unbound token", "DPoP " + unboundToken, []string{newProof(proofKey, "6", unboundToken)}, "jwt_token_invalid"},
+		{"other key", "DPoP " + boundToken, []string{newProof(otherKey, "7", boundToken)}, "jwt_dpop_proof_invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "https://api.example.com/resource", nil)
+			req.Header.Set("Authorization", tc.authorization)
+			for _, proof := range tc.proofs {
+				req.Header.Add("DPoP", proof)
+			}
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, logger))
+
+			errKind := ""
+			if verr := j.Validate(req); verr != nil {
+				errKind = verr.(*errors.Error).Kinds()[0]
+			}
+			if errKind != tc.wantErrKind {
+				subT.Errorf("Validate() error kind does not match; want: %q, got: %q", tc.wantErrKind, errKind)
+			}
+		})
+	}
+
+	// concurrent requests with the same proof
+	parallelProof := newProof(proofKey, "parallel", boundToken)
+	var accepted int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "https://api.example.com/resource", nil)
+			req.Header.Set("Authorization", "DPoP "+boundToken)
+			req.Header.Set("DPoP", parallelProof)
+			req = req.WithContext(context.WithValue(context.Background(), request.LogEntry, logger))
+			if j.Validate(req) == nil {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 1 {
+		t.Errorf("expected exactly one accepted request with the same proof, got %d", accepted)
+	}
+}
+
 func Test_JWT_Validate_claims(t *testing.T) {
 	log, _ := test.NewLogger()
 	tmpStoreCh := make(chan struct{})
@@ -1053,6 +1386,36 @@ func TestJwtConfig(t *testing.T) {
 			`,
 			"configuration error: myac: token source is invalid",
 		},
+		{
+			"dpop + bearer",
+			`
+			server "test" {}
+			definitions {
+			  jwt "myac" {
+			    signature_algorithm = "HS256"
+			    dpop = true
+			    bearer = true
+			    key = "..."
+			  }
+			}
+			`,
+			"configuration error: myac: dpop cannot be used together with bearer, cookie, header or token_value",
+		},
+		{
+			"dpop + token_value",
+			`
+			server "test" {}
+			definitions {
+			  jwt "myac" {
+			    signature_algorithm = "HS256"
+			    dpop = true
+			    token_value = env.TOKEN
+			    key = "..."
+			  }
+			}
+			`,
+			"configuration error: myac: dpop cannot be used together with bearer, cookie, header or token_value",
+		},
 		{
 			"ok: signature_algorithm + key_file",
 			`
@@ -1148,7 +1511,7 @@ func TestJwtConfig(t *testing.T) {
 			  backend "foo" {}
 			}
 			`,
-			"configuration error: myac: backend is obsolete without jwks_url attribute",
+			"configuration error: myac: backend is obsolete without jwks_url or revocation_url attribute",
 		},
 	}
 
@@ -1221,6 +1584,24 @@ func newRSAKeyPair() (pubKeyBytes []byte, privKey *rsa.PrivateKey) {
 	return
 }
 
+func newEd25519KeyPair() (pubKeyBytes []byte, privKey ed25519.PrivateKey) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	bytes, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		panic(err)
+	}
+
+	pubKeyBytes = pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: bytes,
+	})
+	return
+}
+
 func setCookieAndHeader(req *http.Request, key, value string) *http.Request {
 	req.Header.Set(key, value)
 	req.Header.Set("Cookie", key+"="+value)
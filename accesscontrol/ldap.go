@@ -0,0 +1,317 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ AccessControl = &LDAP{}
+
+const ldapUsernamePlaceholder = "{username}"
+
+// LDAP represents an AC-LDAP object which checks Basic credentials with a bind
+// at an LDAP server.
+type LDAP struct {
+	baseDN           string
+	bindDN           string
+	bindPassword     string
+	cacheKey         []byte
+	cacheTTL         time.Duration
+	groupAttribute   string
+	groupPermissions []patternPermissions
+	memStore         cache.Store
+	name             string
+	permissionsMap   map[string][]string
+	startTLS         bool
+	timeout          time.Duration
+	tlsConfig        *tls.Config
+	url              string
+	userDN           string
+	userFilter       string
+}
+
+// ldapUser is the (cached) result of a successful bind.
+type ldapUser struct {
+	DN     string   `json:"dn"`
+	Groups []string `json:"groups"`
+}
+
+// NewLDAP creates a new AC-LDAP object.
+func NewLDAP(conf *config.LDAP, caCertificates []byte, permissionsMap map[string][]string, memStore cache.Store) (*LDAP, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("url: %w", err)
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("url: scheme must be either %q or %q", "ldap", "ldaps")
+	}
+	if conf.StartTLS && u.Scheme == "ldaps" {
+		return nil, fmt.Errorf("start_tls: cannot be used with an %q url", "ldaps")
+	}
+
+	if (conf.UserDN == "") == (conf.UserFilter == "") {
+		return nil, fmt.Errorf("either user_dn or user_filter is required")
+	}
+	if conf.UserFilter != "" && conf.BaseDN == "" {
+		return nil, fmt.Errorf("base_dn is required with user_filter")
+	}
+	if conf.BindDN == "" && conf.BindPassword != "" {
+		return nil, fmt.Errorf("bind_password requires bind_dn")
+	}
+
+	cacheTTL, err := config.ParseDuration("cache_ttl", conf.CacheTTL, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := config.ParseDuration("timeout", conf.Timeout, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: conf.DisableCertificateValidation,
+		ServerName:         u.Hostname(),
+	}
+	if len(caCertificates) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCertificates) {
+			return nil, fmt.Errorf("ca_file: no valid certificate found")
+		}
+	}
+
+	groupPermissions, err := compilePatternPermissions(conf.GroupPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("group_permissions: %w", err)
+	}
+
+	groupAttribute := conf.GroupAttribute
+	if groupAttribute == "" {
+		groupAttribute = "memberOf"
+	}
+
+	// The cache key is an HMAC of the credentials with a random key per instance,
+	// so cached entries in a shared store do not reveal password hashes.
+	cacheKey := make([]byte, 32)
+	if _, err = rand.Read(cacheKey); err != nil {
+		return nil, err
+	}
+
+	return &LDAP{
+		baseDN:           conf.BaseDN,
+		bindDN:           conf.BindDN,
+		bindPassword:     conf.BindPassword,
+		cacheKey:         cacheKey,
+		cacheTTL:         cacheTTL,
+		groupAttribute:   groupAttribute,
+		groupPermissions: groupPermissions,
+		memStore:         memStore,
+		name:             conf.Name,
+		permissionsMap:   permissionsMap,
+		startTLS:         conf.StartTLS,
+		timeout:          timeout,
+		tlsConfig:        tlsConfig,
+		url:              conf.URL,
+		userDN:           conf.UserDN,
+		userFilter:       conf.UserFilter,
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (l *LDAP) Validate(req *http.Request) error {
+	user, pass, ok := req.BasicAuth()
+	if !ok {
+		const prefix = "Basic "
+		if val := req.Header.Get("Authorization"); val == "" || !strings.HasPrefix(val, prefix) {
+			return errors.LdapCredentialsMissing.Message("credentials required")
+		}
+		return errors.Ldap.Message("reading authorization failed")
+	}
+
+	// An empty password would be an unauthenticated bind, see RFC 4513, section 5.1.2.
+	if user == "" || pass == "" {
+		return errors.Ldap.Message("credential mismatch")
+	}
+
+	key := l.key(user, pass)
+	if u := l.cached(key); u != nil {
+		return l.setContext(req, user, u)
+	}
+
+	u, err := l.bind(req.Context(), user, pass)
+	if err != nil {
+		return err
+	}
+
+	if l.cacheTTL > 0 && l.memStore != nil {
+		if b, merr := json.Marshal(u); merr == nil {
+			l.memStore.Set(key, string(b), int64(l.cacheTTL.Seconds()))
+		}
+	}
+
+	return l.setContext(req, user, u)
+}
+
+func (l *LDAP) bind(ctx context.Context, user, pass string) (*ldapUser, error) {
+	deadline := time.Now().Add(l.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	conn, err := ldap.DialURL(l.url,
+		ldap.DialWithDialer(&net.Dialer{Deadline: deadline}),
+		ldap.DialWithTLSConfig(l.tlsConfig))
+	if err != nil {
+		return nil, errors.Ldap.Message("connection failed").With(err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(time.Until(deadline))
+
+	if l.startTLS {
+		if err = conn.StartTLS(l.tlsConfig); err != nil {
+			return nil, errors.Ldap.Message("StartTLS failed").With(err)
+		}
+	}
+
+	u := &ldapUser{}
+	if l.userFilter != "" {
+		if l.bindDN != "" {
+			if err = conn.Bind(l.bindDN, l.bindPassword); err != nil {
+				return nil, errors.Ldap.Message("service account bind failed").With(err)
+			}
+		}
+
+		filter := strings.ReplaceAll(l.userFilter, ldapUsernamePlaceholder, ldap.EscapeFilter(user))
+		entry, err := l.search(conn, l.baseDN, ldap.ScopeWholeSubtree, filter)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, errors.Ldap.Message("credential mismatch").With(fmt.Errorf("user not found"))
+		}
+		u.DN = entry.DN
+		u.Groups = entry.GetAttributeValues(l.groupAttribute)
+	} else {
+		u.DN = strings.ReplaceAll(l.userDN, ldapUsernamePlaceholder, ldap.EscapeDN(user))
+	}
+
+	if err = conn.Bind(u.DN, pass); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errors.Ldap.Message("credential mismatch")
+		}
+		return nil, errors.Ldap.Message("bind failed").With(err)
+	}
+
+	if l.userFilter == "" && l.groupAttribute != "" {
+		// read the group memberships with the user's own permissions
+		entry, err := l.search(conn, u.DN, ldap.ScopeBaseObject, "(objectClass=*)")
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			u.Groups = entry.GetAttributeValues(l.groupAttribute)
+		}
+	}
+
+	return u, nil
+}
+
+func (l *LDAP) search(conn *ldap.Conn, baseDN string, scope int, filter string) (*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 2,
+		int(l.timeout.Seconds()), false, filter, []string{l.groupAttribute}, nil))
+	if err != nil {
+		return nil, errors.Ldap.Message("search failed").With(err)
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, nil
+	case 1:
+		return result.Entries[0], nil
+	default:
+		return nil, errors.Ldap.Message("search failed").With(fmt.Errorf("multiple entries found"))
+	}
+}
+
+func (l *LDAP) cached(key string) *ldapUser {
+	if l.cacheTTL <= 0 || l.memStore == nil {
+		return nil
+	}
+
+	stored, ok := l.memStore.Get(key).(string)
+	if !ok {
+		return nil
+	}
+
+	u := &ldapUser{}
+	if err := json.Unmarshal([]byte(stored), u); err != nil {
+		return nil
+	}
+	return u
+}
+
+func (l *LDAP) key(user, pass string) string {
+	mac := hmac.New(sha256.New, l.cacheKey)
+	mac.Write([]byte(user))
+	mac.Write([]byte{0})
+	mac.Write([]byte(pass))
+	return "ldap:" + l.name + ":" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LDAP) setContext(req *http.Request, user string, u *ldapUser) error {
+	var permissions []string
+	for _, gp := range l.groupPermissions {
+		for _, group := range u.Groups {
+			if gp.pattern.MatchString(group) {
+				for _, p := range gp.permissions {
+					permissions, _ = addPermission(permissions, p)
+				}
+				break
+			}
+		}
+	}
+	permissions = addMappedPermissions(l.permissionsMap, permissions, permissions)
+
+	groups := make([]interface{}, len(u.Groups))
+	for i, g := range u.Groups {
+		groups[i] = g
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[l.name] = map[string]interface{}{
+		"dn":     u.DN,
+		"groups": groups,
+		"user":   user,
+	}
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	if len(permissions) > 0 {
+		alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
+		ctx = context.WithValue(ctx, request.GrantedPermissions, append(alreadyGrantedPermissions, permissions...))
+	}
+
+	*req = *req.WithContext(ctx)
+	return nil
+}
@@ -0,0 +1,235 @@
+package accesscontrol_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/server"
+)
+
+var ldapEntries = []test.LDAPEntry{
+	{
+		DN:       "cn=couper,ou=services,dc=example,dc=com",
+		Password: "s3rv1ce",
+	},
+	{
+		DN:       "uid=john,ou=people,dc=example,dc=com",
+		Password: "my-pass",
+		Attributes: map[string][]string{
+			"memberOf":    {"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"},
+			"objectClass": {"person"},
+			"uid":         {"john"},
+		},
+	},
+	{
+		DN:       "uid=jane,ou=people,dc=example,dc=com",
+		Password: "her-pass",
+		Attributes: map[string][]string{
+			"memberOf":    {"cn=users,ou=groups,dc=example,dc=com"},
+			"objectClass": {"person"},
+			"uid":         {"jane"},
+		},
+	},
+}
+
+func TestLDAP_Validate(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	selfSigned, err := server.NewCertificate(time.Hour, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConf := &tls.Config{Certificates: []tls.Certificate{*selfSigned.Server}}
+
+	ldapServer := test.NewLDAPServer(tlsConf, ldapEntries...)
+	defer ldapServer.Close()
+	ldapsServer := test.NewLDAPSServer(tlsConf, ldapEntries...)
+	defer ldapsServer.Close()
+
+	groupPermissions := map[string][]string{
+		"cn=admins,ou=groups,*": {"admin"},
+		"cn=users,ou=groups,*":  {"read"},
+	}
+
+	type testCase struct {
+		name     string
+		conf     *config.LDAP
+		user     string
+		pass     string
+		expErr   string
+		expDN    string
+		expPerms []string
+	}
+
+	searchConf := func(url string) *config.LDAP {
+		return &config.LDAP{
+			BaseDN:           "ou=people,dc=example,dc=com",
+			BindDN:           "cn=couper,ou=services,dc=example,dc=com",
+			BindPassword:     "s3rv1ce",
+			CacheTTL:         "0s",
+			GroupPermissions: groupPermissions,
+			Name:             "ldap",
+			URL:              url,
+			UserFilter:       "(&(objectClass=person)(uid={username}))",
+		}
+	}
+	bindConf := &config.LDAP{
+		CacheTTL:         "0s",
+		GroupPermissions: groupPermissions,
+		Name:             "ldap",
+		URL:              ldapServer.URL(),
+		UserDN:           "uid={username},ou=people,dc=example,dc=com",
+	}
+	startTLSConf := searchConf(ldapServer.URL())
+	startTLSConf.StartTLS = true
+	startTLSConf.DisableCertificateValidation = true
+	untrustedConf := searchConf(ldapsServer.URL())
+	ldapsConf := searchConf(ldapsServer.URL())
+	ldapsConf.DisableCertificateValidation = true
+	wrongServiceConf := searchConf(ldapServer.URL())
+	wrongServiceConf.BindPassword = "wrong"
+	unreachableConf := searchConf("ldap://127.0.0.1:1")
+
+	for _, tc := range []testCase{
+		{"search-then-bind", searchConf(ldapServer.URL()), "john", "my-pass", "", "uid=john,ou=people,dc=example,dc=com", []string{"admin", "read"}},
+		{"search-then-bind: wrong password", searchConf(ldapServer.URL()), "john", "wrong", "access control error: credential mismatch", "", nil},
+		{"search-then-bind: unknown user", searchConf(ldapServer.URL()), "jim", "my-pass", "access control error: credential mismatch: user not found", "", nil},
+		{"search-then-bind: filter injection", searchConf(ldapServer.URL()), "*", "my-pass", "access control error: credential mismatch: user not found", "", nil},
+		{"search-then-bind: empty password", searchConf(ldapServer.URL()), "john", "", "access control error: credential mismatch", "", nil},
+		{"direct bind", bindConf, "jane", "her-pass", "", "uid=jane,ou=people,dc=example,dc=com", []string{"read"}},
+		{"direct bind: wrong password", bindConf, "jane", "my-pass", "access control error: credential mismatch", "", nil},
+		{"StartTLS", startTLSConf, "john", "my-pass", "", "uid=john,ou=people,dc=example,dc=com", []string{"admin", "read"}},
+		{"LDAPS", ldapsConf, "jane", "her-pass", "", "uid=jane,ou=people,dc=example,dc=com", []string{"read"}},
+		{"LDAPS: untrusted certificate", untrustedConf, "jane", "her-pass", "access control error: connection failed: LDAP Result Code 200 \"Network Error\": tls: failed to verify certificate: x509: certificate signed by unknown authority", "", nil},
+		{"service account: wrong password", wrongServiceConf, "john", "my-pass", "access control error: service account bind failed: LDAP Result Code 49 \"Invalid Credentials\": Invalid Credentials", "", nil},
+		{"unreachable", unreachableConf, "john", "my-pass", "access control error: connection failed: LDAP Result Code 200 \"Network Error\": dial tcp 127.0.0.1:1: connect: connection refused", "", nil},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			l, err := ac.NewLDAP(tc.conf, nil, nil, cache.New(logrus.NewEntry(log), quitCh))
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.SetBasicAuth(tc.user, tc.pass)
+			err = l.Validate(req)
+			if tc.expErr != "" {
+				if err == nil || err.(*errors.Error).LogError() != tc.expErr {
+					st.Errorf("expected error %q, got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err.(*errors.Error).LogError())
+			}
+
+			acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+			info := acMap["ldap"].(map[string]interface{})
+			if info["dn"] != tc.expDN || info["user"] != tc.user {
+				st.Errorf("unexpected context: %#v", info)
+			}
+			if perms := req.Context().Value(request.GrantedPermissions); !reflect.DeepEqual(perms, tc.expPerms) {
+				st.Errorf("expected permissions %v, got %v", tc.expPerms, perms)
+			}
+		})
+	}
+}
+
+func TestLDAP_ValidateCredentialsMissing(t *testing.T) {
+	l, err := ac.NewLDAP(&config.LDAP{URL: "ldap://127.0.0.1:1", UserDN: "uid={username}"}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err = l.Validate(req); !errors.Equals(err, errors.LdapCredentialsMissing) {
+		t.Errorf("expected ldap_credentials_missing error, got %v", err)
+	}
+
+	req.Header.Set("Authorization", "Basic X")
+	if err = l.Validate(req); err == nil || errors.Equals(err, errors.LdapCredentialsMissing) {
+		t.Errorf("expected ldap error, got %v", err)
+	}
+}
+
+func TestLDAP_Cache(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	ldapServer := test.NewLDAPServer(nil, ldapEntries...)
+	defer ldapServer.Close()
+
+	l, err := ac.NewLDAP(&config.LDAP{
+		Name:   "ldap",
+		URL:    ldapServer.URL(),
+		UserDN: "uid={username},ou=people,dc=example,dc=com",
+	}, nil, nil, cache.New(logrus.NewEntry(log), quitCh))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(user, pass string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, pass)
+		return l.Validate(req)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err = validate("john", "my-pass"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if binds := ldapServer.Binds(); binds != 1 {
+		t.Errorf("expected 1 bind, got %d", binds)
+	}
+
+	// failed binds and other credentials are not served from the cache
+	if err = validate("john", "wrong"); err == nil {
+		t.Error("expected credential mismatch")
+	}
+	if err = validate("john", "wrong"); err == nil {
+		t.Error("expected credential mismatch")
+	}
+	if binds := ldapServer.Binds(); binds != 3 {
+		t.Errorf("expected 3 binds, got %d", binds)
+	}
+}
+
+func TestNewLDAP_Config(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		conf   *config.LDAP
+		expErr string
+	}{
+		{"scheme", &config.LDAP{URL: "http://ldap", UserDN: "uid={username}"}, `url: scheme must be either "ldap" or "ldaps"`},
+		{"start_tls with ldaps", &config.LDAP{URL: "ldaps://ldap", UserDN: "uid={username}", StartTLS: true}, `start_tls: cannot be used with an "ldaps" url`},
+		{"missing user_dn and user_filter", &config.LDAP{URL: "ldap://ldap"}, "either user_dn or user_filter is required"},
+		{"user_dn and user_filter", &config.LDAP{URL: "ldap://ldap", UserDN: "uid={username}", UserFilter: "(uid={username})", BaseDN: "dc=example"}, "either user_dn or user_filter is required"},
+		{"missing base_dn", &config.LDAP{URL: "ldap://ldap", UserFilter: "(uid={username})"}, "base_dn is required with user_filter"},
+		{"bind_password without bind_dn", &config.LDAP{URL: "ldap://ldap", UserDN: "uid={username}", BindPassword: "s3cr3t"}, "bind_password requires bind_dn"},
+		{"cache_ttl", &config.LDAP{URL: "ldap://ldap", UserDN: "uid={username}", CacheTTL: "1x"}, `cache_ttl: time: unknown unit "x" in duration "1x"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewLDAP(tc.conf, nil, nil, nil)
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("expected error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
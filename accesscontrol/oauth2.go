@@ -15,6 +15,7 @@ var _ AccessControl = &OAuth2Callback{}
 type OAuth2Callback struct {
 	oauth2Client oauth2.AuthCodeFlowClient
 	name         string
+	session      *Session
 }
 
 // NewOAuth2Callback creates a new access control for the OAuth2 authorization code flow callback.
@@ -25,6 +26,16 @@ func NewOAuth2Callback(oauth2Client oauth2.AuthCodeFlowClient, name string) *OAu
 	}
 }
 
+// Client returns the OAuth2 client of the authorization code flow.
+func (oa *OAuth2Callback) Client() oauth2.AuthCodeFlowClient {
+	return oa.oauth2Client
+}
+
+// SetSession sets the session created after a successful authorization code flow.
+func (oa *OAuth2Callback) SetSession(session *Session) {
+	oa.session = session
+}
+
 // Validate implements the AccessControl interface
 func (oa *OAuth2Callback) Validate(req *http.Request) error {
 	if req.Method != http.MethodGet {
@@ -36,6 +47,12 @@ func (oa *OAuth2Callback) Validate(req *http.Request) error {
 		return err
 	}
 
+	if oa.session != nil {
+		if err = oa.session.Create(req, tokenResponseData); err != nil {
+			return errors.Oauth2.Message("session creation failed").With(err)
+		}
+	}
+
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
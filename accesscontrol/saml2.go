@@ -1,37 +1,54 @@
 package accesscontrol
 
 import (
+	"bytes"
+	"compress/flate"
 	"context"
+	"crypto"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"sort"
+	"strings"
 
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
 	dsig "github.com/russellhaering/goxmldsig"
 
+	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/eval/lib"
 )
 
+const samlMaxMessageSize = 5 * 1024 * 1024
+
 type Saml2 struct {
 	arrayAttributes []string
+	certStore       *dsig.MemoryX509CertificateStore
+	conf            *config.SAML
+	metadata        *types.EntityDescriptor
 	name            string
-	sp              *saml2.SAMLServiceProvider
+	sloPath         string
 }
 
-func NewSAML2ACS(metadata []byte, name string, acsURL string, spEntityID string, arrayAttributes []string) (*Saml2, error) {
+func NewSAML2ACS(conf *config.SAML) (*Saml2, error) {
 	metadataEntity := &types.EntityDescriptor{}
-	if err := xml.Unmarshal(metadata, metadataEntity); err != nil {
+	if err := xml.Unmarshal(conf.MetadataBytes, metadataEntity); err != nil {
 		return nil, err
 	}
 
-	certStore := dsig.MemoryX509CertificateStore{
+	if metadataEntity.IDPSSODescriptor == nil {
+		return nil, fmt.Errorf("missing IDPSSODescriptor in IdP metadata")
+	}
+
+	certStore := &dsig.MemoryX509CertificateStore{
 		Roots: []*x509.Certificate{},
 	}
 
@@ -54,19 +71,26 @@ func NewSAML2ACS(metadata []byte, name string, acsURL string, spEntityID string,
 		}
 	}
 
-	sp := &saml2.SAMLServiceProvider{
-		AssertionConsumerServiceURL: acsURL,
-		AudienceURI:                 spEntityID,
-		IDPCertificateStore:         &certStore,
-		IdentityProviderIssuer:      metadataEntity.EntityID,
+	var sloPath string
+	if conf.SpSloURL != "" {
+		sloURL, err := url.Parse(conf.SpSloURL)
+		if err != nil {
+			return nil, fmt.Errorf("sp_slo_url: %w", err)
+		}
+		sloPath = sloURL.Path
 	}
+
+	arrayAttributes := conf.ArrayAttributes
 	if arrayAttributes != nil {
 		sort.Strings(arrayAttributes)
 	}
 	samlObj := &Saml2{
 		arrayAttributes: arrayAttributes,
-		name:            name,
-		sp:              sp,
+		certStore:       certStore,
+		conf:            conf,
+		metadata:        metadataEntity,
+		name:            conf.Name,
+		sloPath:         sloPath,
 	}
 	return samlObj, nil
 }
@@ -82,34 +106,43 @@ func (s *Saml2) Validate(req *http.Request) error {
 		return errors.Saml.With(err)
 	}
 
-	origin := eval.NewRawOrigin(req.URL)
-	absAcsURL, err := lib.AbsoluteURL(s.sp.AssertionConsumerServiceURL, origin)
+	// The service provider depends on the origin of the current request,
+	// so a new one is created for every request.
+	sp, err := lib.NewSAMLServiceProvider(s.conf, s.metadata, eval.NewRawOrigin(req.URL))
 	if err != nil {
 		return errors.Saml.With(err)
 	}
-	s.sp.AssertionConsumerServiceURL = absAcsURL
+	sp.IDPCertificateStore = s.certStore
 
-	encodedResponse := req.FormValue("SAMLResponse")
-	req.ContentLength = 0
+	var ctxData map[string]interface{}
+	if s.sloPath != "" && req.URL.Path == s.sloPath {
+		ctxData, err = s.validateLogout(req, sp)
+		if err != nil {
+			return errors.Saml.With(err)
+		}
+	} else {
+		encodedResponse := req.FormValue("SAMLResponse")
+		req.ContentLength = 0
 
-	assertionInfo, err := s.sp.RetrieveAssertionInfo(encodedResponse)
-	if err != nil {
-		return errors.Saml.With(err)
-	}
+		assertionInfo, err := sp.RetrieveAssertionInfo(encodedResponse)
+		if err != nil {
+			return errors.Saml.With(err)
+		}
 
-	err = s.ValidateAssertionInfo(assertionInfo)
-	if err != nil {
-		return errors.Saml.With(err)
-	}
+		err = s.ValidateAssertionInfo(assertionInfo)
+		if err != nil {
+			return errors.Saml.With(err)
+		}
 
-	ass := s.GetAssertionData(assertionInfo)
+		ctxData = s.GetAssertionData(assertionInfo)
+	}
 
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
 		acMap = make(map[string]interface{})
 	}
-	acMap[s.name] = ass
+	acMap[s.name] = ctxData
 	ctx = context.WithValue(ctx, request.AccessControls, acMap)
 	*req = *req.WithContext(ctx)
 
@@ -151,6 +184,222 @@ func (s *Saml2) GetAssertionData(assertionInfo *saml2.AssertionInfo) map[string]
 	if assertionInfo.SessionNotOnOrAfter != nil {
 		ass["exp"] = assertionInfo.SessionNotOnOrAfter.Unix()
 	}
+	if assertionInfo.SessionIndex != "" {
+		ass["session_index"] = assertionInfo.SessionIndex
+	}
 
 	return ass
 }
+
+// validateLogout validates a LogoutRequest or LogoutResponse sent by the IdP to the
+// single logout endpoint, either with the HTTP-Redirect or the HTTP-POST binding.
+// Messages must be signed, either in the query (HTTP-Redirect) or in the XML document.
+func (s *Saml2) validateLogout(req *http.Request, sp *saml2.SAMLServiceProvider) (map[string]interface{}, error) {
+	param := "SAMLRequest"
+	encoded := req.FormValue(param)
+	if encoded == "" {
+		param = "SAMLResponse"
+		encoded = req.FormValue(param)
+	}
+	req.ContentLength = 0
+
+	if encoded == "" {
+		return nil, fmt.Errorf("missing SAMLRequest or SAMLResponse")
+	}
+
+	redirectBinding := req.Method == http.MethodGet
+	querySigned := false
+	if redirectBinding && req.URL.Query().Get("Signature") != "" {
+		if err := s.verifyRedirectSignature(req.URL.RawQuery, param); err != nil {
+			return nil, err
+		}
+		querySigned = true
+	}
+
+	if param == "SAMLResponse" {
+		logoutResponse, err := sp.ValidateEncodedLogoutResponsePOST(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if !querySigned && !logoutResponse.SignatureValidated {
+			return nil, fmt.Errorf("unsigned logout response")
+		}
+		return map[string]interface{}{}, nil
+	}
+
+	logoutRequest, err := sp.ValidateEncodedLogoutRequestPOST(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !querySigned && !logoutRequest.SignatureValidated {
+		return nil, fmt.Errorf("unsigned logout request")
+	}
+
+	sessionIndex, err := logoutSessionIndex(encoded, redirectBinding)
+	if err != nil {
+		return nil, err
+	}
+
+	responseURL, err := buildLogoutResponseURL(sp, logoutRequest.ID, req.FormValue("RelayState"))
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"logout_response_url": responseURL,
+	}
+	if logoutRequest.NameID != nil {
+		data["sub"] = logoutRequest.NameID.Value
+	}
+	if sessionIndex != "" {
+		data["session_index"] = sessionIndex
+	}
+
+	return data, nil
+}
+
+// verifyRedirectSignature verifies the query signature of the HTTP-Redirect binding
+// against the IdP certificates. The signed string is built from the URL-encoded
+// parameter values as received, see section 3.4.4.1 of saml-bindings-2.0-os.
+func (s *Saml2) verifyRedirectSignature(rawQuery, param string) error {
+	rawValues := make(map[string]string)
+	for _, pair := range strings.Split(rawQuery, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+		if key, err := url.QueryUnescape(k); err == nil {
+			rawValues[key] = v
+		}
+	}
+
+	signed := param + "=" + rawValues[param]
+	if relayState, ok := rawValues["RelayState"]; ok {
+		signed += "&RelayState=" + relayState
+	}
+	signed += "&SigAlg=" + rawValues["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(rawValues["SigAlg"])
+	if err != nil {
+		return err
+	}
+
+	var hash crypto.Hash
+	switch sigAlg {
+	case dsig.RSASHA1SignatureMethod:
+		hash = crypto.SHA1
+	case dsig.RSASHA256SignatureMethod:
+		hash = crypto.SHA256
+	case dsig.RSASHA512SignatureMethod:
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported SigAlg %q", sigAlg)
+	}
+
+	rawSignature, err := url.QueryUnescape(rawValues["Signature"])
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(rawSignature)
+	if err != nil {
+		return err
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	for _, cert := range s.certStore.Roots {
+		if pubKey, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			if rsa.VerifyPKCS1v15(pubKey, hash, digest, signature) == nil {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("invalid query signature")
+}
+
+// logoutSessionIndex reads the SessionIndex of a LogoutRequest, which is not
+// provided by saml2.LogoutRequest.
+func logoutSessionIndex(encoded string, deflated bool) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	if deflated {
+		r := io.LimitReader(flate.NewReader(bytes.NewReader(raw)), samlMaxMessageSize)
+		if raw, err = io.ReadAll(r); err != nil {
+			return "", err
+		}
+	}
+
+	logoutRequest := &struct {
+		SessionIndex []string `xml:"urn:oasis:names:tc:SAML:2.0:protocol SessionIndex"`
+	}{}
+	if err = xml.Unmarshal(raw, logoutRequest); err != nil {
+		return "", err
+	}
+
+	if len(logoutRequest.SessionIndex) == 0 {
+		return "", nil
+	}
+	return logoutRequest.SessionIndex[0], nil
+}
+
+// buildLogoutResponseURL creates the HTTP-Redirect binding URL of a successful
+// LogoutResponse to the given request. The query is signed if the service provider
+// has a key pair.
+func buildLogoutResponseURL(sp *saml2.SAMLServiceProvider, requestID, relayState string) (string, error) {
+	if sp.IdentityProviderSLOURL == "" {
+		return "", fmt.Errorf("no SingleLogoutService with HTTP-Redirect binding in the IdP metadata")
+	}
+
+	responseURL, err := url.Parse(sp.IdentityProviderSLOURL)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := sp.BuildLogoutResponseDocumentNoSig(saml2.StatusCodeSuccess, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	logoutResponse, err := doc.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	fw, err := flate.NewWriter(buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = fw.Write(logoutResponse); err != nil {
+		return "", err
+	}
+	if err = fw.Close(); err != nil {
+		return "", err
+	}
+
+	// The signed parameters have to be in this order.
+	params := []string{"SAMLResponse=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes()))}
+	if relayState != "" {
+		params = append(params, "RelayState="+url.QueryEscape(relayState))
+	}
+
+	if sp.SPKeyStore != nil {
+		signingContext := sp.SigningContext()
+		params = append(params, "SigAlg="+url.QueryEscape(signingContext.GetSignatureMethodIdentifier()))
+		signature, err := signingContext.SignString(strings.Join(params, "&"))
+		if err != nil {
+			return "", err
+		}
+		params = append(params, "Signature="+url.QueryEscape(base64.StdEncoding.EncodeToString(signature)))
+	}
+
+	if responseURL.RawQuery != "" {
+		params = append([]string{responseURL.RawQuery}, params...)
+	}
+	responseURL.RawQuery = strings.Join(params, "&")
+
+	return responseURL.String(), nil
+}
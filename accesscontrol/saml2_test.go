@@ -2,20 +2,30 @@ package accesscontrol_test
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/xml"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/reader"
+	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/internal/test"
 )
@@ -47,7 +57,13 @@ func Test_NewSAML2ACS(t *testing.T) {
 			continue
 		}
 
-		_, err = ac.NewSAML2ACS(metadata, "test", tc.acsURL, tc.spEntityID, tc.arrayAttributes)
+		_, err = ac.NewSAML2ACS(&config.SAML{
+			ArrayAttributes: tc.arrayAttributes,
+			MetadataBytes:   metadata,
+			Name:            "test",
+			SpAcsURL:        tc.acsURL,
+			SpEntityID:      tc.spEntityID,
+		})
 		helper.Must(err)
 	}
 }
@@ -57,7 +73,13 @@ func Test_SAML2ACS_Validate(t *testing.T) {
 	if err != nil || metadata == nil {
 		t.Fatal("Expected a metadata object")
 	}
-	sa, err := ac.NewSAML2ACS(metadata, "test", "http://www.examle.org/saml/acs", "my-sp-entity-id", []string{"memberOf"})
+	sa, err := ac.NewSAML2ACS(&config.SAML{
+		ArrayAttributes: []string{"memberOf"},
+		MetadataBytes:   metadata,
+		Name:            "test",
+		SpAcsURL:        "http://www.examle.org/saml/acs",
+		SpEntityID:      "my-sp-entity-id",
+	})
 	if err != nil || sa == nil {
 		t.Fatal("Expected a saml acs object")
 	}
@@ -104,7 +126,13 @@ func Test_SAML2ACS_ValidateAssertionInfo(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	sa, err := ac.NewSAML2ACS(metadata, "test", "http://www.examle.org/saml/acs", "my-sp-entity-id", []string{"memberOf"})
+	sa, err := ac.NewSAML2ACS(&config.SAML{
+		ArrayAttributes: []string{"memberOf"},
+		MetadataBytes:   metadata,
+		Name:            "test",
+		SpAcsURL:        "http://www.examle.org/saml/acs",
+		SpEntityID:      "my-sp-entity-id",
+	})
 	if err != nil || sa == nil {
 		t.Fatal("Expected a saml acs object")
 	}
@@ -145,7 +173,13 @@ func Test_SAML2ACS_GetAssertionData(t *testing.T) {
 	if err != nil || metadata == nil {
 		t.Fatal("Expected a metadata object")
 	}
-	sa, err := ac.NewSAML2ACS(metadata, "test", "http://www.examle.org/saml/acs", "my-sp-entity-id", []string{"memberOf"})
+	sa, err := ac.NewSAML2ACS(&config.SAML{
+		ArrayAttributes: []string{"memberOf"},
+		MetadataBytes:   metadata,
+		Name:            "test",
+		SpAcsURL:        "http://www.examle.org/saml/acs",
+		SpEntityID:      "my-sp-entity-id",
+	})
 	if err != nil || sa == nil {
 		t.Fatal("Expected a saml acs object")
 	}
@@ -317,3 +351,170 @@ func Test_SAML2ACS_GetAssertionData(t *testing.T) {
 		})
 	}
 }
+
+func Test_SAML2ACS_ValidateLogout(t *testing.T) {
+	helper := test.New(t)
+
+	idpKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	idpCertDER, err := x509.CreateCertificate(rand.Reader, template, template, &idpKey.PublicKey, idpKey)
+	helper.Must(err)
+
+	metadata := []byte(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="https://idp.example.org/saml">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo>
+        <ds:X509Data>
+          <ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idpCertDER) + `</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleLogoutService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.org/saml/slo"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.org/saml/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`)
+
+	spKeyPair, err := tls.LoadX509KeyPair("testdata/saml-sp.crt", "testdata/saml-sp.key")
+	helper.Must(err)
+
+	sa, err := ac.NewSAML2ACS(&config.SAML{
+		MetadataBytes: metadata,
+		Name:          "test",
+		SpAcsURL:      "/saml/acs",
+		SpEntityID:    "my-sp-entity-id",
+		SpKeyPair:     &spKeyPair,
+		SpSloURL:      "/saml/slo",
+	})
+	helper.Must(err)
+
+	// the IdP sends messages to the SP, so it is modelled as a service provider with swapped roles
+	idpTLSCert := tls.Certificate{Certificate: [][]byte{idpCertDER}, PrivateKey: idpKey}
+	newIdP := func(signed bool) *saml2.SAMLServiceProvider {
+		idp := &saml2.SAMLServiceProvider{
+			IdentityProviderSLOURL: "https://www.example.com/saml/slo",
+			ServiceProviderIssuer:  "https://idp.example.org/saml",
+			SignAuthnRequests:      signed,
+		}
+		if signed {
+			idp.SPKeyStore = dsig.TLSCertKeyStore(idpTLSCert)
+		}
+		return idp
+	}
+
+	signedRedirectURL := func(subT *testing.T) string {
+		idp := newIdP(true)
+		doc, err := idp.BuildLogoutRequestDocumentNoSig("abc12345", "_session-1")
+		if err != nil {
+			subT.Fatal(err)
+		}
+		u, err := idp.BuildLogoutURLRedirect("state", doc)
+		if err != nil {
+			subT.Fatal(err)
+		}
+		return u
+	}
+
+	t.Run("signed logout request, redirect binding", func(subT *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, signedRedirectURL(subT), nil)
+		if err := sa.Validate(req); err != nil {
+			subT.Fatal(err)
+		}
+
+		acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+		data := acMap["test"].(map[string]interface{})
+		if data["sub"] != "abc12345" || data["session_index"] != "_session-1" {
+			subT.Errorf("unexpected context: %#v", data)
+		}
+
+		responseURL, err := url.Parse(data["logout_response_url"].(string))
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if responseURL.Host != "idp.example.org" || responseURL.Path != "/saml/slo" {
+			subT.Errorf("unexpected logout_response_url: %q", responseURL.String())
+		}
+
+		q := responseURL.Query()
+		if q.Get("RelayState") != "state" || q.Get("SigAlg") == "" || q.Get("Signature") == "" {
+			subT.Errorf("expected signed logout response with RelayState, got: %q", responseURL.RawQuery)
+		}
+
+		signature, err := base64.StdEncoding.DecodeString(q.Get("Signature"))
+		if err != nil {
+			subT.Fatal(err)
+		}
+		signed := responseURL.RawQuery[:strings.Index(responseURL.RawQuery, "&Signature=")]
+		spCert, err := x509.ParseCertificate(spKeyPair.Certificate[0])
+		if err != nil {
+			subT.Fatal(err)
+		}
+		if err = spCert.CheckSignature(x509.SHA256WithRSA, []byte(signed), signature); err != nil {
+			subT.Errorf("invalid logout response signature: %v", err)
+		}
+	})
+
+	t.Run("tampered logout request, redirect binding", func(subT *testing.T) {
+		u := strings.Replace(signedRedirectURL(subT), "RelayState=state", "RelayState=other", 1)
+		req := httptest.NewRequest(http.MethodGet, u, nil)
+		err := sa.Validate(req)
+		if err == nil || !strings.Contains(err.(errors.GoError).LogError(), "invalid query signature") {
+			subT.Errorf("expected invalid query signature, got: %v", err)
+		}
+	})
+
+	t.Run("unsigned logout request, redirect binding", func(subT *testing.T) {
+		u, _ := url.Parse(signedRedirectURL(subT))
+		q := u.Query()
+		q.Del("Signature")
+		q.Del("SigAlg")
+		u.RawQuery = q.Encode()
+		req := httptest.NewRequest(http.MethodGet, u.String(), nil)
+		err := sa.Validate(req)
+		if err == nil || !strings.Contains(err.(errors.GoError).LogError(), "unsigned logout request") {
+			subT.Errorf("expected unsigned logout request, got: %v", err)
+		}
+	})
+
+	t.Run("signed logout response, post binding", func(subT *testing.T) {
+		doc, err := newIdP(true).BuildLogoutResponseDocument(saml2.StatusCodeSuccess, "_request-1")
+		if err != nil {
+			subT.Fatal(err)
+		}
+		b, err := doc.WriteToBytes()
+		if err != nil {
+			subT.Fatal(err)
+		}
+
+		form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(b)}}
+		req := httptest.NewRequest(http.MethodPost, "https://www.example.com/saml/slo", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err = sa.Validate(req); err != nil {
+			subT.Fatal(err)
+		}
+	})
+
+	t.Run("unsigned logout response, post binding", func(subT *testing.T) {
+		doc, err := newIdP(false).BuildLogoutResponseDocumentNoSig(saml2.StatusCodeSuccess, "_request-1")
+		if err != nil {
+			subT.Fatal(err)
+		}
+		b, err := doc.WriteToBytes()
+		if err != nil {
+			subT.Fatal(err)
+		}
+
+		form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(b)}}
+		req := httptest.NewRequest(http.MethodPost, "https://www.example.com/saml/slo", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		err = sa.Validate(req)
+		if err == nil || !strings.Contains(err.(errors.GoError).LogError(), "unsigned logout response") {
+			subT.Errorf("expected unsigned logout response, got: %v", err)
+		}
+	})
+}
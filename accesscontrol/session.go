@@ -0,0 +1,539 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coupergateway/couper/accesscontrol/jwe"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/oauth2"
+	"github.com/coupergateway/couper/server/writer"
+)
+
+var _ AccessControl = &Session{}
+
+const (
+	// sessionRefreshLeeway refreshes access tokens shortly before they expire.
+	sessionRefreshLeeway = 30 * time.Second
+	// sessionCookieMaxSize is the maximum size of a cookie value most browsers accept.
+	sessionCookieMaxSize = 4000
+)
+
+// sessionData represents the data stored for a session.
+type sessionData struct {
+	// CreatedAt is the creation time of the session in milliseconds.
+	CreatedAt int64 `json:"created_at"`
+	// Data is the token response without the refresh token.
+	Data map[string]interface{} `json:"data"`
+	// ExpiresAt is the expiry of the access token, if known.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+	// RefreshToken is the current refresh token, if any.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// SessionExpiresAt is the end of the session lifetime.
+	SessionExpiresAt int64 `json:"session_expires_at"`
+}
+
+// update takes over the (new) tokens from the given token response.
+func (d *sessionData) update(tokenResponseData map[string]interface{}, now time.Time) error {
+	if _, ok := tokenResponseData["access_token"].(string); !ok {
+		return fmt.Errorf("missing access token")
+	}
+
+	if d.Data == nil {
+		d.Data = make(map[string]interface{})
+	}
+	for k, v := range tokenResponseData {
+		switch k {
+		case "refresh_token":
+			d.RefreshToken, _ = v.(string)
+		case "id_token", "id_token_claims", "userinfo":
+			// keep the validated ones from the authorization code flow
+			if _, exists := d.Data[k]; !exists {
+				d.Data[k] = v
+			}
+		default:
+			d.Data[k] = v
+		}
+	}
+
+	d.ExpiresAt = 0
+	if expiresIn, ok := tokenResponseData["expires_in"].(float64); ok {
+		d.ExpiresAt = now.Unix() + int64(expiresIn)
+	} else {
+		delete(d.Data, "expires_in")
+	}
+
+	return nil
+}
+
+// needsRefresh reports whether the access token is (about to be) expired.
+func (d *sessionData) needsRefresh(now time.Time) bool {
+	return d.ExpiresAt > 0 && now.Add(sessionRefreshLeeway).Unix() >= d.ExpiresAt
+}
+
+// contextData returns the session data for the request context.
+func (d *sessionData) contextData() map[string]interface{} {
+	contextData := make(map[string]interface{}, len(d.Data)+1)
+	for k, v := range d.Data {
+		contextData[k] = v
+	}
+	if d.ExpiresAt > 0 {
+		contextData["expires_at"] = d.ExpiresAt
+	}
+	return contextData
+}
+
+// Session represents an AC-Session object for login sessions created by an authorization code flow.
+type Session struct {
+	backchannelLogoutPath string
+	cookieName            string
+	decrypter             *jwe.Decrypter
+	encrypter             *jwe.Encrypter
+	forwardAccessToken    bool
+	logoutPath            string
+	logoutTokenValidator  oauth2.LogoutTokenValidator
+	memStore              cache.Store
+	name                  string
+	oauth2Client          oauth2.AuthCodeFlowClient
+	refreshLocks          *keyLocks
+	secureCookie          bool
+	ttl                   time.Duration
+}
+
+// keyLocks provides a mutex per key, e.g. to serialize the refreshes of a session.
+type keyLocks struct {
+	locks map[string]*keyLock
+	mu    sync.Mutex
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of the given key and returns the related unlock function.
+func (k *keyLocks) lock(key string) func() {
+	k.mu.Lock()
+	l, exist := k.locks[key]
+	if !exist {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		k.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// NewSession creates a new AC-Session object. The encryption key is
+// required for sessions stored in the session cookie.
+func NewSession(conf *config.Session, encryptionKey []byte, oauth2Client oauth2.AuthCodeFlowClient, memStore cache.Store) (*Session, error) {
+	ttl, err := config.ParseDuration("ttl", conf.TTL, 8*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if ttl < time.Second {
+		return nil, fmt.Errorf("ttl: must be at least one second")
+	}
+
+	cookieName := conf.Cookie
+	if cookieName == "" {
+		cookieName = "couper_session"
+	}
+
+	s := &Session{
+		backchannelLogoutPath: conf.BackchannelLogoutPath,
+		cookieName:            cookieName,
+		forwardAccessToken:    !conf.DisableAccessTokenForwarding,
+		logoutPath:            conf.LogoutPath,
+		memStore:              memStore,
+		name:                  conf.Name,
+		oauth2Client:          oauth2Client,
+		refreshLocks:          &keyLocks{locks: make(map[string]*keyLock)},
+		secureCookie:          !conf.DisableSecureCookie,
+		ttl:                   ttl,
+	}
+
+	if s.backchannelLogoutPath != "" {
+		validator, ok := oauth2Client.(oauth2.LogoutTokenValidator)
+		if !ok {
+			return nil, fmt.Errorf("backchannel_logout_path requires an oidc block referenced by oauth2")
+		}
+		s.logoutTokenValidator = validator
+	}
+
+	switch conf.Store {
+	case "", config.SessionStoreCache:
+		if len(encryptionKey) > 0 {
+			return nil, fmt.Errorf("encryption_key can only be used with store = %q", config.SessionStoreCookie)
+		}
+	case config.SessionStoreCookie:
+		if len(encryptionKey) == 0 {
+			return nil, fmt.Errorf("encryption_key or encryption_key_file required with store = %q", config.SessionStoreCookie)
+		}
+		if s.encrypter, err = jwe.NewEncrypter(jwe.AlgorithmDirect, "A256GCM", encryptionKey); err != nil {
+			return nil, fmt.Errorf("encryption_key: %w", err)
+		}
+		if s.decrypter, err = jwe.NewDecrypter(encryptionKey); err != nil {
+			return nil, fmt.Errorf("encryption_key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("store: must be either %q or %q", config.SessionStoreCache, config.SessionStoreCookie)
+	}
+
+	return s, nil
+}
+
+// Create starts a new session with the given token response and sets the session cookie.
+func (s *Session) Create(req *http.Request, tokenResponseData map[string]interface{}) error {
+	now := time.Now()
+	data := &sessionData{CreatedAt: now.UnixMilli(), SessionExpiresAt: now.Add(s.ttl).Unix()}
+	if err := data.update(tokenResponseData, now); err != nil {
+		return err
+	}
+
+	value, err := s.save("", data, now)
+	if err != nil {
+		return err
+	}
+
+	s.setCookie(req, value, int(s.ttl.Seconds()))
+	return nil
+}
+
+// Validate implements the AccessControl interface
+func (s *Session) Validate(req *http.Request) error {
+	if s.backchannelLogoutPath != "" && req.URL.Path == s.backchannelLogoutPath {
+		return s.backChannelLogout(req)
+	}
+
+	logout := s.logoutPath != "" && req.URL.Path == s.logoutPath
+	// Cross-site requests cannot end the session: in contrast to top-level
+	// navigations, POST requests are sent without the SameSite=Lax cookie.
+	if logout && req.Method != http.MethodPost {
+		return errors.Session.Messagef("wrong logout method (%s)", req.Method)
+	}
+
+	cookie, err := req.Cookie(s.cookieName)
+	if err != nil || cookie.Value == "" {
+		if logout {
+			return nil
+		}
+		return errors.SessionMissing.Message("session cookie missing")
+	}
+
+	now := time.Now()
+	data, err := s.load(cookie.Value, now)
+	if err == nil && s.loggedOut(data) {
+		s.delete(cookie.Value)
+		err = fmt.Errorf("session ended by back-channel logout")
+	}
+	if err != nil {
+		s.setCookie(req, "", -1)
+		if logout {
+			return nil
+		}
+		return errors.Session.With(err)
+	}
+
+	if logout {
+		s.delete(cookie.Value)
+		s.setCookie(req, "", -1)
+		s.setContext(req, data.contextData())
+		return nil
+	}
+
+	if data.needsRefresh(now) {
+		if data, err = s.refresh(req, cookie.Value, data, now); err != nil {
+			s.delete(cookie.Value)
+			s.setCookie(req, "", -1)
+			return err
+		}
+	}
+
+	s.setContext(req, data.contextData())
+
+	if s.forwardAccessToken {
+		accessToken, _ := data.Data["access_token"].(string)
+		*req = *req.WithContext(context.WithValue(req.Context(), request.SessionAccessToken, accessToken))
+	}
+
+	return nil
+}
+
+// backChannelLogout ends the sessions referenced by the logout token of a back-channel
+// logout request, see OpenID Connect Back-Channel Logout 1.0, section 2.8.
+func (s *Session) backChannelLogout(req *http.Request) error {
+	if req.Method != http.MethodPost {
+		return errors.SessionLogoutTokenInvalid.Messagef("wrong method (%s)", req.Method)
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return errors.SessionLogoutTokenInvalid.With(err)
+	}
+
+	logoutToken := req.PostFormValue("logout_token")
+	if logoutToken == "" {
+		return errors.SessionLogoutTokenInvalid.Message("missing logout_token")
+	}
+
+	claims, err := s.logoutTokenValidator.ValidateLogoutToken(logoutToken)
+	if err != nil {
+		return errors.SessionLogoutTokenInvalid.With(err)
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return errors.SessionLogoutTokenInvalid.Message("missing jti claim in logout token")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.SessionLogoutTokenInvalid.Message("missing exp claim in logout token")
+	}
+
+	// The jti is remembered at least until the logout token expires.
+	ttl := int64(s.ttl.Seconds())
+	jtiTTL := ttl
+	if untilExp := int64(exp) - time.Now().Unix() + 1; untilExp > jtiTTL {
+		jtiTTL = untilExp
+	}
+	if !s.memStore.SetIfAbsent(s.key("logout-jti:"+jti), "1", jtiTTL) {
+		return errors.SessionLogoutTokenInvalid.Message("logout token replayed")
+	}
+
+	// A sid claim logs out a single session, a sub claim all sessions of the user.
+	claim := "sid"
+	value, _ := claims["sid"].(string)
+	if value == "" {
+		claim = "sub"
+		value, _ = claims["sub"].(string)
+	}
+	s.memStore.Set(s.logoutKey(claim, value), strconv.FormatInt(time.Now().UnixMilli(), 10), ttl)
+
+	if rw, ok := req.Context().Value(request.ResponseWriter).(*writer.Response); ok {
+		rw.AddHeaderModifier(func(header http.Header) {
+			header.Set("Cache-Control", "no-store")
+		})
+	}
+
+	s.setContext(req, claims)
+	return nil
+}
+
+// loggedOut checks whether a back-channel logout for the session's sid or sub claim
+// has been received after the session was created.
+func (s *Session) loggedOut(data *sessionData) bool {
+	if s.logoutTokenValidator == nil {
+		return false
+	}
+
+	claims, _ := data.Data["id_token_claims"].(map[string]interface{})
+	for _, claim := range []string{"sid", "sub"} {
+		value, _ := claims[claim].(string)
+		if value == "" {
+			continue
+		}
+
+		loggedOutAt, _ := s.memStore.Get(s.logoutKey(claim, value)).(string)
+		if at, err := strconv.ParseInt(loggedOutAt, 10, 64); err == nil && at >= data.CreatedAt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refresh requests a new access token with the refresh token of the session. Refreshes of
+// a session are serialized, so concurrent requests take over the session refreshed by the
+// first one instead of using a (rotated) refresh token twice.
+func (s *Session) refresh(req *http.Request, value string, data *sessionData, now time.Time) (*sessionData, error) {
+	unlock := s.refreshLocks.lock(s.key(value))
+	defer unlock()
+
+	if refreshed, newValue := s.refreshed(value, now); refreshed != nil {
+		if newValue != value {
+			s.setCookie(req, newValue, int(refreshed.SessionExpiresAt-now.Unix()))
+		}
+		return refreshed, nil
+	}
+
+	if data.RefreshToken == "" {
+		return nil, errors.Session.Message("access token expired")
+	}
+
+	tokenResponseData, err := s.oauth2Client.RefreshTokenResponse(req.Context(), data.RefreshToken)
+	if err != nil {
+		return nil, errors.Session.Message("access token refresh failed").With(err)
+	}
+
+	if err = data.update(tokenResponseData, now); err != nil {
+		return nil, errors.Session.Message("access token refresh failed").With(err)
+	}
+
+	newValue, err := s.save(value, data, now)
+	if err != nil {
+		return nil, errors.Session.With(err)
+	}
+
+	if newValue != value {
+		// requests with the former session cookie take over the new one
+		s.memStore.Set(s.refreshedKey(value), newValue, int64(sessionRefreshLeeway.Seconds()))
+		s.setCookie(req, newValue, int(data.SessionExpiresAt-now.Unix()))
+	}
+
+	return data, nil
+}
+
+// refreshed returns the session data and the session cookie value if the session
+// has already been refreshed by a concurrent request.
+func (s *Session) refreshed(value string, now time.Time) (*sessionData, string) {
+	newValue := value
+	if s.decrypter != nil {
+		v, ok := s.memStore.Get(s.refreshedKey(value)).(string)
+		if !ok {
+			return nil, ""
+		}
+		newValue = v
+	}
+
+	data, err := s.load(newValue, now)
+	if err != nil || data.needsRefresh(now) {
+		return nil, ""
+	}
+	return data, newValue
+}
+
+// save stores the session data and returns the session cookie value. With the
+// cache store, the given cookie value is used as session ID, a new one is created if empty.
+func (s *Session) save(value string, data *sessionData, now time.Time) (string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	if s.encrypter != nil {
+		value, err = s.encrypter.Encrypt(b, nil)
+		if err != nil {
+			return "", err
+		}
+		if len(value) > sessionCookieMaxSize {
+			return "", fmt.Errorf("session data exceeds the maximum cookie size")
+		}
+		return value, nil
+	}
+
+	if value == "" {
+		id := make([]byte, 32)
+		if _, err = rand.Read(id); err != nil {
+			return "", err
+		}
+		value = base64.RawURLEncoding.EncodeToString(id)
+	}
+
+	s.memStore.Set(s.key(value), string(b), data.SessionExpiresAt-now.Unix())
+	return value, nil
+}
+
+// load returns the session data for the given session cookie value.
+func (s *Session) load(value string, now time.Time) (*sessionData, error) {
+	var b []byte
+	if s.decrypter != nil {
+		plaintext, _, err := s.decrypter.Decrypt(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session cookie: %w", err)
+		}
+		b = plaintext
+	} else {
+		stored, ok := s.memStore.Get(s.key(value)).(string)
+		if !ok {
+			return nil, fmt.Errorf("unknown session")
+		}
+		b = []byte(stored)
+	}
+
+	data := &sessionData{}
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, fmt.Errorf("invalid session data: %w", err)
+	}
+
+	if data.SessionExpiresAt <= now.Unix() {
+		return nil, fmt.Errorf("session expired")
+	}
+
+	return data, nil
+}
+
+// delete removes the session from the cache store. Sessions stored
+// in the session cookie end with clearing the cookie.
+func (s *Session) delete(value string) {
+	if s.decrypter == nil {
+		s.memStore.Del(s.key(value))
+	}
+}
+
+func (s *Session) key(id string) string {
+	hash := sha256.Sum256([]byte(id))
+	return "session:" + s.name + ":" + hex.EncodeToString(hash[:])
+}
+
+func (s *Session) refreshedKey(value string) string {
+	return s.key("refreshed:" + value)
+}
+
+func (s *Session) logoutKey(claim, value string) string {
+	return s.key("logout:" + claim + ":" + value)
+}
+
+func (s *Session) setContext(req *http.Request, contextData map[string]interface{}) {
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[s.name] = contextData
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+}
+
+// setCookie adds the session cookie to the client response. A negative
+// maxAge clears the cookie.
+func (s *Session) setCookie(req *http.Request, value string, maxAge int) {
+	rw, ok := req.Context().Value(request.ResponseWriter).(*writer.Response)
+	if !ok {
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     s.cookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   s.secureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	rw.AddHeaderModifier(func(header http.Header) {
+		header.Add("Set-Cookie", cookie.String())
+	})
+}
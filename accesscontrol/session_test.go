@@ -0,0 +1,362 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/server/writer"
+)
+
+type mockAuthCodeFlowClient struct {
+	mu              sync.Mutex
+	refreshErr      error
+	refreshResponse map[string]interface{}
+	refreshTokens   []string
+	rotate          bool // rejects refresh tokens which have already been used
+}
+
+func (m *mockAuthCodeFlowClient) ExchangeCodeAndGetTokenResponse(_ *http.Request, _ *url.URL) (map[string]interface{}, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockAuthCodeFlowClient) RefreshTokenResponse(_ context.Context, refreshToken string) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rotate {
+		for _, used := range m.refreshTokens {
+			if used == refreshToken {
+				return nil, fmt.Errorf("error=invalid_grant")
+			}
+		}
+	}
+	m.refreshTokens = append(m.refreshTokens, refreshToken)
+	return m.refreshResponse, m.refreshErr
+}
+
+// sessionRequest creates a request with a response writer to capture the session cookie.
+func sessionRequest(cookie *http.Cookie) (*http.Request, *writer.Response, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	rw := writer.NewResponseWriter(rec, "")
+	*req = *req.WithContext(context.WithValue(req.Context(), request.ResponseWriter, rw))
+	return req, rw, rec
+}
+
+func sessionCookie(rw *writer.Response, rec *httptest.ResponseRecorder) *http.Cookie {
+	rw.WriteHeader(http.StatusOK)
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "couper_session" {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestSession_Validate(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	for _, store := range []string{config.SessionStoreCache, config.SessionStoreCookie} {
+		t.Run(store, func(st *testing.T) {
+			conf := &config.Session{Name: "sess", Store: store, LogoutPath: "/logout"}
+			var key []byte
+			if store == config.SessionStoreCookie {
+				key = []byte("0123456789abcdef0123456789abcdef")
+			}
+
+			client := &mockAuthCodeFlowClient{}
+			session, err := ac.NewSession(conf, key, client, cache.New(logrus.NewEntry(log), quitCh))
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			// create
+			req, rw, rec := sessionRequest(nil)
+			err = session.Create(req, map[string]interface{}{
+				"access_token":    "token1",
+				"expires_in":      float64(3600),
+				"id_token":        "id.token",
+				"id_token_claims": map[string]interface{}{"sub": "me"},
+				"refresh_token":   "refresh1",
+			})
+			if err != nil {
+				st.Fatal(err)
+			}
+			cookie := sessionCookie(rw, rec)
+			if cookie == nil || cookie.Value == "" || !cookie.HttpOnly || !cookie.Secure || cookie.MaxAge != 8*3600 {
+				st.Fatalf("unexpected session cookie: %#v", cookie)
+			}
+
+			// validate
+			req, _, _ = sessionRequest(cookie)
+			if err = session.Validate(req); err != nil {
+				st.Fatal(err)
+			}
+			if token := req.Context().Value(request.SessionAccessToken); token != "token1" {
+				st.Errorf("expected access token to forward, got %q", token)
+			}
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			sessionData, _ := acMap["sess"].(map[string]interface{})
+			if sessionData["id_token"] != "id.token" || sessionData["access_token"] != "token1" {
+				st.Errorf("unexpected session context: %#v", sessionData)
+			}
+			if _, exists := sessionData["refresh_token"]; exists {
+				st.Error("expected no refresh token in session context")
+			}
+			if len(client.refreshTokens) != 0 {
+				st.Errorf("expected no refresh, got %d", len(client.refreshTokens))
+			}
+
+			// missing cookie
+			req, _, _ = sessionRequest(nil)
+			if err = session.Validate(req); !errors.Equals(err, errors.SessionMissing) {
+				st.Errorf("expected session_missing error, got %v", err)
+			}
+
+			// invalid cookie
+			req, _, _ = sessionRequest(&http.Cookie{Name: "couper_session", Value: "invalid"})
+			if err = session.Validate(req); !errors.Equals(err, errors.Session) {
+				st.Errorf("expected session error, got %v", err)
+			}
+
+			// logout with a method other than POST
+			req, _, _ = sessionRequest(cookie)
+			req.URL.Path = "/logout"
+			if err = session.Validate(req); err == nil || err.(*errors.Error).LogError() != "access control error: wrong logout method (GET)" {
+				st.Errorf("expected wrong logout method error, got %v", err)
+			}
+			req, _, _ = sessionRequest(cookie)
+			if err = session.Validate(req); err != nil {
+				st.Errorf("expected session to be valid, got %v", err)
+			}
+
+			// logout
+			req, rw, rec = sessionRequest(cookie)
+			req.Method = http.MethodPost
+			req.URL.Path = "/logout"
+			if err = session.Validate(req); err != nil {
+				st.Fatal(err)
+			}
+			if cleared := sessionCookie(rw, rec); cleared == nil || cleared.MaxAge >= 0 {
+				st.Errorf("expected cleared session cookie, got %#v", cleared)
+			}
+			if store == config.SessionStoreCache {
+				req, _, _ = sessionRequest(cookie)
+				if err = session.Validate(req); err == nil || err.(*errors.Error).LogError() != "access control error: unknown session" {
+					st.Errorf("expected unknown session error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSession_DisableSecureCookie(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	conf := &config.Session{Name: "sess", DisableSecureCookie: true}
+	session, err := ac.NewSession(conf, nil, &mockAuthCodeFlowClient{}, cache.New(logrus.NewEntry(log), quitCh))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, rw, rec := sessionRequest(nil)
+	if err = session.Create(req, map[string]interface{}{"access_token": "token1"}); err != nil {
+		t.Fatal(err)
+	}
+	if cookie := sessionCookie(rw, rec); cookie == nil || cookie.Secure || !cookie.HttpOnly {
+		t.Errorf("expected session cookie without Secure flag, got %#v", cookie)
+	}
+}
+
+func TestSession_Refresh(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	memStore := cache.New(logrus.NewEntry(log), quitCh)
+
+	client := &mockAuthCodeFlowClient{
+		refreshResponse: map[string]interface{}{
+			"access_token":  "token2",
+			"expires_in":    float64(3600),
+			"id_token":      "new.id.token",
+			"refresh_token": "refresh2",
+		},
+	}
+	session, err := ac.NewSession(&config.Session{Name: "sess"}, nil, client, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, rw, rec := sessionRequest(nil)
+	err = session.Create(req, map[string]interface{}{
+		"access_token":  "token1",
+		"expires_in":    float64(10), // within the refresh leeway
+		"id_token":      "id.token",
+		"refresh_token": "refresh1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := sessionCookie(rw, rec)
+
+	req, _, _ = sessionRequest(cookie)
+	if err = session.Validate(req); err != nil {
+		t.Fatal(err)
+	}
+	if token := req.Context().Value(request.SessionAccessToken); token != "token2" {
+		t.Errorf("expected refreshed access token to forward, got %q", token)
+	}
+	acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+	if idToken := acMap["sess"].(map[string]interface{})["id_token"]; idToken != "id.token" {
+		t.Errorf("expected id token of the login, got %q", idToken)
+	}
+
+	// the refreshed access token is valid for an hour
+	req, _, _ = sessionRequest(cookie)
+	if err = session.Validate(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.refreshTokens) != 1 || client.refreshTokens[0] != "refresh1" {
+		t.Errorf("expected one refresh with refresh1, got %v", client.refreshTokens)
+	}
+
+	// failed refresh ends the session
+	client.refreshErr = fmt.Errorf("error=invalid_grant")
+	session, err = ac.NewSession(&config.Session{Name: "sess2"}, nil, client, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, rw, rec = sessionRequest(nil)
+	if err = session.Create(req, map[string]interface{}{"access_token": "token1", "expires_in": float64(1), "refresh_token": "refresh1"}); err != nil {
+		t.Fatal(err)
+	}
+	cookie = sessionCookie(rw, rec)
+
+	req, rw, rec = sessionRequest(cookie)
+	if err = session.Validate(req); err == nil || err.(*errors.Error).LogError() != "access control error: access token refresh failed: error=invalid_grant" {
+		t.Errorf("expected refresh error, got %v", err)
+	}
+	if cleared := sessionCookie(rw, rec); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("expected cleared session cookie, got %#v", cleared)
+	}
+}
+
+func TestSession_ConcurrentRefresh(t *testing.T) {
+	log, _ := logrustest.NewNullLogger()
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	for _, store := range []string{config.SessionStoreCache, config.SessionStoreCookie} {
+		t.Run(store, func(st *testing.T) {
+			var key []byte
+			if store == config.SessionStoreCookie {
+				key = []byte("0123456789abcdef0123456789abcdef")
+			}
+
+			client := &mockAuthCodeFlowClient{
+				refreshResponse: map[string]interface{}{
+					"access_token":  "token2",
+					"expires_in":    float64(3600),
+					"refresh_token": "refresh2",
+				},
+				rotate: true,
+			}
+			session, err := ac.NewSession(&config.Session{Name: "sess", Store: store}, key, client, cache.New(logrus.NewEntry(log), quitCh))
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req, rw, rec := sessionRequest(nil)
+			err = session.Create(req, map[string]interface{}{
+				"access_token":  "token1",
+				"expires_in":    float64(10), // within the refresh leeway
+				"refresh_token": "refresh1",
+			})
+			if err != nil {
+				st.Fatal(err)
+			}
+			cookie := sessionCookie(rw, rec)
+
+			const parallel = 5
+			errs := make(chan error, parallel)
+			tokens := make(chan interface{}, parallel)
+			wg := sync.WaitGroup{}
+			for i := 0; i < parallel; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					r, _, _ := sessionRequest(cookie)
+					errs <- session.Validate(r)
+					tokens <- r.Context().Value(request.SessionAccessToken)
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			close(tokens)
+
+			for err = range errs {
+				if err != nil {
+					st.Errorf("expected no error, got %v", err)
+				}
+			}
+			for token := range tokens {
+				if token != "token2" {
+					st.Errorf("expected refreshed access token, got %q", token)
+				}
+			}
+
+			// a later request with the former session cookie takes over the refreshed session
+			req, _, _ = sessionRequest(cookie)
+			if err = session.Validate(req); err != nil {
+				st.Fatal(err)
+			}
+
+			if len(client.refreshTokens) != 1 {
+				st.Errorf("expected one refresh, got %v", client.refreshTokens)
+			}
+		})
+	}
+}
+
+func TestNewSession_Config(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, tc := range []struct {
+		name   string
+		conf   *config.Session
+		key    []byte
+		expErr string
+	}{
+		{"cookie without key", &config.Session{Store: "cookie"}, nil, `encryption_key or encryption_key_file required with store = "cookie"`},
+		{"cookie with short key", &config.Session{Store: "cookie"}, []byte("short"), "encryption_key: key for A256GCM must have a size of 32 bytes"},
+		{"cache with key", &config.Session{}, key, `encryption_key can only be used with store = "cookie"`},
+		{"unknown store", &config.Session{Store: "db"}, nil, `store: must be either "cache" or "cookie"`},
+		{"invalid ttl", &config.Session{TTL: "1ms"}, nil, "ttl: must be at least one second"},
+		{"back-channel logout without oidc", &config.Session{BackchannelLogoutPath: "/bcl"}, nil, "backchannel_logout_path requires an oidc block referenced by oauth2"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewSession(tc.conf, tc.key, &mockAuthCodeFlowClient{}, nil)
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("expected error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
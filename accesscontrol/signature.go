@@ -0,0 +1,218 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/buffer"
+)
+
+var _ AccessControl = &Signature{}
+
+// signatureBodyLimit limits reading request bodies which have not been buffered
+// by an endpoint, like the default {request_body_limit} of endpoints.
+var signatureBodyLimit = int64(64 << 20)
+
+const (
+	signaturePlaceholderBody      = "{body}"
+	signaturePlaceholderMethod    = "{method}"
+	signaturePlaceholderPath      = "{path}"
+	signaturePlaceholderQuery     = "{query}"
+	signaturePlaceholderTimestamp = "{timestamp}"
+)
+
+// Signature represents an AC-Signature object which verifies an HMAC over
+// the request body and other request properties, e.g. of inbound webhooks.
+type Signature struct {
+	decode             func(string) ([]byte, error)
+	hashFunc           func() hash.Hash
+	header             string
+	key                []byte
+	name               string
+	prefix             string
+	stringToSign       string
+	timestampHeader    string
+	timestampTolerance time.Duration
+}
+
+// NewSignature creates a new AC-Signature object.
+func NewSignature(conf *config.Signature, key []byte) (*Signature, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key: must not be empty")
+	}
+
+	if conf.Header == "" {
+		return nil, fmt.Errorf("header: must not be empty")
+	}
+
+	var hashFunc func() hash.Hash
+	switch conf.Algorithm {
+	case "sha1":
+		hashFunc = sha1.New
+	case "", "sha256":
+		hashFunc = sha256.New
+	case "sha512":
+		hashFunc = sha512.New
+	default:
+		return nil, fmt.Errorf("algorithm: unsupported value %q", conf.Algorithm)
+	}
+
+	var decode func(string) ([]byte, error)
+	switch conf.Encoding {
+	case "", "hex":
+		decode = hex.DecodeString
+	case "base64":
+		decode = base64.StdEncoding.DecodeString
+	case "base64url":
+		decode = func(s string) ([]byte, error) {
+			return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+		}
+	default:
+		return nil, fmt.Errorf("encoding: unsupported value %q", conf.Encoding)
+	}
+
+	stringToSign := conf.StringToSign
+	if stringToSign == "" {
+		stringToSign = signaturePlaceholderBody
+	}
+	if strings.Contains(stringToSign, signaturePlaceholderTimestamp) && conf.TimestampHeader == "" {
+		return nil, fmt.Errorf("string_to_sign: %s requires timestamp_header", signaturePlaceholderTimestamp)
+	}
+	// An unsigned timestamp could be replaced to replay old signatures.
+	if conf.TimestampHeader != "" && !strings.Contains(stringToSign, signaturePlaceholderTimestamp) {
+		return nil, fmt.Errorf("timestamp_header: requires %s in string_to_sign", signaturePlaceholderTimestamp)
+	}
+
+	timestampTolerance, err := config.ParseDuration("timestamp_tolerance", conf.TimestampTolerance, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{
+		decode:             decode,
+		hashFunc:           hashFunc,
+		header:             conf.Header,
+		key:                key,
+		name:               conf.Name,
+		prefix:             conf.Prefix,
+		stringToSign:       stringToSign,
+		timestampHeader:    conf.TimestampHeader,
+		timestampTolerance: timestampTolerance,
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (s *Signature) Validate(req *http.Request) error {
+	value := req.Header.Get(s.header)
+	if value == "" {
+		return errors.SignatureMissing.Messagef("signature required in %s header", s.header)
+	}
+
+	if !strings.HasPrefix(value, s.prefix) {
+		return errors.Signature.Message("invalid signature").With(fmt.Errorf("missing prefix %q", s.prefix))
+	}
+
+	signature, err := s.decode(strings.TrimPrefix(value, s.prefix))
+	if err != nil {
+		return errors.Signature.Message("invalid signature").With(err)
+	}
+
+	var timestamp string
+	if s.timestampHeader != "" {
+		timestamp = req.Header.Get(s.timestampHeader)
+		if err = s.checkTimestamp(timestamp); err != nil {
+			return err
+		}
+	}
+
+	body, err := s.body(req)
+	if err != nil {
+		return errors.Signature.Message("reading body failed").With(err)
+	}
+
+	mac := hmac.New(s.hashFunc, s.key)
+	replacer := strings.NewReplacer(
+		signaturePlaceholderBody, string(body),
+		signaturePlaceholderMethod, req.Method,
+		signaturePlaceholderPath, req.URL.EscapedPath(),
+		signaturePlaceholderQuery, req.URL.RawQuery,
+		signaturePlaceholderTimestamp, timestamp,
+	)
+	_, _ = replacer.WriteString(mac, s.stringToSign)
+
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.Signature.Message("signature mismatch")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	info := make(map[string]interface{})
+	if timestamp != "" {
+		info["timestamp"] = timestamp
+	}
+	acMap[s.name] = info
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+func (s *Signature) checkTimestamp(timestamp string) error {
+	if timestamp == "" {
+		return errors.Signature.Messagef("timestamp required in %s header", s.timestampHeader)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Signature.Message("invalid timestamp").With(err)
+	}
+
+	diff := time.Since(time.Unix(seconds, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > s.timestampTolerance {
+		return errors.Signature.Message("timestamp outside of tolerance")
+	}
+
+	return nil
+}
+
+// body returns the raw request body. The body is buffered for all endpoints
+// protected by a signature access control. Otherwise, e.g. if used standalone,
+// the body gets buffered here up to the <signatureBodyLimit>.
+func (s *Signature) body(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		if err := eval.SetGetBody(req, buffer.Request, signatureBodyLimit); err != nil {
+			return nil, err
+		}
+		if req.GetBody == nil { // no body
+			return nil, nil
+		}
+	}
+
+	r, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
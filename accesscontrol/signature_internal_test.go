@@ -0,0 +1,48 @@
+package accesscontrol
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestSignature_BodyLimit(t *testing.T) {
+	defer func(limit int64) {
+		signatureBodyLimit = limit
+	}(signatureBodyLimit)
+	signatureBodyLimit = 8
+
+	key := []byte("It's a Secret to Everybody")
+	s, err := NewSignature(&config.Signature{Header: "Signature", Name: "sig"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body   string
+		expErr string
+	}{
+		{"12345678", ""},
+		{"123456789", "access control error: reading body failed: client request error: body size exceeded: 8B"},
+	} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(tc.body))
+
+		req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(tc.body))
+		req.Header.Set("Signature", hex.EncodeToString(mac.Sum(nil)))
+
+		err = s.Validate(req)
+		if tc.expErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.body, err)
+		} else if tc.expErr != "" && (err == nil || err.(*errors.Error).LogError() != tc.expErr) {
+			t.Errorf("%s: want error %q, got %v", tc.body, tc.expErr, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package accesscontrol_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestSignature_Validate(t *testing.T) {
+	const body = "Hello, World!"
+	key := []byte("It's a Secret to Everybody")
+
+	sha1Mac := hmac.New(sha1.New, key)
+	sha1Mac.Write([]byte(body))
+	sha1Signature := hex.EncodeToString(sha1Mac.Sum(nil))
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	tooOld := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	canonical := func(timestamp string) string {
+		mac := hmac.New(sha512.New, key)
+		mac.Write([]byte(timestamp + "\nPOST\n/hook\na=b\n" + body))
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	githubConf := &config.Signature{Header: "X-Hub-Signature-256", Name: "sig", Prefix: "sha256="}
+	canonicalConf := &config.Signature{
+		Algorithm:       "sha512",
+		Encoding:        "base64",
+		Header:          "Signature",
+		Name:            "sig",
+		StringToSign:    "{timestamp}\n{method}\n{path}\n{query}\n{body}",
+		TimestampHeader: "Timestamp",
+	}
+
+	for _, tc := range []struct {
+		name   string
+		conf   *config.Signature
+		header http.Header
+		expErr string
+	}{
+		{"sha256 hex", githubConf, http.Header{"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}}, ""},
+		{"sha256 hex: upper case", githubConf, http.Header{"X-Hub-Signature-256": {"sha256=757107EA0EB2509FC211221CCE984B8A37570B6D7586C22C46F4379C8B043E17"}}, ""},
+		{"sha256 hex: mismatch", githubConf, http.Header{"X-Hub-Signature-256": {"sha256=657107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}}, "access control error: signature mismatch"},
+		{"sha256 hex: missing prefix", githubConf, http.Header{"X-Hub-Signature-256": {"757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}}, `access control error: invalid signature: missing prefix "sha256="`},
+		{"sha256 hex: malformed", githubConf, http.Header{"X-Hub-Signature-256": {"sha256=xyz"}}, "access control error: invalid signature: encoding/hex: invalid byte: U+0078 'x'"},
+		{"sha1", &config.Signature{Algorithm: "sha1", Header: "X-Hub-Signature", Name: "sig", Prefix: "sha1="}, http.Header{"X-Hub-Signature": {"sha1=" + sha1Signature}}, ""},
+		{"canonical string", canonicalConf, http.Header{"Signature": {canonical(now)}, "Timestamp": {now}}, ""},
+		{"canonical string: other timestamp", canonicalConf, http.Header{"Signature": {canonical(now)}, "Timestamp": {strconv.FormatInt(time.Now().Unix()-1, 10)}}, "access control error: signature mismatch"},
+		{"canonical string: timestamp too old", canonicalConf, http.Header{"Signature": {canonical(tooOld)}, "Timestamp": {tooOld}}, "access control error: timestamp outside of tolerance"},
+		{"canonical string: timestamp missing", canonicalConf, http.Header{"Signature": {canonical(now)}}, "access control error: timestamp required in Timestamp header"},
+		{"canonical string: timestamp invalid", canonicalConf, http.Header{"Signature": {canonical(now)}, "Timestamp": {"now"}}, `access control error: invalid timestamp: strconv.ParseInt: parsing "now": invalid syntax`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			s, err := ac.NewSignature(tc.conf, key)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/hook?a=b", strings.NewReader(body))
+			req.Header = tc.header
+			err = s.Validate(req)
+			if tc.expErr != "" {
+				if err == nil || err.(*errors.Error).LogError() != tc.expErr {
+					st.Errorf("expected error %q, got %v", tc.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err.(*errors.Error).LogError())
+			}
+
+			if _, ok := req.Context().Value(request.AccessControls).(map[string]interface{})["sig"]; !ok {
+				st.Error("expected access control context")
+			}
+
+			// the body is still readable after the verification
+			b, err := io.ReadAll(req.Body)
+			if err != nil || string(b) != body {
+				st.Errorf("expected body %q, got %q (%v)", body, string(b), err)
+			}
+		})
+	}
+}
+
+func TestSignature_ValidateMissing(t *testing.T) {
+	s, err := ac.NewSignature(&config.Signature{Header: "X-Signature"}, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	if err = s.Validate(req); !errors.Equals(err, errors.SignatureMissing) {
+		t.Errorf("expected signature_missing error, got %v", err)
+	}
+}
+
+func TestSignature_TimestampReplay(t *testing.T) {
+	const body = "Hello, World!"
+	key := []byte("It's a Secret to Everybody")
+
+	sign := func(stringToSign string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(stringToSign))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	tooOld := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// an unsigned timestamp would let an old signature pass with a new timestamp header
+	_, err := ac.NewSignature(&config.Signature{Header: "Signature", StringToSign: "{body}", TimestampHeader: "Timestamp"}, key)
+	if err == nil {
+		t.Fatal("expected config error for timestamp_header without {timestamp} in string_to_sign")
+	}
+
+	s, err := ac.NewSignature(&config.Signature{Header: "Signature", StringToSign: "{timestamp}.{body}", TimestampHeader: "Timestamp"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	req.Header = http.Header{"Signature": {sign(tooOld + "." + body)}, "Timestamp": {now}}
+	if err = s.Validate(req); err == nil || err.(*errors.Error).LogError() != "access control error: signature mismatch" {
+		t.Errorf("expected signature mismatch for replayed signature, got %v", err)
+	}
+}
+
+func TestNewSignature_Config(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		conf   *config.Signature
+		key    string
+		expErr string
+	}{
+		{"empty key", &config.Signature{Header: "X-Signature"}, "", "key: must not be empty"},
+		{"algorithm", &config.Signature{Header: "X-Signature", Algorithm: "md5"}, "key", `algorithm: unsupported value "md5"`},
+		{"encoding", &config.Signature{Header: "X-Signature", Encoding: "base32"}, "key", `encoding: unsupported value "base32"`},
+		{"timestamp without timestamp_header", &config.Signature{Header: "X-Signature", StringToSign: "{timestamp}.{body}"}, "key", "string_to_sign: {timestamp} requires timestamp_header"},
+		{"timestamp_header without timestamp", &config.Signature{Header: "X-Signature", TimestampHeader: "X-Timestamp"}, "key", "timestamp_header: requires {timestamp} in string_to_sign"},
+		{"timestamp_tolerance", &config.Signature{Header: "X-Signature", TimestampTolerance: "1x"}, "key", `timestamp_tolerance: time: unknown unit "x" in duration "1x"`},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewSignature(tc.conf, []byte(tc.key))
+			if err == nil || err.Error() != tc.expErr {
+				st.Errorf("expected error %q, got %v", tc.expErr, err)
+			}
+		})
+	}
+}
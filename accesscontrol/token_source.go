@@ -18,6 +18,8 @@ const (
 	cookieType
 	headerType
 	valueType
+	queryType
+	dpopType
 )
 
 type (
@@ -74,6 +76,42 @@ func NewTokenSource(bearer bool, cookie, header string, value hcl.Expression) (*
 	return ts, nil
 }
 
+// NewDPoPTokenSource creates a new token source for DPoP-bound tokens which are read
+// from an "Authorization: DPoP ..." request header field, see RFC 9449.
+func NewDPoPTokenSource() *TokenSource {
+	return &TokenSource{tsType: dpopType}
+}
+
+// NewKeySource creates a new token source for API keys which are read from a cookie,
+// a request header field (default: "X-API-Key") or a query parameter.
+func NewKeySource(cookie, header, query string) (*TokenSource, error) {
+	c, h, q := strings.TrimSpace(cookie), strings.TrimSpace(header), strings.TrimSpace(query)
+
+	var b uint8
+	ts := &TokenSource{
+		name:   "X-API-Key",
+		tsType: headerType,
+	}
+
+	if c != "" {
+		b |= (1 << cookieType)
+		ts.name, ts.tsType = c, cookieType
+	}
+	if h != "" {
+		b |= (1 << headerType)
+		ts.name, ts.tsType = h, headerType
+	}
+	if q != "" {
+		b |= (1 << queryType)
+		ts.name, ts.tsType = q, queryType
+	}
+	if bits.OnesCount8(b) > 1 {
+		return nil, fmt.Errorf("only one of cookie, header or query_param attributes is allowed")
+	}
+
+	return ts, nil
+}
+
 // TokenValue retrieves the token value from the request.
 func (s *TokenSource) TokenValue(req *http.Request) (string, error) {
 	var tokenValue string
@@ -82,6 +120,8 @@ func (s *TokenSource) TokenValue(req *http.Request) (string, error) {
 	switch s.tsType {
 	case bearerType:
 		tokenValue, err = getBearerAuth(req.Header)
+	case dpopType:
+		tokenValue, err = getDPoPAuth(req.Header)
 	case cookieType:
 		cookie, cerr := req.Cookie(s.name)
 		if cerr != http.ErrNoCookie && cookie != nil {
@@ -93,6 +133,8 @@ func (s *TokenSource) TokenValue(req *http.Request) (string, error) {
 		} else {
 			tokenValue = req.Header.Get(s.name)
 		}
+	case queryType:
+		tokenValue = req.URL.Query().Get(s.name)
 	case valueType:
 		requestContext := eval.ContextFromRequest(req).HCLContext()
 		var value cty.Value
@@ -125,6 +167,20 @@ func getBearerAuth(reqHeaders http.Header) (string, error) {
 	return getBearer(authorization)
 }
 
+// getDPoPAuth retrieves a DPoP-bound token from the request headers.
+func getDPoPAuth(reqHeaders http.Header) (string, error) {
+	authorization := reqHeaders.Get("Authorization")
+	if authorization == "" {
+		return "", fmt.Errorf("missing authorization header")
+	}
+
+	const dpop = "dpop "
+	if strings.HasPrefix(strings.ToLower(authorization), dpop) {
+		return strings.Trim(authorization[len(dpop):], " "), nil
+	}
+	return "", fmt.Errorf("dpop with token required in authorization header")
+}
+
 // getBearer retrieves a bearer token from the Authorization request header field value.
 func getBearer(authorization string) (string, error) {
 	const bearer = "bearer "
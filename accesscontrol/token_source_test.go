@@ -152,3 +152,41 @@ func Test_TokenValue(t *testing.T) {
 		})
 	}
 }
+
+func Test_DPoPTokenValue(t *testing.T) {
+	ts := ac.NewDPoPTokenSource()
+
+	for _, tc := range []struct {
+		name      string
+		reqHeader http.Header
+		expToken  string
+		expErrMsg string
+	}{
+		{"dpop", http.Header{"Authorization": []string{"DPoP asdf"}}, "asdf", ""},
+		{"missing authorization header", http.Header{}, "", "missing authorization header"},
+		{"missing token in dpop authorization header", http.Header{"Authorization": []string{"DPoP "}}, "", "token required"},
+		{"bearer auth scheme", http.Header{"Authorization": []string{"Bearer asdf"}}, "", "dpop with token required in authorization header"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/foo", nil)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			req.Header = tc.reqHeader
+
+			token, err := ts.TokenValue(req)
+			if tc.expErrMsg != "" {
+				if err == nil || err.Error() != tc.expErrMsg {
+					subT.Errorf("expected error message: %q, got %v", tc.expErrMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if token != tc.expToken {
+				subT.Errorf("expected token: %q, got %q", tc.expToken, token)
+			}
+		})
+	}
+}
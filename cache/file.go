@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fileGCInterval = time.Minute
+	tmpFilePrefix  = ".tmp-"
+)
+
+var _ backend = &fileBackend{}
+
+// fileBackend stores each entry as a single file within a directory. Files are
+// replaced atomically, so the directory may be shared by multiple instances.
+type fileBackend struct {
+	dir string
+	log *logrus.Entry
+}
+
+// NewFileStore creates a new <SharedStore> object which persists
+// its shareable entries within the given directory.
+func NewFileStore(dir string, log *logrus.Entry, quitCh <-chan struct{}) (*SharedStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file: missing directory")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+
+	fb := &fileBackend{dir: dir, log: log}
+	go fb.gc(quitCh)
+
+	return newSharedStore(fb, log, quitCh), nil
+}
+
+func (fb *fileBackend) get(k string) ([]byte, error) {
+	b, err := os.ReadFile(fb.path(k))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	value, expired := decodeFileEntry(b, time.Now())
+	if expired {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (fb *fileBackend) set(k string, v []byte, ttl int64) error {
+	tmpFile, err := fb.writeTemp(v, ttl)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, fb.path(k))
+}
+
+// setNX links the written entry to its final path which fails if the file exists.
+// Expired files are removed first; entries are replaced atomically otherwise.
+func (fb *fileBackend) setNX(k string, v []byte, ttl int64) (bool, error) {
+	tmpFile, err := fb.writeTemp(v, ttl)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmpFile)
+
+	name := fb.path(k)
+	for i := 0; i < 2; i++ {
+		err = os.Link(tmpFile, name)
+		if !errors.Is(err, fs.ErrExist) {
+			return err == nil, err
+		}
+
+		if existing, gerr := fb.get(k); gerr != nil || existing != nil {
+			return false, gerr
+		}
+
+		if err = fb.del(k); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// writeTemp writes the entry to a temporary file within the directory and returns its name.
+func (fb *fileBackend) writeTemp(v []byte, ttl int64) (string, error) {
+	entry := make([]byte, 8, 8+len(v))
+	binary.BigEndian.PutUint64(entry, uint64(time.Now().Unix()+ttl))
+	entry = append(entry, v...)
+
+	f, err := os.CreateTemp(fb.dir, tmpFilePrefix+"*")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = f.Write(entry); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+func (fb *fileBackend) del(k string) error {
+	err := os.Remove(fb.path(k))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (fb *fileBackend) path(k string) string {
+	sum := sha256.Sum256([]byte(k))
+	return filepath.Join(fb.dir, hex.EncodeToString(sum[:]))
+}
+
+func (fb *fileBackend) gc(quitCh <-chan struct{}) {
+	ticker := time.NewTicker(fileGCInterval)
+
+	defer func() {
+		if rc := recover(); rc != nil {
+			fb.log.WithField("panic", string(debug.Stack())).Panic(rc)
+		}
+		ticker.Stop()
+	}()
+
+	for {
+		select {
+		case <-quitCh:
+			return
+		case now := <-ticker.C:
+			entries, err := os.ReadDir(fb.dir)
+			if err != nil {
+				fb.log.WithError(err).Warn("cache store: gc failed")
+				continue
+			}
+
+			for _, e := range entries {
+				if e.IsDir() {
+					continue
+				}
+
+				name := filepath.Join(fb.dir, e.Name())
+				if strings.HasPrefix(e.Name(), tmpFilePrefix) { // leftovers of interrupted writes
+					if info, ierr := e.Info(); ierr == nil && now.Sub(info.ModTime()) > fileGCInterval {
+						_ = os.Remove(name)
+					}
+					continue
+				}
+
+				b, rerr := os.ReadFile(name)
+				if rerr != nil {
+					continue
+				}
+
+				if _, expired := decodeFileEntry(b, now); expired {
+					_ = os.Remove(name)
+				}
+			}
+		}
+	}
+}
+
+// decodeFileEntry returns the value of the given file content and
+// whether the entry is expired. Invalid entries are treated as expired.
+func decodeFileEntry(b []byte, now time.Time) ([]byte, bool) {
+	if len(b) < 8 {
+		return nil, true
+	}
+
+	expAt := int64(binary.BigEndian.Uint64(b[:8]))
+	if now.Unix() >= expAt {
+		return nil, true
+	}
+	return b[8:], false
+}
@@ -80,18 +80,36 @@ func (ms *MemoryStore) GetAllWithPrefix(prefix string) []interface{} {
 
 // Set stores a key/value pair for <ttl> second(s) into the <MemoryStore>.
 func (ms *MemoryStore) Set(k string, v interface{}, ttl int64) {
+	ms.mu.Lock()
+	ms.set(k, v, ttl)
+	ms.mu.Unlock()
+}
+
+// SetIfAbsent stores a key/value pair for <ttl> second(s) into the <MemoryStore>
+// unless a non-expired value exists for the key.
+func (ms *MemoryStore) SetIfAbsent(k string, v interface{}, ttl int64) bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if e, ok := ms.db[k]; ok && time.Now().Unix() < e.expAt {
+		return false
+	}
+
+	ms.set(k, v, ttl)
+	return true
+}
+
+func (ms *MemoryStore) set(k string, v interface{}, ttl int64) {
 	if ttl < 0 {
 		ttl = 0
 	} else if ttl > maxExpiresIn {
 		ttl = maxExpiresIn
 	}
 
-	ms.mu.Lock()
 	ms.db[k] = &entry{
 		value: v,
 		expAt: time.Now().Unix() + ttl,
 	}
-	ms.mu.Unlock()
 }
 
 func (ms *MemoryStore) gc() {
@@ -44,4 +44,14 @@ func TestCache_All(t *testing.T) {
 	if v := ms.Get("del"); v != "del" {
 		t.Errorf("Expected 'del', given %q", v)
 	}
+
+	if ms.SetIfAbsent("del", "other", 30) {
+		t.Error("Expected existing value to be kept")
+	}
+	if !ms.SetIfAbsent("key", "new", 30) {
+		t.Error("Expected expired value to be replaced")
+	}
+	if v := ms.Get("key"); v != "new" {
+		t.Errorf("Expected 'new', given %q", v)
+	}
 }
@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	redisMaxIdleConns = 8
+	redisTimeout      = 5 * time.Second
+)
+
+var _ backend = &redisBackend{}
+
+// redisBackend implements a minimal client for servers speaking the Redis serialization protocol (RESP).
+type redisBackend struct {
+	addr      string
+	db        int
+	idleConns chan *redisConn
+	password  string
+	tlsConf   *tls.Config
+	username  string
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRedisStore creates a new <SharedStore> object which writes its shareable
+// entries to the Redis server with the given URL:
+// redis[s]://[[user]:password@]host[:port][/database].
+func NewRedisStore(rawURL string, log *logrus.Entry, quitCh <-chan struct{}) (*SharedStore, error) {
+	rb, err := newRedisBackend(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	// fail early on connection or authentication errors
+	if _, err = rb.do("PING"); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	go func() {
+		<-quitCh
+		rb.close()
+	}()
+
+	return newSharedStore(rb, log, quitCh), nil
+}
+
+func newRedisBackend(rawURL string) (*redisBackend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rb := &redisBackend{
+		idleConns: make(chan *redisConn, redisMaxIdleConns),
+	}
+
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		rb.tlsConf = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+
+	rb.addr = u.Host
+	if u.Port() == "" {
+		rb.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	if u.User != nil {
+		rb.username = u.User.Username()
+		rb.password, _ = u.User.Password()
+	}
+
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if rb.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid database number %q", db)
+		}
+	}
+
+	return rb, nil
+}
+
+func (rb *redisBackend) get(k string) ([]byte, error) {
+	reply, err := rb.do("GET", k)
+	if err != nil {
+		return nil, err
+	}
+
+	b, _ := reply.([]byte)
+	return b, nil
+}
+
+func (rb *redisBackend) set(k string, v []byte, ttl int64) error {
+	if ttl <= 0 { // would be expired anyway
+		return rb.del(k)
+	}
+
+	_, err := rb.do("SET", k, string(v), "EX", strconv.FormatInt(ttl, 10))
+	return err
+}
+
+func (rb *redisBackend) setNX(k string, v []byte, ttl int64) (bool, error) {
+	if ttl <= 0 { // would be expired anyway
+		return true, nil
+	}
+
+	reply, err := rb.do("SET", k, string(v), "EX", strconv.FormatInt(ttl, 10), "NX")
+	if err != nil {
+		return false, err
+	}
+
+	// a nil reply signals an existing key
+	return reply != nil, nil
+}
+
+func (rb *redisBackend) del(k string) error {
+	_, err := rb.do("DEL", k)
+	return err
+}
+
+// do sends the given command and returns its reply which is either
+// a string, an int64, a []byte or nil.
+func (rb *redisBackend) do(args ...string) (interface{}, error) {
+	rc, err := rb.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rc.do(args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		_ = rc.conn.Close() // connection state is unknown
+		return nil, err
+	}
+
+	select {
+	case rb.idleConns <- rc:
+	default:
+		_ = rc.conn.Close()
+	}
+
+	return reply, err
+}
+
+func (rb *redisBackend) conn() (*redisConn, error) {
+	select {
+	case rc := <-rb.idleConns:
+		return rc, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if rb.tlsConf != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", rb.addr, rb.tlsConf)
+	} else {
+		conn, err = dialer.Dial("tcp", rb.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if rb.password != "" {
+		auth := []string{"AUTH", rb.password}
+		if rb.username != "" {
+			auth = []string{"AUTH", rb.username, rb.password}
+		}
+		if _, err = rc.do(auth...); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	if rb.db != 0 {
+		if _, err = rc.do("SELECT", strconv.Itoa(rb.db)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return rc, nil
+}
+
+func (rb *redisBackend) close() {
+	for {
+		select {
+		case rc := <-rb.idleConns:
+			_ = rc.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+func (rc *redisConn) do(args ...string) (interface{}, error) {
+	if err := rc.conn.SetDeadline(time.Now().Add(redisTimeout)); err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		sb.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+
+	if _, err := io.WriteString(rc.conn, sb.String()); err != nil {
+		return nil, err
+	}
+
+	return rc.readReply()
+}
+
+func (rc *redisConn) readReply() (interface{}, error) {
+	line, err := rc.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("invalid reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, perr := strconv.Atoi(line[1:])
+		if perr != nil {
+			return nil, fmt.Errorf("invalid bulk string size: %q", line[1:])
+		} else if size < 0 {
+			return nil, nil
+		}
+
+		b := make([]byte, size+2) // including trailing CRLF
+		if _, err = io.ReadFull(rc.r, b); err != nil {
+			return nil, err
+		}
+		return b[:size], nil
+	default:
+		return nil, fmt.Errorf("unsupported reply type: %q", line[0])
+	}
+}
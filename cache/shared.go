@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// backend represents a persistent or remote key/value storage
+// which is able to hold serialized values only.
+type backend interface {
+	// get returns nil without an error for missing or expired keys.
+	get(k string) ([]byte, error)
+	set(k string, v []byte, ttl int64) error
+	// setNX stores the value unless a non-expired one exists for the key.
+	setNX(k string, v []byte, ttl int64) (bool, error)
+	del(k string) error
+}
+
+// SharedStore represents the <SharedStore> object. String values, e.g. tokens,
+// are written to the configured backend and may be shared across instances or restarts.
+// All other values are runtime objects like backends or parsers and are held
+// by the process-local <MemoryStore>.
+type SharedStore struct {
+	*MemoryStore
+	backend backend
+}
+
+func newSharedStore(b backend, log *logrus.Entry, quitCh <-chan struct{}) *SharedStore {
+	return &SharedStore{
+		MemoryStore: New(log, quitCh),
+		backend:     b,
+	}
+}
+
+// Del deletes the value by the key from the <SharedStore>.
+func (ss *SharedStore) Del(k string) {
+	ss.MemoryStore.Del(k)
+
+	if err := ss.backend.del(k); err != nil {
+		ss.log.WithError(err).Warn("cache store: delete failed")
+	}
+}
+
+// Get return the value by the key if the ttl is not expired from the <SharedStore>.
+func (ss *SharedStore) Get(k string) interface{} {
+	if v := ss.MemoryStore.Get(k); v != nil {
+		return v
+	}
+
+	v, err := ss.backend.get(k)
+	if err != nil {
+		ss.log.WithError(err).Warn("cache store: read failed")
+		return nil
+	} else if v == nil {
+		return nil
+	}
+
+	return string(v)
+}
+
+// Set stores a key/value pair for <ttl> second(s) into the <SharedStore>.
+// Values which are not of type string and values which could not be
+// written to the backend are stored in memory.
+func (ss *SharedStore) Set(k string, v interface{}, ttl int64) {
+	s, ok := v.(string)
+	if !ok {
+		ss.MemoryStore.Set(k, v, ttl)
+		return
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	} else if ttl > maxExpiresIn {
+		ttl = maxExpiresIn
+	}
+
+	if err := ss.backend.set(k, []byte(s), ttl); err != nil {
+		ss.log.WithError(err).Warn("cache store: write failed")
+		ss.MemoryStore.Set(k, v, ttl)
+		return
+	}
+
+	// drop a possible in-memory fallback entry
+	ss.MemoryStore.Del(k)
+}
+
+// SetIfAbsent stores a key/value pair for <ttl> second(s) into the <SharedStore>
+// unless a non-expired value exists for the key. Like with <Set>, values which are
+// not of type string or could not be written to the backend are stored in memory.
+func (ss *SharedStore) SetIfAbsent(k string, v interface{}, ttl int64) bool {
+	s, ok := v.(string)
+	if !ok {
+		return ss.MemoryStore.SetIfAbsent(k, v, ttl)
+	}
+
+	// a former write may have fallen back to memory
+	if ss.MemoryStore.Get(k) != nil {
+		return false
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	} else if ttl > maxExpiresIn {
+		ttl = maxExpiresIn
+	}
+
+	stored, err := ss.backend.setNX(k, []byte(s), ttl)
+	if err != nil {
+		ss.log.WithError(err).Warn("cache store: write failed")
+		return ss.MemoryStore.SetIfAbsent(k, v, ttl)
+	}
+
+	return stored
+}
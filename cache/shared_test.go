@@ -0,0 +1,286 @@
+package cache_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestSharedStore_File(t *testing.T) {
+	log, _ := test.NewLogger()
+	logger := log.WithContext(context.Background())
+
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	dir := t.TempDir()
+	store, err := cache.NewFileStore(dir, logger, quitCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testSharedStore(t, store)
+
+	// another instance with the same directory
+	other, err := cache.NewFileStore(dir, logger, quitCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Set("shared", "token", 30)
+	if v := other.Get("shared"); v != "token" {
+		t.Errorf("Expected 'token', given %q", v)
+	}
+}
+
+func TestSharedStore_Redis(t *testing.T) {
+	log, _ := test.NewLogger()
+	logger := log.WithContext(context.Background())
+
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	srv := newRedisStandIn(t, "secret")
+	defer srv.Close()
+
+	if _, err := cache.NewRedisStore("redis://:wrong@"+srv.Addr().String(), logger, quitCh); err == nil ||
+		err.Error() != "redis: WRONGPASS invalid password" {
+		t.Errorf("Expected authentication error, given: %v", err)
+	}
+
+	store, err := cache.NewRedisStore("redis://:secret@"+srv.Addr().String()+"/2", logger, quitCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testSharedStore(t, store)
+
+	other, err := cache.NewRedisStore("redis://:secret@"+srv.Addr().String()+"/2", logger, quitCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Set("shared", "token", 30)
+	if v := other.Get("shared"); v != "token" {
+		t.Errorf("Expected 'token', given %q", v)
+	}
+}
+
+func TestSharedStore_RedisURL(t *testing.T) {
+	log, _ := test.NewLogger()
+	logger := log.WithContext(context.Background())
+
+	for _, tc := range []struct {
+		url    string
+		expErr string
+	}{
+		{"http://localhost", `redis: unsupported URL scheme "http"`},
+		{"redis://", "redis: missing host"},
+		{"redis://localhost/foo", `redis: invalid database number "foo"`},
+	} {
+		_, err := cache.NewRedisStore(tc.url, logger, nil)
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("%s: expected error %q, given: %v", tc.url, tc.expErr, err)
+		}
+	}
+}
+
+func testSharedStore(t *testing.T, store cache.Store) {
+	t.Helper()
+
+	if v := store.Get("key"); v != nil {
+		t.Errorf("Nil expected, given %q", v)
+	}
+
+	store.Set("key", "val", 1)
+	store.Set("del", "del", 30)
+	store.Set("obj", []string{"runtime", "object"}, 30)
+
+	if v := store.Get("key"); v != "val" {
+		t.Errorf("Expected 'val', given %q", v)
+	}
+	if v, ok := store.Get("obj").([]string); !ok || len(v) != 2 {
+		t.Errorf("Expected in-memory object, given %#v", store.Get("obj"))
+	}
+
+	store.Del("del")
+	if v := store.Get("del"); v != nil {
+		t.Errorf("Nil expected, given %q", v)
+	}
+
+	testSetIfAbsent(t, store, "once", "val")
+	testSetIfAbsent(t, store, "once-obj", []string{"runtime", "object"})
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if v := store.Get("key"); v != nil {
+		t.Errorf("Nil expected, given %q", v)
+	}
+
+	if !store.SetIfAbsent("key", "new", 30) {
+		t.Error("Expected expired value to be replaced")
+	}
+	if v := store.Get("key"); v != "new" {
+		t.Errorf("Expected 'new', given %q", v)
+	}
+}
+
+func testSetIfAbsent(t *testing.T, store cache.Store, key string, value interface{}) {
+	t.Helper()
+
+	var stored int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.SetIfAbsent(key, value, 30) {
+				atomic.AddInt32(&stored, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Errorf("%s: expected exactly one stored value, given %d", key, stored)
+	}
+	if store.Get(key) == nil {
+		t.Errorf("%s: expected stored value", key)
+	}
+}
+
+// redisStandIn is a minimal in-memory server speaking the
+// Redis serialization protocol for the commands used by the cache store.
+type redisStandIn struct {
+	net.Listener
+	password string
+
+	mu sync.Mutex
+	db map[string]redisStandInEntry
+}
+
+type redisStandInEntry struct {
+	value string
+	expAt time.Time
+}
+
+func newRedisStandIn(t *testing.T, password string) *redisStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &redisStandIn{Listener: ln, password: password, db: make(map[string]redisStandInEntry)}
+	go func() {
+		for {
+			conn, aerr := ln.Accept()
+			if aerr != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *redisStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	authenticated, db := s.password == "", "0"
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			if args[len(args)-1] != s.password {
+				reply = "-WRONGPASS invalid password\r\n"
+				break
+			}
+			authenticated, reply = true, "+OK\r\n"
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "PING":
+			reply = "+PONG\r\n"
+		case cmd == "SELECT":
+			db, reply = args[1], "+OK\r\n"
+		case cmd == "GET":
+			s.mu.Lock()
+			e, ok := s.db[db+"/"+args[1]]
+			s.mu.Unlock()
+			if !ok || time.Now().After(e.expAt) {
+				reply = "$-1\r\n"
+				break
+			}
+			reply = fmt.Sprintf("$%d\r\n%s\r\n", len(e.value), e.value)
+		case cmd == "SET":
+			ttl, _ := strconv.Atoi(args[4])
+			reply = "+OK\r\n"
+			s.mu.Lock()
+			if e, ok := s.db[db+"/"+args[1]]; ok && len(args) > 5 && strings.ToUpper(args[5]) == "NX" && time.Now().Before(e.expAt) {
+				reply = "$-1\r\n"
+			} else {
+				s.db[db+"/"+args[1]] = redisStandInEntry{value: args[2], expAt: time.Now().Add(time.Duration(ttl) * time.Second)}
+			}
+			s.mu.Unlock()
+		case cmd == "DEL":
+			s.mu.Lock()
+			delete(s.db, db+"/"+args[1])
+			s.mu.Unlock()
+			reply = ":1\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+
+		if _, err = io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, serr := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if serr != nil {
+			return nil, serr
+		}
+
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
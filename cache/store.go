@@ -0,0 +1,30 @@
+package cache
+
+// Store represents the interface of a key/value storage with expiring entries.
+type Store interface {
+	// Del deletes the value by the key.
+	Del(k string)
+	// Get returns the value by the key if the ttl is not expired.
+	Get(k string) interface{}
+	// GetAllWithPrefix returns all non-expired values whose keys start with the given prefix.
+	GetAllWithPrefix(prefix string) []interface{}
+	// Set stores a key/value pair for <ttl> second(s).
+	Set(k string, v interface{}, ttl int64)
+	// SetIfAbsent atomically stores a key/value pair for <ttl> second(s) unless a
+	// non-expired value exists for the key and reports whether the pair has been stored.
+	SetIfAbsent(k string, v interface{}, ttl int64) bool
+}
+
+var (
+	_ Store = &MemoryStore{}
+	_ Store = &SharedStore{}
+)
+
+// Local returns the process-local part of the given store. It is sufficient for
+// runtime objects since those are never written to the backend of a <SharedStore>.
+func Local(s Store) Store {
+	if ss, ok := s.(*SharedStore); ok {
+		return ss.MemoryStore
+	}
+	return s
+}
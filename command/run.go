@@ -85,7 +85,11 @@ func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry)
 	timings := runtime.DefaultTimings
 	env.Decode(&timings)
 
-	memStore := cache.New(logEntry, r.context.Done())
+	memStore, err := newCacheStore(config.Settings, logEntry, r.context.Done())
+	if err != nil {
+		return err
+	}
+
 	// logEntry has still the 'daemon' type which can be used for config related load errors.
 	srvConf, err := runtime.NewServerConfiguration(config, logEntry, memStore)
 	if err != nil {
@@ -202,6 +206,32 @@ func readCertificateFile(file string) ([]byte, error) {
 	return cert, nil
 }
 
+// newCacheStore creates the configured <cache.Store>.
+func newCacheStore(settings *config.Settings, logEntry *logrus.Entry, quitCh <-chan struct{}) (cache.Store, error) {
+	var (
+		store cache.Store
+		err   error
+	)
+
+	switch settings.CacheStore {
+	case "", "memory":
+		return cache.New(logEntry, quitCh), nil
+	case "file":
+		store, err = cache.NewFileStore(settings.CacheStorePath, logEntry, quitCh)
+	case "redis":
+		store, err = cache.NewRedisStore(settings.CacheStoreURL, logEntry, quitCh)
+	default:
+		return nil, fmt.Errorf("invalid value for the -cache-store flag given: '%s' only 'memory', 'file' or 'redis' are supported", settings.CacheStore)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cache store: %w", err)
+	}
+
+	logEntry.Infof("configured with cache store: %s", settings.CacheStore)
+	return store, nil
+}
+
 func (r *Run) Usage() {
 	r.flagSet.Usage()
 }
@@ -209,6 +239,10 @@ func (r *Run) Usage() {
 func newFlagSet(settings *config.Settings, cmdName string) *flag.FlagSet {
 	set := flag.NewFlagSet(cmdName, flag.ContinueOnError)
 	set.StringVar(&settings.CAFile, "ca-file", settings.CAFile, "-ca-file certificate.pem")
+	set.StringVar(&settings.CacheStore, "cache-store", settings.CacheStore, "-cache-store [memory|file|redis]")
+	set.StringVar(&settings.CacheStorePath, "cache-store-path", settings.CacheStorePath, "-cache-store-path /var/lib/couper")
+	set.StringVar(&settings.CacheStoreURL, "cache-store-url", settings.CacheStoreURL, "-cache-store-url redis://localhost:6379/0")
+	set.BoolVar(&settings.H2C, "h2c", settings.H2C, "-h2c")
 	set.StringVar(&settings.HealthPath, "health-path", settings.HealthPath, "-health-path /healthz")
 	set.IntVar(&settings.DefaultPort, "p", settings.DefaultPort, "-p 8080")
 	set.BoolVar(&settings.XForwardedHost, "xfh", settings.XForwardedHost, "-xfh")
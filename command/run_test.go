@@ -49,6 +49,7 @@ func TestNewRun(t *testing.T) {
 			AcceptForwarded:          &config.AcceptForwarded{},
 			BindAddress:              "*",
 			BindAddresses:            map[string]string{"": "tcp"},
+			CacheStore:               defaultSettings.CacheStore,
 			DefaultPort:              9090,
 			HealthPath:               "/status/health",
 			LogFormat:                defaultSettings.LogFormat,
@@ -69,6 +70,7 @@ func TestNewRun(t *testing.T) {
 			AcceptForwarded:          &config.AcceptForwarded{},
 			BindAddress:              "*",
 			BindAddresses:            map[string]string{"": "tcp"},
+			CacheStore:               defaultSettings.CacheStore,
 			DefaultPort:              9876,
 			HealthPath:               defaultSettings.HealthPath,
 			LogFormat:                defaultSettings.LogFormat,
@@ -87,6 +89,7 @@ func TestNewRun(t *testing.T) {
 			AcceptForwarded:          &config.AcceptForwarded{},
 			BindAddress:              "*",
 			BindAddresses:            map[string]string{"": "tcp"},
+			CacheStore:               defaultSettings.CacheStore,
 			DefaultPort:              4561,
 			HealthPath:               defaultSettings.HealthPath,
 			LogFormat:                defaultSettings.LogFormat,
@@ -405,6 +408,33 @@ settings {
 	}
 }
 
+func TestCacheStore_Run(t *testing.T) {
+	helper := test.New(t)
+
+	for _, tc := range []struct {
+		settings string
+		want     string
+	}{
+		{`cache_store = "foo"`, "invalid value for the -cache-store flag given: 'foo' only 'memory', 'file' or 'redis' are supported"},
+		{`cache_store = "file"`, "cache store: file: missing directory"},
+		{`cache_store = "redis"
+  cache_store_url = "memcache://localhost"`, `cache store: redis: unsupported URL scheme "memcache"`},
+	} {
+		couperFile, err := configload.LoadBytes([]byte("server {}\nsettings {\n  "+tc.settings+"\n}\n"), "cache-store-test.hcl")
+		helper.Must(err)
+
+		ctx, shutdown := context.WithDeadline(context.Background(), time.Now().Add(time.Second))
+
+		log, _ := test.NewLogger()
+		execErr := NewRun(ctx).Execute(Args{}, couperFile, log.WithContext(ctx))
+		shutdown()
+
+		if execErr == nil || execErr.Error() != tc.want {
+			t.Errorf("want: %q, got: %v", tc.want, execErr)
+		}
+	}
+}
+
 func TestReadCAFile(t *testing.T) {
 	helper := test.New(t)
 
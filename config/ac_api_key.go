@@ -0,0 +1,53 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &APIKey{}
+	_ Inline = &APIKey{}
+)
+
+// APIKey represents the "api_key" config block
+type APIKey struct {
+	ErrorHandlerSetter
+	Cookie             string               `hcl:"cookie,optional" docs:"Read the API key from a cookie. Cannot be used together with {header} or {query_param}."`
+	Header             string               `hcl:"header,optional" docs:"Read the API key from the given request header field. Implies {Bearer} if {Authorization} (case-insensitive) is used. Cannot be used together with {cookie} or {query_param}." default:"X-API-Key"`
+	Keys               map[string]cty.Value `hcl:"keys,optional" docs:"Object with the accepted API keys as keys and the related [metadata](#metadata) objects as values." type:"object"`
+	KeysFile           string               `hcl:"keys_file,optional" docs:"Reference to a JSON file with the hex-encoded SHA-256 hashes of the accepted API keys as keys and the related [metadata](#metadata) objects as values."`
+	Name               string               `hcl:"name,label"`
+	PermissionsMap     map[string][]string  `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from the {permissions} metadata field. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
+	PermissionsMapFile string               `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
+	QueryParam         string               `hcl:"query_param,optional" docs:"Read the API key from the given query parameter. Cannot be used together with {cookie} or {header}."`
+	Remain             hcl.Body             `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *APIKey) HCLBody() *hclsyntax.Body {
+	return a.Remain.(*hclsyntax.Body)
+}
+
+func (a *APIKey) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (a *APIKey) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(a)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(a.Inline())
+	return schema
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+const (
+	AuthorizationOrderAllowFirst = "allow_first"
+	AuthorizationOrderDenyFirst  = "deny_first"
+	AuthorizationOrderFirstMatch = "first_match"
+)
+
+var (
+	_ Body   = &Authorization{}
+	_ Inline = &Authorization{}
+)
+
+// Authorization represents the "authorization" config block
+type Authorization struct {
+	ErrorHandlerSetter
+	DefaultEffect string               `hcl:"default_effect,optional" docs:"The effect if no rule matches. Valid values: {\"allow\"}, {\"deny\"}." default:"deny"`
+	LogDecisions  bool                 `hcl:"log_decisions,optional" docs:"Whether to log every policy decision, see [Decision Log](#decision-log)."`
+	Name          string               `hcl:"name,label"`
+	Order         string               `hcl:"order,optional" docs:"How matching rules are combined, see [Rule Order](#rule-order). Valid values: {\"first_match\"}, {\"deny_first\"}, {\"allow_first\"}." default:"first_match"`
+	Remain        hcl.Body             `hcl:",remain"`
+	Rules         []*AuthorizationRule `hcl:"rule,block" docs:"Configures a [rule](/configuration/block/authorization_rule) (one or more)."`
+}
+
+// AuthorizationRule represents the "rule" block of an "authorization" block.
+type AuthorizationRule struct {
+	Condition hcl.Expression `hcl:"condition" docs:"The rule matches the request if this expression evaluates to {true}, e.g. {request.context.<jwt_name>.sub == request.path_params.user}." type:"expression (bool)"`
+	Effect    string         `hcl:"effect" docs:"The effect of the rule. Valid values: {\"allow\"}, {\"deny\"}."`
+	Name      string         `hcl:"name,label"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *Authorization) HCLBody() *hclsyntax.Body {
+	return a.Remain.(*hclsyntax.Body)
+}
+
+func (a *Authorization) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (a *Authorization) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(a)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(a.Inline())
+	return meta.MergeSchemas(schema, meta.LogFieldsAttributeSchema)
+}
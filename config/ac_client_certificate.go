@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &ClientCertificateAC{}
+	_ Inline = &ClientCertificateAC{}
+)
+
+// ClientCertificateAC represents the "client_certificate" access control block
+// which is not to be confused with the "client_certificate" block of the server "tls" block.
+type ClientCertificateAC struct {
+	ErrorHandlerSetter
+	AllowedSANs        []string            `hcl:"allowed_sans,optional" docs:"List of [patterns](#patterns) of which at least one must match one of the subject alternative names of the client certificate."`
+	AllowedSubjects    []string            `hcl:"allowed_subjects,optional" docs:"List of [patterns](#patterns) of which at least one must match the subject distinguished name of the client certificate."`
+	Name               string              `hcl:"name,label"`
+	PermissionsMap     map[string][]string `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from {subject_permissions} and {san_permissions}. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
+	PermissionsMapFile string              `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
+	SANPermissions     map[string][]string `hcl:"san_permissions,optional" docs:"Mapping of [patterns](#patterns) to permissions granted if one of the subject alternative names of the client certificate matches."`
+	SubjectPermissions map[string][]string `hcl:"subject_permissions,optional" docs:"Mapping of [patterns](#patterns) to permissions granted if the subject distinguished name of the client certificate matches."`
+	Remain             hcl.Body            `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (c *ClientCertificateAC) HCLBody() *hclsyntax.Body {
+	return c.Remain.(*hclsyntax.Body)
+}
+
+func (c *ClientCertificateAC) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (c *ClientCertificateAC) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(c)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(c.Inline())
+	return schema
+}
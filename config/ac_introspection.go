@@ -0,0 +1,103 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ BackendInitialization = &Introspection{}
+	_ Body                  = &Introspection{}
+	_ Inline                = &Introspection{}
+	_ OAuth2AS              = &Introspection{}
+	_ OAuth2Client          = &Introspection{}
+)
+
+// Introspection represents the "introspection" access control block for opaque tokens, see RFC 7662.
+type Introspection struct {
+	ErrorHandlerSetter
+	BackendName             string              `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for introspection requests. Mutually exclusive with {backend} block."`
+	ClientID                string              `hcl:"client_id" docs:"The client identifier."`
+	ClientSecret            string              `hcl:"client_secret,optional" docs:"The client password. Required unless {token_endpoint_auth_method} is {\"private_key_jwt\"}."`
+	Cookie                  string              `hcl:"cookie,optional" docs:"Read token value from a cookie. Cannot be used together with {header} or {token_value}."`
+	Header                  string              `hcl:"header,optional" docs:"Read token value from the given request header field. Implies {Bearer} if {Authorization} (case-insensitive) is used, otherwise any other header name can be used. Cannot be used together with {cookie} or {token_value}."`
+	IntrospectionEndpoint   string              `hcl:"introspection_endpoint" docs:"The authorization server endpoint URL used for token introspection."`
+	JWTSigningProfile       *JWTSigningProfile  `hcl:"jwt_signing_profile,block" docs:"Configures a [JWT signing profile](/configuration/block/jwt_signing_profile) to create a client assertion if {token_endpoint_auth_method} is either {\"client_secret_jwt\"} or {\"private_key_jwt\"} (zero or one)."`
+	Name                    string              `hcl:"name,label"`
+	PermissionsClaim        string              `hcl:"permissions_claim,optional" docs:"Name of the introspection response member containing the granted permissions. The member value must either be a string containing a space-separated list of permissions or a list of string permissions." default:"scope"`
+	PermissionsMap          map[string][]string `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from {permissions_claim}. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
+	PermissionsMapFile      string              `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
+	Remain                  hcl.Body            `hcl:",remain"`
+	TokenEndpointAuthMethod *string             `hcl:"token_endpoint_auth_method,optional" docs:"Defines the method to authenticate the client at the introspection endpoint. If set to {\"client_secret_post\"}, the client credentials are transported in the request body. If set to {\"client_secret_basic\"}, the client credentials are transported via Basic Authentication. If set to {\"client_secret_jwt\"}, the client is authenticated via a JWT signed with the {client_secret}. If set to {\"private_key_jwt\"}, the client is authenticated via a JWT signed with its private key (see {jwt_signing_profile} block)." default:"client_secret_basic"`
+	TokenValue              hcl.Expression      `hcl:"token_value,optional" docs:"Expression to obtain the token. Cannot be used together with {cookie} or {header}." type:"string"`
+	TTL                     string              `hcl:"ttl,optional" docs:"Time period the introspection result may be cached. Results for active tokens are not cached beyond their {exp} time. Set to {\"0s\"} to disable caching." type:"duration" default:"1m"`
+
+	// Internally used
+	Backend *hclsyntax.Body
+}
+
+func (i *Introspection) Prepare(backendFunc PrepareBackendFunc) (err error) {
+	i.Backend, err = backendFunc("introspection_endpoint", i.IntrospectionEndpoint, i)
+	return err
+}
+
+// Reference implements the <BackendReference> interface.
+func (i *Introspection) Reference() string {
+	return i.BackendName
+}
+
+// HCLBody implements the <Body> interface.
+func (i *Introspection) HCLBody() *hclsyntax.Body {
+	return i.Remain.(*hclsyntax.Body)
+}
+
+// Inline implements the <Inline> interface.
+func (i *Introspection) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+		Backend *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for introspection requests (zero or one). Mutually exclusive with {backend} attribute."`
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (i *Introspection) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(i)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(i.Inline())
+
+	return meta.MergeSchemas(schema, meta.LogFieldsAttributeSchema)
+}
+
+func (i *Introspection) ClientAuthenticationRequired() bool {
+	return true
+}
+
+func (i *Introspection) GetClientID() string {
+	return i.ClientID
+}
+
+func (i *Introspection) GetClientSecret() string {
+	return i.ClientSecret
+}
+
+func (i *Introspection) GetJWTSigningProfile() *JWTSigningProfile {
+	return i.JWTSigningProfile
+}
+
+// GetTokenEndpoint implements the <OAuth2AS> interface. The introspection
+// endpoint is the default audience of client assertions.
+func (i *Introspection) GetTokenEndpoint() (string, error) {
+	return i.IntrospectionEndpoint, nil
+}
+
+func (i *Introspection) GetTokenEndpointAuthMethod() *string {
+	return i.TokenEndpointAuthMethod
+}
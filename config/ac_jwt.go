@@ -26,34 +26,42 @@ type Claims hcl.Expression
 // JWT represents the <JWT> object.
 type JWT struct {
 	ErrorHandlerSetter
-	BackendName           string              `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for JWKS requests. Mutually exclusive with {backend} block."`
-	Bearer                bool                `hcl:"bearer,optional" docs:"If set to {true} the token is obtained from a {Authorization: Bearer ...} request header. Cannot be used together with {cookie}, {header} or {token_value}."`
+	BackendName           string              `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for JWKS and revocation list requests. Mutually exclusive with {backend} block."`
+	Bearer                bool                `hcl:"bearer,optional" docs:"If set to {true} the token is obtained from a {Authorization: Bearer ...} request header. Cannot be used together with {cookie}, {dpop}, {header} or {token_value}."`
+	CertificateBound      bool                `hcl:"certificate_bound,optional" docs:"If set to {true}, the token must be bound to the client certificate presented on the connection: its {cnf.x5t#S256} claim must match the SHA-256 thumbprint of the certificate, see [RFC 8705](https://datatracker.ietf.org/doc/html/rfc8705). Requires a [{client_certificate}](/configuration/block/client_certificate) block in the server {tls} block."`
 	Claims                Claims              `hcl:"claims,optional" docs:"Object with claims that must be given for a valid token (equals comparison with JWT payload). The claim values are evaluated per request."`
 	ClaimsRequired        []string            `hcl:"required_claims,optional" docs:"List of claim names that must be given for a valid token."`
-	Cookie                string              `hcl:"cookie,optional" docs:"Read token value from a cookie. Cannot be used together with {bearer}, {header} or {token_value}"`
+	Cookie                string              `hcl:"cookie,optional" docs:"Read token value from a cookie. Cannot be used together with {bearer}, {dpop}, {header} or {token_value}"`
+	DecryptionKey         string              `hcl:"decryption_key,optional" docs:"Private key (in PEM format) for the {RSA-OAEP}, {RSA-OAEP-256} and {ECDH-ES} key management algorithms or the shared key for {dir} to decrypt encrypted tokens (JWE) before their validation. If configured, only encrypted tokens are accepted. Mutually exclusive with {decryption_key_file}."`
+	DecryptionKeyFile     string              `hcl:"decryption_key_file,optional" docs:"Reference to file containing the decryption key. Mutually exclusive with {decryption_key}. See {decryption_key} for more information."`
 	DisablePrivateCaching bool                `hcl:"disable_private_caching,optional" docs:"If set to {true}, Couper does not add the {private} directive to the {Cache-Control} HTTP header field value."`
-	Header                string              `hcl:"header,optional" docs:"Read token value from the given request header field. Implies {Bearer} if {Authorization} (case-insensitive) is used (deprecated!), otherwise any other header name can be used. Cannot be used together with {bearer}, {cookie} or {token_value}."`
+	DPoP                  bool                `hcl:"dpop,optional" docs:"If set to {true} the token is obtained from a {Authorization: DPoP ...} request header and must be bound to the key of the [DPoP proof (RFC 9449)](https://datatracker.ietf.org/doc/html/rfc9449) sent in the {DPoP} request header (via the {cnf.jkt} claim). Cannot be used together with {bearer}, {cookie}, {header} or {token_value}."`
+	Header                string              `hcl:"header,optional" docs:"Read token value from the given request header field. Implies {Bearer} if {Authorization} (case-insensitive) is used (deprecated!), otherwise any other header name can be used. Cannot be used together with {bearer}, {cookie}, {dpop} or {token_value}."`
 	JWKsURL               string              `hcl:"jwks_url,optional" docs:"URI pointing to a set of [JSON Web Keys (RFC 7517)](https://datatracker.ietf.org/doc/html/rfc7517)"`
 	JWKsTTL               string              `hcl:"jwks_ttl,optional" docs:"Time period the JWK set stays valid and may be cached." type:"duration" default:"1h"`
 	JWKsMaxStale          string              `hcl:"jwks_max_stale,optional" docs:"Time period the cached JWK set stays valid after its TTL has passed." type:"duration" default:"1h"`
-	Key                   string              `hcl:"key,optional" docs:"Public key (in PEM format) for {RS*} and {ES*} variants or the secret for {HS*} algorithm. Mutually exclusive with {key_file}."`
+	Key                   string              `hcl:"key,optional" docs:"Public key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithm. Mutually exclusive with {key_file}."`
 	KeyFile               string              `hcl:"key_file,optional" docs:"Reference to file containing verification key. Mutually exclusive with {key}. See {key} for more information."`
 	Name                  string              `hcl:"name,label"`
 	Remain                hcl.Body            `hcl:",remain"`
+	RevocationURL         string              `hcl:"revocation_url,optional" docs:"URI pointing to a revocation list (JSON) with revoked {jti}, {sub} or {iat_before} values. Either a {file:} URI, a {store:} URI with the key of the list in the [cache store](settings) or an HTTP(S) URL requested via the {backend}."`
+	RevocationTTL         string              `hcl:"revocation_ttl,optional" docs:"Time period the revocation list stays valid and may be cached." type:"duration" default:"1m"`
+	RevocationMaxStale    string              `hcl:"revocation_max_stale,optional" docs:"Time period the cached revocation list stays valid after its TTL has passed." type:"duration" default:"1h"`
 	RolesClaim            string              `hcl:"roles_claim,optional" docs:"Name of claim specifying the roles of the user represented by the token. The claim value must either be a string containing a space-separated list of role values or a list of string role values."`
 	RolesMap              map[string][]string `hcl:"roles_map,optional" docs:"Mapping of roles to granted permissions. Non-mapped roles can be assigned with {*} to specific permissions. Mutually exclusive with {roles_map_file}."`
 	RolesMapFile          string              `hcl:"roles_map_file,optional" docs:"Reference to JSON file containing role mappings. Mutually exclusive with {roles_map}. See {roles_map} for more information."`
 	PermissionsClaim      string              `hcl:"permissions_claim,optional" docs:"Name of claim containing the granted permissions. The claim value must either be a string containing a space-separated list of permissions or a list of string permissions."`
 	PermissionsMap        map[string][]string `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from {permissions_claim} and those created from {roles_map}. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
 	PermissionsMapFile    string              `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
-	SignatureAlgorithm    string              `hcl:"signature_algorithm,optional" docs:"Valid values: {RS256}, {RS384}, {RS512}, {HS256}, {HS384}, {HS512}, {ES256}, {ES384}, {ES512}"`
-	SigningKey            string              `hcl:"signing_key,optional" docs:"Private key (in PEM format) for {RS*} and {ES*} variants. Mutually exclusive with {signing_key_file}."`
+	SignatureAlgorithm    string              `hcl:"signature_algorithm,optional" docs:"Valid values: {RS256}, {RS384}, {RS512}, {HS256}, {HS384}, {HS512}, {ES256}, {ES384}, {ES512}, {PS256}, {PS384}, {PS512}, {EdDSA}"`
+	SigningKey            string              `hcl:"signing_key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants. Mutually exclusive with {signing_key_file}."`
 	SigningKeyFile        string              `hcl:"signing_key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {signing_key}. See {signing_key} for more information."`
 	SigningTTL            string              `hcl:"signing_ttl,optional" docs:"The token's time-to-live (creates the {exp} claim)." type:"duration"`
-	TokenValue            hcl.Expression      `hcl:"token_value,optional" docs:"Expression to obtain the token. Cannot be used together with {bearer}, {cookie} or {header}." type:"string"`
+	TokenValue            hcl.Expression      `hcl:"token_value,optional" docs:"Expression to obtain the token. Cannot be used together with {bearer}, {cookie}, {dpop} or {header}." type:"string"`
 
 	// Internally used
-	Backend *hclsyntax.Body
+	Backend           *hclsyntax.Body
+	RevocationBackend *hclsyntax.Body
 }
 
 func (j *JWT) Prepare(backendFunc PrepareBackendFunc) (err error) {
@@ -64,6 +72,13 @@ func (j *JWT) Prepare(backendFunc PrepareBackendFunc) (err error) {
 		}
 	}
 
+	if j.RevocationURL != "" {
+		j.RevocationBackend, err = backendFunc("revocation_url", j.RevocationURL, j)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err = j.check(); err != nil {
 		return errors.Configuration.Label(j.Name).With(err)
 	}
@@ -84,7 +99,7 @@ func (j *JWT) HCLBody() *hclsyntax.Body {
 func (j *JWT) Inline() interface{} {
 	type Inline struct {
 		meta.LogFieldsAttribute
-		Backend *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for JWKS requests (zero or one). Mutually exclusive with {backend} attribute."`
+		Backend *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for JWKS and revocation list requests (zero or one). Mutually exclusive with {backend} attribute."`
 	}
 
 	return &Inline{}
@@ -107,12 +122,24 @@ func (j *JWT) check() error {
 		return fmt.Errorf("signature_algorithm or jwks_url attribute required")
 	}
 
-	if j.JWKsURL == "" && (j.BackendName != "" || j.Backend != nil) {
-		return fmt.Errorf("backend is obsolete without jwks_url attribute")
+	if j.JWKsURL == "" && j.RevocationURL == "" && (j.BackendName != "" || j.Backend != nil) {
+		return fmt.Errorf("backend is obsolete without jwks_url or revocation_url attribute")
 	} else if j.BackendName != "" && j.Backend == nil {
 		return fmt.Errorf("backend must be either a block or an attribute")
 	}
 
+	if j.DPoP {
+		var hasTokenValue bool
+		if j.TokenValue != nil {
+			tv, err := j.TokenValue.Value(nil)
+			hasTokenValue = err != nil || !tv.IsNull()
+		}
+
+		if j.Bearer || j.Cookie != "" || j.Header != "" || hasTokenValue {
+			return fmt.Errorf("dpop cannot be used together with bearer, cookie, header or token_value")
+		}
+	}
+
 	if j.JWKsURL != "" {
 		attributes := map[string]string{
 			"signature_algorithm": j.SignatureAlgorithm,
@@ -145,7 +172,10 @@ func (j *JWT) DefaultErrorHandlers() []*ErrorHandler {
 		return []*ErrorHandler{}
 	}
 	wwwAuthenticateValue := "Bearer"
-	return []*ErrorHandler{
+	if j.DPoP {
+		wwwAuthenticateValue = "DPoP"
+	}
+	errorHandlers := []*ErrorHandler{
 		{
 			Kinds: []string{"jwt_token_missing"},
 			Remain: body.NewHCLSyntaxBodyWithAttr("set_response_headers", seetie.MapToValue(map[string]interface{}{
@@ -164,5 +194,28 @@ func (j *JWT) DefaultErrorHandlers() []*ErrorHandler {
 				"Www-Authenticate": wwwAuthenticateValue + ` error="invalid_token", error_description="The access token expired"`,
 			}), hcl.Range{Filename: "default_jwt_error_handler"}),
 		},
+		{
+			Kinds: []string{"jwt_token_revoked"},
+			Remain: body.NewHCLSyntaxBodyWithAttr("set_response_headers", seetie.MapToValue(map[string]interface{}{
+				"Www-Authenticate": wwwAuthenticateValue + ` error="invalid_token", error_description="The access token has been revoked"`,
+			}), hcl.Range{Filename: "default_jwt_error_handler"}),
+		},
+	}
+	if j.CertificateBound {
+		errorHandlers = append(errorHandlers, &ErrorHandler{
+			Kinds: []string{"jwt_certificate_binding_invalid"},
+			Remain: body.NewHCLSyntaxBodyWithAttr("set_response_headers", seetie.MapToValue(map[string]interface{}{
+				"Www-Authenticate": wwwAuthenticateValue + ` error="invalid_token", error_description="The access token is not bound to the client certificate"`,
+			}), hcl.Range{Filename: "default_jwt_error_handler"}),
+		})
+	}
+	if j.DPoP {
+		errorHandlers = append(errorHandlers, &ErrorHandler{
+			Kinds: []string{"jwt_dpop_proof_invalid"},
+			Remain: body.NewHCLSyntaxBodyWithAttr("set_response_headers", seetie.MapToValue(map[string]interface{}{
+				"Www-Authenticate": wwwAuthenticateValue + ` error="invalid_dpop_proof"`,
+			}), hcl.Range{Filename: "default_jwt_error_handler"}),
+		})
 	}
+	return errorHandlers
 }
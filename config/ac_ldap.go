@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/body"
+	"github.com/coupergateway/couper/config/meta"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+var (
+	_ Body   = &LDAP{}
+	_ Inline = &LDAP{}
+)
+
+// LDAP represents the "ldap" config block
+type LDAP struct {
+	ErrorHandlerSetter
+	BaseDN                       string              `hcl:"base_dn,optional" docs:"The search base for {user_filter}. Required with {user_filter}."`
+	BindDN                       string              `hcl:"bind_dn,optional" docs:"The DN of the service account used for the search with {user_filter}."`
+	BindPassword                 string              `hcl:"bind_password,optional" docs:"The password of the service account."`
+	CacheTTL                     string              `hcl:"cache_ttl,optional" docs:"How long successful binds are cached. Cached credentials are not checked against the directory again. {\"0s\"} disables the cache." type:"duration" default:"1m"`
+	CAFile                       string              `hcl:"ca_file,optional" docs:"Reference to a PEM file with CA certificates to verify the certificate of the LDAP server."`
+	DisableCertificateValidation bool                `hcl:"disable_certificate_validation,optional" docs:"Disables the validation of the certificate of the LDAP server."`
+	GroupAttribute               string              `hcl:"group_attribute,optional" docs:"The attribute of the user entry containing the DNs of the groups the user is a member of." default:"memberOf"`
+	GroupPermissions             map[string][]string `hcl:"group_permissions,optional" docs:"Mapping of [patterns](#patterns) to permissions granted if one of the group DNs of the user matches."`
+	Name                         string              `hcl:"name,label"`
+	PermissionsMap               map[string][]string `hcl:"permissions_map,optional" docs:"Mapping of granted permissions to additional granted permissions. Maps values from {group_permissions}. The map is called recursively. Mutually exclusive with {permissions_map_file}."`
+	PermissionsMapFile           string              `hcl:"permissions_map_file,optional" docs:"Reference to JSON file containing permission mappings. Mutually exclusive with {permissions_map}. See {permissions_map} for more information."`
+	Realm                        string              `hcl:"realm,optional" docs:"The realm to be sent in a WWW-Authenticate response HTTP header field."`
+	Remain                       hcl.Body            `hcl:",remain"`
+	StartTLS                     bool                `hcl:"start_tls,optional" docs:"Whether to upgrade an {ldap://} connection with StartTLS."`
+	Timeout                      string              `hcl:"timeout,optional" docs:"The total deadline duration for connecting to and requesting the LDAP server." type:"duration" default:"10s"`
+	URL                          string              `hcl:"url" docs:"The URL of the LDAP server, e.g. {\"ldaps://ldap.example.com\"} or {\"ldap://ldap.example.com:389\"}."`
+	UserDN                       string              `hcl:"user_dn,optional" docs:"The template of the user DN to bind with directly. Mutually exclusive with {user_filter}."`
+	UserFilter                   string              `hcl:"user_filter,optional" docs:"The template of the filter to search the user DN below {base_dn} before binding with it (search-then-bind). Mutually exclusive with {user_dn}."`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (l *LDAP) HCLBody() *hclsyntax.Body {
+	return l.Remain.(*hclsyntax.Body)
+}
+
+func (l *LDAP) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (l *LDAP) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(l)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(l.Inline())
+	return schema
+}
+
+func (l *LDAP) DefaultErrorHandlers() []*ErrorHandler {
+	wwwAuthenticateValue := "Basic"
+	if l.Realm != "" {
+		wwwAuthenticateValue += fmt.Sprintf(" realm=%q", l.Realm)
+	}
+	return []*ErrorHandler{
+		{
+			Kinds: []string{"ldap"},
+			Remain: body.NewHCLSyntaxBodyWithAttr("set_response_headers", seetie.MapToValue(map[string]interface{}{
+				"Www-Authenticate": wwwAuthenticateValue,
+			}), hcl.Range{Filename: "default_ldap_error_handler"}),
+		},
+	}
+}
@@ -30,6 +30,7 @@ type OIDC struct {
 	JWKsMaxStale            string             `hcl:"jwks_max_stale,optional" docs:"Time period the cached JWK set stays valid after its TTL has passed." type:"duration" default:"1h"`
 	JWTSigningProfile       *JWTSigningProfile `hcl:"jwt_signing_profile,block" docs:"Configures a [JWT signing profile](/configuration/block/jwt_signing_profile) to create a client assertion if {token_endpoint_auth_method} is either {\"client_secret_jwt\"} or {\"private_key_jwt\"}."`
 	Name                    string             `hcl:"name,label"`
+	PostLogoutRedirectURI   string             `hcl:"post_logout_redirect_uri,optional" docs:"The URL the OpenID provider redirects to after a logout with the [{oidc_logout_url()} function](/configuration/functions). Relative URL references are resolved against the origin of the current request URL."`
 	Remain                  hcl.Body           `hcl:",remain"`
 	RedirectURI             string             `hcl:"redirect_uri" docs:"The Couper endpoint for receiving the authorization code. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
 	Scope                   string             `hcl:"scope,optional" docs:"A space separated list of requested scope values for the access token."`
@@ -117,6 +118,10 @@ func (o *OIDC) GetGrantType() string {
 	return "authorization_code"
 }
 
+func (o *OIDC) GetPostLogoutRedirectURI() string {
+	return o.PostLogoutRedirectURI
+}
+
 func (o *OIDC) GetRedirectURI() string {
 	return o.RedirectURI
 }
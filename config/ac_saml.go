@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/tls"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -16,15 +18,21 @@ var (
 // SAML represents the <SAML> object.
 type SAML struct {
 	ErrorHandlerSetter
-	ArrayAttributes []string `hcl:"array_attributes,optional" docs:"A list of assertion attributes that may have several values. Results in at least an empty array in {request.context.<label>.attributes.<name>}"`
-	IdpMetadataFile string   `hcl:"idp_metadata_file" docs:"File reference to the Identity Provider metadata XML file."`
-	Name            string   `hcl:"name,label"`
-	Remain          hcl.Body `hcl:",remain"`
-	SpAcsURL        string   `hcl:"sp_acs_url" docs:"The URL of the Service Provider's ACS endpoint. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
-	SpEntityID      string   `hcl:"sp_entity_id" docs:"The Service Provider's entity ID."`
+	ArrayAttributes   []string `hcl:"array_attributes,optional" docs:"A list of assertion attributes that may have several values. Results in at least an empty array in {request.context.<label>.attributes.<name>}"`
+	IdpMetadataFile   string   `hcl:"idp_metadata_file" docs:"File reference to the Identity Provider metadata XML file."`
+	Name              string   `hcl:"name,label"`
+	Remain            hcl.Body `hcl:",remain"`
+	SpAcsURL          string   `hcl:"sp_acs_url" docs:"The URL of the Service Provider's ACS endpoint. Relative URL references are resolved against the origin of the current request URL. The origin can be changed with the [{accept_forwarded_url} attribute](settings) if Couper is running behind a proxy."`
+	SpCertificate     string   `hcl:"sp_certificate,optional" docs:"The PEM encoded certificate of the Service Provider. Mutually exclusive with {sp_certificate_file}."`
+	SpCertificateFile string   `hcl:"sp_certificate_file,optional" docs:"Reference to a PEM file with the certificate of the Service Provider. Mutually exclusive with {sp_certificate}."`
+	SpEntityID        string   `hcl:"sp_entity_id" docs:"The Service Provider's entity ID."`
+	SpPrivateKey      string   `hcl:"sp_private_key,optional" docs:"The PEM encoded RSA private key of the Service Provider. Used to sign requests and to decrypt encrypted assertions. Mutually exclusive with {sp_private_key_file}."`
+	SpPrivateKeyFile  string   `hcl:"sp_private_key_file,optional" docs:"Reference to a PEM file with the RSA private key of the Service Provider. Mutually exclusive with {sp_private_key}."`
+	SpSloURL          string   `hcl:"sp_slo_url,optional" docs:"The URL of the Service Provider's single logout endpoint. Relative URL references are resolved against the origin of the current request URL."`
 
 	// internally used
 	MetadataBytes []byte
+	SpKeyPair     *tls.Certificate
 }
 
 // HCLBody implements the <Body> interface. Internally used for 'error_handler'.
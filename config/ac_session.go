@@ -0,0 +1,61 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+const (
+	SessionStoreCache  = "cache"
+	SessionStoreCookie = "cookie"
+)
+
+var (
+	_ Body   = &Session{}
+	_ Inline = &Session{}
+)
+
+// Session represents the "session" config block for login sessions created by an
+// authorization code flow access control.
+type Session struct {
+	ErrorHandlerSetter
+	BackchannelLogoutPath        string   `hcl:"backchannel_logout_path,optional" docs:"The request path receiving back-channel logout requests of the OpenID provider. Requires an [OIDC](/configuration/block/oidc) access control referenced by {oauth2}."`
+	Cookie                       string   `hcl:"cookie,optional" docs:"The name of the session cookie." default:"couper_session"`
+	DisableAccessTokenForwarding bool     `hcl:"disable_access_token_forwarding,optional" docs:"If set to {true}, the access token is not set as {Authorization: Bearer ...} request header field in [proxy](/configuration/block/proxy) requests."`
+	DisableSecureCookie          bool     `hcl:"disable_secure_cookie,optional" docs:"If set to {true}, the session cookie is set without the {Secure} flag, e.g. for local development via plain HTTP."`
+	EncryptionKey                string   `hcl:"encryption_key,optional" docs:"The key (32 bytes) to encrypt the session data stored in the session cookie with {store = \"cookie\"}. Mutually exclusive with {encryption_key_file}."`
+	EncryptionKeyFile            string   `hcl:"encryption_key_file,optional" docs:"Reference to file containing the encryption key. Mutually exclusive with {encryption_key}. See {encryption_key} for more information."`
+	LogoutPath                   string   `hcl:"logout_path,optional" docs:"The request path ending the session. {POST} requests to this path remove the session and the session cookie. They are granted access even without a valid session."`
+	Name                         string   `hcl:"name,label"`
+	OAuth2                       string   `hcl:"oauth2" docs:"References the [OIDC](/configuration/block/oidc) or [OAuth2](/configuration/block/beta_oauth2) access control creating the session on a successful redirect endpoint request. Its token endpoint is used to refresh expired access tokens."`
+	Remain                       hcl.Body `hcl:",remain"`
+	Store                        string   `hcl:"store,optional" docs:"Where to store the session data: {\"cache\"} (the [cache store](/configuration/command-line#cache-store-options) of Couper, the session cookie contains a random session ID) or {\"cookie\"} (the encrypted session cookie)." default:"cache"`
+	TTL                          string   `hcl:"ttl,optional" docs:"The maximum lifetime of a session." type:"duration" default:"8h"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (s *Session) HCLBody() *hclsyntax.Body {
+	return s.Remain.(*hclsyntax.Body)
+}
+
+func (s *Session) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (s *Session) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(s)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(s.Inline())
+	return schema
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &Signature{}
+	_ Inline = &Signature{}
+)
+
+// Signature represents the "signature" config block
+type Signature struct {
+	ErrorHandlerSetter
+	Algorithm          string   `hcl:"algorithm,optional" docs:"The hash function of the HMAC. Valid values: {\"sha1\"}, {\"sha256\"}, {\"sha512\"}." default:"sha256"`
+	Encoding           string   `hcl:"encoding,optional" docs:"The encoding of the signature in the request header field. Valid values: {\"hex\"}, {\"base64\"}, {\"base64url\"}." default:"hex"`
+	Header             string   `hcl:"header" docs:"The name of the request header field containing the signature."`
+	Key                string   `hcl:"key,optional" docs:"The secret key of the HMAC. Mutually exclusive with {key_file}."`
+	KeyFile            string   `hcl:"key_file,optional" docs:"Reference to a file containing the secret key. Mutually exclusive with {key}."`
+	Name               string   `hcl:"name,label"`
+	Prefix             string   `hcl:"prefix,optional" docs:"A prefix of the header field value to be removed before decoding the signature, e.g. {\"sha256=\"}."`
+	Remain             hcl.Body `hcl:",remain"`
+	StringToSign       string   `hcl:"string_to_sign,optional" docs:"The template of the signed content. See [String to Sign](#string-to-sign)." default:"{body}"`
+	TimestampHeader    string   `hcl:"timestamp_header,optional" docs:"The name of the request header field containing the time of signing as Unix time in seconds. Must be set if, and only if, the template refers to the timestamp."`
+	TimestampTolerance string   `hcl:"timestamp_tolerance,optional" docs:"The maximum difference between the time of signing and the current time. Only used with {timestamp_header}." type:"duration" default:"5m"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (s *Signature) HCLBody() *hclsyntax.Body {
+	return s.Remain.(*hclsyntax.Body)
+}
+
+func (s *Signature) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (s *Signature) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(s)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(s.Inline())
+	return schema
+}
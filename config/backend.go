@@ -16,16 +16,22 @@ var (
 
 // Backend represents the <Backend> object.
 type Backend struct {
-	DisableCertValidation  bool        `hcl:"disable_certificate_validation,optional" docs:"Disables the peer certificate validation. Must not be used in backend refinement."`
-	DisableConnectionReuse bool        `hcl:"disable_connection_reuse,optional" docs:"Disables reusage of connections to the origin. Must not be used in backend refinement."`
-	Health                 *Health     `hcl:"beta_health,block" docs:"Configures a [health check](/configuration/block/health) (zero or one)."`
-	HTTP2                  bool        `hcl:"http2,optional" docs:"Enables the HTTP2 support. Must not be used in backend refinement."`
-	MaxConnections         int         `hcl:"max_connections,optional" docs:"The maximum number of concurrent connections in any state (_active_ or _idle_) to the origin. Must not be used in backend refinement." default:"0"`
-	Name                   string      `hcl:"name,label,optional"`
-	OpenAPI                *OpenAPI    `hcl:"openapi,block" docs:"Configures [OpenAPI validation](/configuration/block/openapi) (zero or one)."`
-	RateLimits             RateLimits  `hcl:"beta_rate_limit,block" docs:"Configures [rate limiting](/configuration/block/rate_limit) (zero or one)."`
-	Remain                 hcl.Body    `hcl:",remain"`
-	TLS                    *BackendTLS `hcl:"tls,block" docs:"Configures [backend TLS](/configuration/block/backend_tls) (zero or one)."`
+	CircuitBreaker         *CircuitBreaker `hcl:"circuit_breaker,block" docs:"Configures a [circuit breaker](/configuration/block/circuit_breaker) (zero or one)."`
+	DisableCertValidation  bool            `hcl:"disable_certificate_validation,optional" docs:"Disables the peer certificate validation. Must not be used in backend refinement."`
+	DisableConnectionReuse bool            `hcl:"disable_connection_reuse,optional" docs:"Disables reusage of connections to the origin. Must not be used in backend refinement."`
+	Fallback               *Fallback       `hcl:"fallback,block" docs:"Configures a [fallback](/configuration/block/fallback) backend for failed requests (zero or one)."`
+	Health                 *Health         `hcl:"beta_health,block" docs:"Configures a [health check](/configuration/block/health) (zero or one)."`
+	HTTP2                  bool            `hcl:"http2,optional" docs:"Enables the HTTP2 support. Origins with the {http} scheme are connected via h2c (HTTP/2 without TLS). Must not be used in backend refinement."`
+	LoadBalancing          string          `hcl:"load_balancing,optional" docs:"Strategy to select one of multiple origins: {\"round_robin\"}, {\"weighted\"}, {\"least_connections\"} or {\"consistent_hash\"}. Must not be used in backend refinement." default:"round_robin"`
+	MaxConnections         int             `hcl:"max_connections,optional" docs:"The maximum number of concurrent connections in any state (_active_ or _idle_) to the origin. Requests to h2c origins (see {http2}) are multiplexed over at most {max_connections} connections and wait for a free stream if all are busy. Must not be used in backend refinement." default:"0"`
+	Name                   string          `hcl:"name,label,optional"`
+	OpenAPI                *OpenAPI        `hcl:"openapi,block" docs:"Configures [OpenAPI validation](/configuration/block/openapi) (zero or one)."`
+	RateLimits             RateLimits      `hcl:"beta_rate_limit,block" docs:"Configures [rate limiting](/configuration/block/rate_limit) (zero or one)."`
+	Remain                 hcl.Body        `hcl:",remain"`
+	ResponseCache          *ResponseCache  `hcl:"response_cache,block" docs:"Configures a [response cache](/configuration/block/response_cache) (zero or one)."`
+	Retry                  *Retry          `hcl:"retry,block" docs:"Configures [retries](/configuration/block/retry) of failed backend requests (zero or one)."`
+	TLS                    *BackendTLS     `hcl:"tls,block" docs:"Configures [backend TLS](/configuration/block/backend_tls) (zero or one)."`
+	Upstreams              []*Upstream     `hcl:"upstream,block" docs:"Configures an [upstream](/configuration/block/upstream) origin to balance requests across (zero or more). Mutually exclusive with {origin} and {origins}."`
 
 	// used for validation and documentation
 	OAuth2       *OAuth2ReqAuth  `hcl:"oauth2,block" docs:"Configures an [OAuth2 authorization](/configuration/block/oauth2) (zero or one)."`
@@ -49,18 +55,22 @@ func (b Backend) Inline() interface{} {
 		meta.ResponseHeadersAttributes
 		meta.FormParamsAttributes
 		meta.QueryParamsAttributes
+		meta.RequestJSONFieldsAttributes
+		meta.ResponseJSONFieldsAttributes
 		meta.LogFieldsAttribute
-		BasicAuth      string `hcl:"basic_auth,optional" docs:"Basic auth for the upstream request with format {user:pass}."`
-		ConnectTimeout string `hcl:"connect_timeout,optional" docs:"The total timeout for dialing and connect to the origin." type:"duration" default:"10s"`
-		Hostname       string `hcl:"hostname,optional" docs:"Value of the HTTP host header field for the origin request. Since hostname replaces the request host the value will also be used for a server identity check during a TLS handshake with the origin."`
-		Origin         string `hcl:"origin,optional" docs:"URL to connect to for backend requests."`
-		Path           string `hcl:"path,optional" docs:"Changeable part of upstream URL."`
-		PathPrefix     string `hcl:"path_prefix,optional" docs:"Prefixes all backend request paths with the given prefix."`
-		ProxyURL       string `hcl:"proxy,optional" docs:"A proxy URL for the related origin request."`
-		ResponseStatus *uint8 `hcl:"set_response_status,optional" docs:"Modifies the response status code."`
-		TTFBTimeout    string `hcl:"ttfb_timeout,optional" docs:"The duration from writing the full request to the origin and receiving the answer." type:"duration" default:"60s"`
-		Timeout        string `hcl:"timeout,optional" docs:"The total deadline duration a backend request has for write and read/pipe." type:"duration" default:"300s"`
-		UseUnhealthy   bool   `hcl:"use_when_unhealthy,optional" docs:"Ignores the health state and continues with the outgoing request."`
+		BasicAuth      string   `hcl:"basic_auth,optional" docs:"Basic auth for the upstream request with format {user:pass}."`
+		ConnectTimeout string   `hcl:"connect_timeout,optional" docs:"The total timeout for dialing and connect to the origin." type:"duration" default:"10s"`
+		HashKey        string   `hcl:"hash_key,optional" docs:"Expression whose value selects the origin for the {\"consistent_hash\"} load balancing strategy, e.g. {request.headers.x-user-id}. Requests with an empty value are balanced round-robin."`
+		Hostname       string   `hcl:"hostname,optional" docs:"Value of the HTTP host header field for the origin request. Since hostname replaces the request host the value will also be used for a server identity check during a TLS handshake with the origin."`
+		Origin         string   `hcl:"origin,optional" docs:"URL to connect to for backend requests."`
+		Origins        []string `hcl:"origins,optional" docs:"List of URLs to balance backend requests across. Mutually exclusive with {origin}. Must not be used in backend refinement."`
+		Path           string   `hcl:"path,optional" docs:"Changeable part of upstream URL."`
+		PathPrefix     string   `hcl:"path_prefix,optional" docs:"Prefixes all backend request paths with the given prefix."`
+		ProxyURL       string   `hcl:"proxy,optional" docs:"A proxy URL for the related origin request."`
+		ResponseStatus *uint8   `hcl:"set_response_status,optional" docs:"Modifies the response status code."`
+		TTFBTimeout    string   `hcl:"ttfb_timeout,optional" docs:"The duration from writing the full request to the origin and receiving the answer." type:"duration" default:"60s"`
+		Timeout        string   `hcl:"timeout,optional" docs:"The total deadline duration a backend request has for write and read/pipe." type:"duration" default:"300s"`
+		UseUnhealthy   bool     `hcl:"use_when_unhealthy,optional" docs:"Ignores the health state and continues with the outgoing request."`
 
 		// set by backend preparation
 		BackendURL string `hcl:"backend_url,optional"`
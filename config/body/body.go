@@ -36,6 +36,18 @@ func MergeBodies(dest, src *hclsyntax.Body, replace bool) *hclsyntax.Body {
 	return dest
 }
 
+// Copy returns a shallow copy of the given body with its own attributes and blocks
+// so that it can be used as merge destination without modifying the given body.
+func Copy(body *hclsyntax.Body) *hclsyntax.Body {
+	copied := *body
+	copied.Attributes = make(hclsyntax.Attributes, len(body.Attributes))
+	for k, v := range body.Attributes {
+		copied.Attributes[k] = v
+	}
+	copied.Blocks = append(hclsyntax.Blocks{}, body.Blocks...)
+	return &copied
+}
+
 func BlocksOfType(body *hclsyntax.Body, blockType string) []*hclsyntax.Block {
 	var blocks []*hclsyntax.Block
 	for _, bl := range body.Blocks {
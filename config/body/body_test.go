@@ -125,3 +125,32 @@ func TestBody_MergeBodies(t *testing.T) {
 		})
 	}
 }
+
+func TestBody_Copy(t *testing.T) {
+	orig := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{
+			"a": &hclsyntax.Attribute{Name: "a", Expr: &hclsyntax.LiteralValueExpr{Val: cty.StringVal("a")}},
+		},
+		Blocks: hclsyntax.Blocks{
+			&hclsyntax.Block{Type: "a", Labels: []string{}, Body: &hclsyntax.Body{}},
+		},
+	}
+
+	src := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{
+			"b": &hclsyntax.Attribute{Name: "b", Expr: &hclsyntax.LiteralValueExpr{Val: cty.StringVal("b")}},
+		},
+		Blocks: hclsyntax.Blocks{
+			&hclsyntax.Block{Type: "b", Labels: []string{}, Body: &hclsyntax.Body{}},
+		},
+	}
+
+	merged := body.MergeBodies(body.Copy(orig), src, false)
+	if len(merged.Attributes) != 2 || len(merged.Blocks) != 2 {
+		t.Errorf("expected 2 attributes and 2 blocks, got: %d, %d", len(merged.Attributes), len(merged.Blocks))
+	}
+
+	if len(orig.Attributes) != 1 || len(orig.Blocks) != 1 {
+		t.Errorf("expected an unmodified body, got: %d attributes, %d blocks", len(orig.Attributes), len(orig.Blocks))
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+// CircuitBreaker represents the <config.CircuitBreaker> object.
+type CircuitBreaker struct {
+	CoolDown         string  `hcl:"cool_down,optional" docs:"Duration the circuit stays open before a single trial request is passed to the backend (half-open state)." type:"duration" default:"30s"`
+	ErrorRate        float64 `hcl:"error_rate,optional" docs:"Ratio of failed requests within the {window} which opens the circuit, e.g. {0.5}. Disabled if {0}." default:"0"`
+	FailureThreshold *uint   `hcl:"failure_threshold,optional" docs:"Number of consecutive failed requests which opens the circuit. Disabled if {0}." default:"5"`
+	MinRequests      *uint   `hcl:"min_requests,optional" docs:"Minimum number of requests within the {window} before the {error_rate} is evaluated." default:"10"`
+	OnStatus         []int   `hcl:"on_status,optional" docs:"Backend response status codes which are counted as failures. Connection errors and timeouts are always counted as failures." default:"[500, 502, 503, 504]"`
+	Window           string  `hcl:"window,optional" docs:"Time window for the {error_rate}." type:"duration" default:"10s"`
+}
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// ClientRateLimit represents the <config.ClientRateLimit> object.
+type ClientRateLimit struct {
+	Key          hcl.Expression `hcl:"key,optional" docs:"Expression to obtain the key requests are counted for, e.g. {request.headers.x-api-key} or {request.context.<jwt_name>.sub}. If the key evaluates to {null} or an empty string, the client IP address is used." type:"string"`
+	Period       string         `hcl:"period" docs:"Defines the rate limit period." type:"duration"`
+	PerPeriod    uint           `hcl:"per_period" docs:"Defines the number of allowed client requests per key in a period."`
+	PeriodWindow string         `hcl:"period_window,optional" default:"sliding" docs:"Defines the window of the period. A {fixed} window permits {per_period} requests within {period} after the first request of a key. After the {period} has expired, another {per_period} requests are permitted. The sliding window ensures that only {per_period} requests are permitted in any interval of length period."`
+}
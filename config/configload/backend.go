@@ -100,7 +100,7 @@ func PrepareBackend(helper *helper, attrName, attrValue string, block config.Bod
 			backendBody = newDefaultBackend()
 		} else {
 			switch labelSuffix {
-			case "_jwks_uri_backend", "_token_backend", "_userinfo_backend":
+			case "_jwks_uri_backend", "_revocation_url", "_token_backend", "_userinfo_backend":
 				copied := *backendBody
 				// create new Attributes to allow different name later
 				copied.Attributes = make(map[string]*hclsyntax.Attribute, len(backendBody.Attributes))
@@ -124,7 +124,13 @@ func PrepareBackend(helper *helper, attrName, attrValue string, block config.Bod
 	}
 
 	// watch out for beta_token_request blocks and nested backend definitions
-	return setTokenRequestBackend(helper, backendBody)
+	backendBody, err = setTokenRequestBackend(helper, backendBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// watch out for a fallback block and nested backend definitions
+	return setFallbackBackend(helper, backendBody)
 }
 
 // getBackendReference reads a referenced backend name and the refined backend block content if any.
@@ -251,6 +257,42 @@ func setTokenRequestBackend(helper *helper, parent *hclsyntax.Body) (*hclsyntax.
 	return parent, nil
 }
 
+// setFallbackBackend prepares a nested backend within a backend-fallback block.
+func setFallbackBackend(helper *helper, parent *hclsyntax.Body) (*hclsyntax.Body, error) {
+	fallbackBlocks := hclbody.BlocksOfType(parent, fallback)
+	if len(fallbackBlocks) == 0 {
+		return parent, nil
+	}
+
+	fallbackBody := fallbackBlocks[0].Body
+	conf := &config.Fallback{}
+	if diags := gohcl.DecodeBody(fallbackBody, helper.context, conf); diags.HasErrors() {
+		return nil, diags
+	}
+
+	backendBlocks := hclbody.BlocksOfType(fallbackBody, backend)
+	if conf.BackendName == "" && len(backendBlocks) == 0 {
+		r := fallbackBlocks[0].DefRange()
+		return nil, newDiagErr(&r, "fallback: missing backend attribute or block")
+	}
+
+	backendBody, err := PrepareBackend(helper, "", "", conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backendBlocks) == 0 {
+		// only add backend block, if not already there
+		backendBlock := &hclsyntax.Block{
+			Type: backend,
+			Body: backendBody,
+		}
+		fallbackBody.Blocks = append(fallbackBody.Blocks, backendBlock)
+	}
+
+	return parent, nil
+}
+
 func setName(nameValue string, backendBody *hclsyntax.Body) {
 	backendBody.Attributes["name"] = &hclsyntax.Attribute{
 		Name: "name",
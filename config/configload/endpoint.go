@@ -104,6 +104,15 @@ func refineEndpoints(helper *helper, endpoints config.Endpoints, checkPathPatter
 				}
 			}
 
+			sseBody, sseErr := getSSEConfig(proxyConfig)
+			if sseErr != nil {
+				return sseErr
+			}
+
+			if sseBody != nil {
+				proxyConfig.Remain = hclbody.MergeBodies(proxyConfig.HCLBody(), sseBody, true)
+			}
+
 			proxyConfig.Backend, err = PrepareBackend(helper, "", "", proxyConfig)
 			if err != nil {
 				return err
@@ -190,3 +199,22 @@ func getWebsocketsConfig(proxyConfig *config.Proxy) (bool, *hclsyntax.Body, erro
 
 	return hasWebsocketBlocks, nil, nil
 }
+
+func getSSEConfig(proxyConfig *config.Proxy) (*hclsyntax.Body, error) {
+	hasSSEBlocks := len(hclbody.BlocksOfType(proxyConfig.HCLBody(), "sse")) > 0
+	if proxyConfig.SSE != nil && hasSSEBlocks {
+		hr := proxyConfig.HCLBody().Attributes["sse"].SrcRange
+		return nil, newDiagErr(&hr, "either sse attribute or block is allowed")
+	}
+
+	if proxyConfig.SSE == nil || !*proxyConfig.SSE {
+		return nil, nil
+	}
+
+	block := &hclsyntax.Block{
+		Type: "sse",
+		Body: &hclsyntax.Body{},
+	}
+
+	return &hclsyntax.Body{Blocks: []*hclsyntax.Block{block}}, nil
+}
@@ -85,6 +85,9 @@ func (h *helper) configureDefinedBackends() error {
 
 func (h *helper) configureACBackends() error {
 	var acs []config.BackendInitialization
+	for _, ac := range h.config.Definitions.Introspection {
+		acs = append(acs, ac)
+	}
 	for _, ac := range h.config.Definitions.JWT {
 		acs = append(acs, ac)
 	}
@@ -175,6 +178,25 @@ func (h *helper) configureSAML() *errors.Error {
 		}
 
 		saml.MetadataBytes = metadata
+
+		if saml.SpCertificate == "" && saml.SpCertificateFile == "" && saml.SpPrivateKey == "" && saml.SpPrivateKeyFile == "" {
+			continue
+		}
+
+		certificate, err := reader.ReadFromAttrFile("saml2 sp_certificate", saml.SpCertificate, saml.SpCertificateFile)
+		if err != nil {
+			return errors.Configuration.Label(saml.Name).With(err)
+		}
+
+		privateKey, err := reader.ReadFromAttrFile("saml2 sp_private_key", saml.SpPrivateKey, saml.SpPrivateKeyFile)
+		if err != nil {
+			return errors.Configuration.Label(saml.Name).With(err)
+		}
+
+		saml.SpKeyPair, err = lib.NewSAMLKeyPair(certificate, privateKey)
+		if err != nil {
+			return errors.Configuration.Label(saml.Name).With(err)
+		}
 	}
 
 	return nil
@@ -424,6 +446,8 @@ func (h *helper) collectBackendDeps(refs map[string][]string) {
 		h.collectFromBlocks(oaBlocks, name, refs)
 		trBlocks := hclbody.BlocksOfType(b, tokenRequest)
 		h.collectFromBlocks(trBlocks, name, refs)
+		fbBlocks := hclbody.BlocksOfType(b, fallback)
+		h.collectFromBlocks(fbBlocks, name, refs)
 	}
 }
 
@@ -447,7 +471,7 @@ func (h *helper) collectFromBlocks(authorizerBlocks hclsyntax.Blocks, name strin
 
 			for _, subBlock := range block.Body.Blocks {
 				switch subBlock.Type {
-				case oauth2, tokenRequest:
+				case fallback, oauth2, tokenRequest:
 					h.collectBackendDeps(refs)
 				}
 			}
@@ -459,12 +483,27 @@ func (h *helper) getDefinedACs() map[string]struct{} {
 	definitions := h.config.Definitions
 	definedACs := make(map[string]struct{})
 
+	for _, ac := range definitions.APIKey {
+		definedACs[ac.Name] = struct{}{}
+	}
+	for _, ac := range definitions.Authorization {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range definitions.BasicAuth {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range definitions.ClientCertificate {
+		definedACs[ac.Name] = struct{}{}
+	}
+	for _, ac := range definitions.Introspection {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range definitions.JWT {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range definitions.LDAP {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range definitions.OAuth2AC {
 		definedACs[ac.Name] = struct{}{}
 	}
@@ -474,6 +513,12 @@ func (h *helper) getDefinedACs() map[string]struct{} {
 	for _, ac := range definitions.SAML {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range definitions.Session {
+		definedACs[ac.Name] = struct{}{}
+	}
+	for _, ac := range definitions.Signature {
+		definedACs[ac.Name] = struct{}{}
+	}
 
 	return definedACs
 }
@@ -26,6 +26,7 @@ const (
 	environment     = "environment"
 	environmentVars = "environment_variables"
 	errorHandler    = "error_handler"
+	fallback        = "fallback"
 	files           = "files"
 	nameLabel       = "name"
 	oauth2          = "oauth2"
@@ -50,20 +51,26 @@ func init() {
 		"ca_file",
 		"client_certificate_file",
 		"client_private_key_file",
+		"decryption_key_file",
 		"document_root",
+		"encryption_key_file",
 		"error_file",
 		"file",
 		"htpasswd_file",
 		"idp_metadata_file",
 		"jwks_url",
 		"key_file",
+		"keys_file",
 		"leaf_certificate_file",
 		"permissions_map_file",
 		"private_key_file",
 		"public_key_file",
+		"revocation_url",
 		"roles_map_file",
 		"server_ca_certificate_file",
 		"signing_key_file",
+		"sp_certificate_file",
+		"sp_private_key_file",
 	}
 
 	pathBearingAttributesMap = make(map[string]struct{})
@@ -200,8 +207,9 @@ func absolutizePaths(fileBody *hclsyntax.Body) ([]configfile.File, error) {
 		filePath := value.AsString()
 		basePath := attribute.SrcRange.Filename
 		var absolutePath string
-		if attribute.Name == "jwks_url" {
-			if strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://") {
+		if attribute.Name == "jwks_url" || attribute.Name == "revocation_url" {
+			if strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://") ||
+				strings.HasPrefix(filePath, "store:") {
 				return nil
 			}
 
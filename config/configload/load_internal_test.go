@@ -0,0 +1,44 @@
+package configload
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func Test_absolutizePaths_SAML(t *testing.T) {
+	src := []byte(`
+saml "sso" {
+  idp_metadata_file   = "idp.xml"
+  sp_certificate_file = "sp.crt"
+  sp_private_key_file = "/etc/sp.key"
+}`)
+	configFile := filepath.Join("conf", "couper.hcl")
+	file, diags := hclsyntax.ParseConfig(src, configFile, hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	watchFiles, err := absolutizePaths(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watchFiles) != 3 {
+		t.Errorf("expected 3 watch files, got %v", watchFiles)
+	}
+
+	attributes := body.Blocks[0].Body.Attributes
+	for name, expected := range map[string]string{
+		"idp_metadata_file":   filepath.Join("conf", "idp.xml"),
+		"sp_certificate_file": filepath.Join("conf", "sp.crt"),
+		"sp_private_key_file": "/etc/sp.key",
+	} {
+		value, _ := attributes[name].Expr.Value(nil)
+		if value.AsString() != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, value.AsString())
+		}
+	}
+}
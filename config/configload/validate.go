@@ -70,7 +70,7 @@ func validateBody(body *hclsyntax.Body, afterMerge bool) error {
 						return err
 					}
 
-				case "basic_auth", "beta_oauth2", "oidc", "saml":
+				case "api_key", "authorization", "basic_auth", "beta_oauth2", "client_certificate", "introspection", "ldap", "oidc", "saml", "session", "signature":
 					err := checkAC(uniqueACs, label, labelRange, afterMerge)
 					if err != nil {
 						return err
@@ -269,7 +269,7 @@ func verifyResponseBodyAttrs(b *hclsyntax.Body) error {
 	return nil
 }
 
-var invalidAttributes = []string{"disable_certificate_validation", "disable_connection_reuse", "http2", "max_connections"}
+var invalidAttributes = []string{"disable_certificate_validation", "disable_connection_reuse", "http2", "load_balancing", "max_connections", "origins"}
 
 func invalidRefinement(body *hclsyntax.Body) error {
 	const message = "backend reference: refinement for %q is not permitted"
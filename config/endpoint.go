@@ -17,17 +17,19 @@ var (
 // Endpoint represents the <Endpoint> object.
 type Endpoint struct {
 	ErrorHandlerSetter
-	AccessControl        []string  `hcl:"access_control,optional" docs:"Sets predefined access control for this block context."`
-	AllowedMethods       []string  `hcl:"allowed_methods,optional" docs:"Sets allowed methods overriding a default set in the containing {api} block. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
-	DisableAccessControl []string  `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
-	ErrorFile            string    `hcl:"error_file,optional" docs:"Location of the error file template."`
-	Pattern              string    `hcl:"pattern,label"`
-	Proxies              Proxies   `hcl:"proxy,block" docs:"Configures a [proxy](/configuration/block/proxy) (zero or more)."`
-	Proxy                string    `hcl:"proxy,optional" docs:"References a [{proxy} block](/configuration/block/proxy) in the [definitions](/configuration/block/definitions)."`
-	Remain               hcl.Body  `hcl:",remain"`
-	RequestBodyLimit     string    `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body} or {request.json_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
-	Requests             Requests  `hcl:"request,block" docs:"Configures a [request](/configuration/block/request) (zero or more)."`
-	Response             *Response `hcl:"response,block" docs:"Configures the [response](/configuration/block/response) (zero or one)."`
+	AccessControl        []string         `hcl:"access_control,optional" docs:"Sets predefined access control for this block context."`
+	AllowedMethods       []string         `hcl:"allowed_methods,optional" docs:"Sets allowed methods overriding a default set in the containing {api} block. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
+	DisableAccessControl []string         `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
+	ErrorFile            string           `hcl:"error_file,optional" docs:"Location of the error file template."`
+	Pattern              string           `hcl:"pattern,label"`
+	Proxies              Proxies          `hcl:"proxy,block" docs:"Configures a [proxy](/configuration/block/proxy) (zero or more)."`
+	Proxy                string           `hcl:"proxy,optional" docs:"References a [{proxy} block](/configuration/block/proxy) in the [definitions](/configuration/block/definitions)."`
+	RateLimit            *ClientRateLimit `hcl:"rate_limit,block" docs:"Configures a [client rate limit](/configuration/block/client_rate_limit) for this endpoint (zero or one)."`
+	Remain               hcl.Body         `hcl:",remain"`
+	RequestBodyLimit     string           `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body} or {request.json_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
+	ResponseBodyLimit    string           `hcl:"response_body_limit,optional" docs:"Configures the maximum buffer size while modifying the JSON response body with {add_response_json_fields}, {remove_response_json_fields} or {set_response_json_fields}. Larger response bodies are passed through unmodified. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
+	Requests             Requests         `hcl:"request,block" docs:"Configures a [request](/configuration/block/request) (zero or more)."`
+	Response             *Response        `hcl:"response,block" docs:"Configures the [response](/configuration/block/response) (zero or one)."`
 
 	// internally configured due to multi-label options
 	RequiredPermission hcl.Expression
@@ -49,6 +51,8 @@ func (e Endpoint) Inline() interface{} {
 		meta.ResponseHeadersAttributes
 		meta.FormParamsAttributes
 		meta.QueryParamsAttributes
+		meta.RequestJSONFieldsAttributes
+		meta.ResponseJSONFieldsAttributes
 		meta.LogFieldsAttribute
 		ResponseStatus     *uint8         `hcl:"set_response_status,optional" docs:"Modifies the response status code."`
 		RequiredPermission hcl.Expression `hcl:"required_permission,optional" docs:"Permission required to use this endpoint (see [error type](/configuration/error-handling#error-types) {insufficient_permissions})." type:"string or object (string)"`
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var (
+	_ BackendReference = &Fallback{}
+	_ Body             = &Fallback{}
+	_ Inline           = &Fallback{}
+)
+
+// Fallback represents the <config.Fallback> object.
+type Fallback struct {
+	BackendName string   `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) the request is sent to if the backend fails. Mutually exclusive with {backend} block."`
+	OnErrors    []string `hcl:"on_errors,optional" docs:"Error types which trigger the fallback: {\"backend\"} (connection errors like a connect timeout or a refused connection), {\"backend_timeout\"} and {\"backend_unhealthy\"}." default:"[\"backend\", \"backend_timeout\", \"backend_unhealthy\"]"`
+	OnStatus    []int    `hcl:"on_status,optional" docs:"Backend response status codes which trigger the fallback." default:"[500, 502, 503, 504]"`
+	Remain      hcl.Body `hcl:",remain"`
+}
+
+// Reference implements the <BackendReference> interface.
+func (f *Fallback) Reference() string {
+	return f.BackendName
+}
+
+// HCLBody implements the <Body> interface.
+func (f *Fallback) HCLBody() *hclsyntax.Body {
+	return f.Remain.(*hclsyntax.Body)
+}
+
+// Inline implements the <Inline> interface.
+func (f *Fallback) Inline() interface{} {
+	type Inline struct {
+		Backend *Backend `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) the request is sent to if the backend fails (zero or one). Mutually exclusive with {backend} attribute."`
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (f *Fallback) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(f)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(f.Inline())
+
+	return schema
+}
@@ -64,14 +64,17 @@ func main() {
 	bracesRegex := regexp.MustCompile(`{([^}]*)}`)
 
 	attributesMap := map[string][]reflect.StructField{
-		"RequestHeadersAttributes":  newFields(&meta.RequestHeadersAttributes{}),
-		"ResponseHeadersAttributes": newFields(&meta.ResponseHeadersAttributes{}),
-		"FormParamsAttributes":      newFields(&meta.FormParamsAttributes{}),
-		"QueryParamsAttributes":     newFields(&meta.QueryParamsAttributes{}),
-		"LogFieldsAttribute":        newFields(&meta.LogFieldsAttribute{}),
+		"RequestHeadersAttributes":     newFields(&meta.RequestHeadersAttributes{}),
+		"ResponseHeadersAttributes":    newFields(&meta.ResponseHeadersAttributes{}),
+		"FormParamsAttributes":         newFields(&meta.FormParamsAttributes{}),
+		"QueryParamsAttributes":        newFields(&meta.QueryParamsAttributes{}),
+		"RequestJSONFieldsAttributes":  newFields(&meta.RequestJSONFieldsAttributes{}),
+		"ResponseJSONFieldsAttributes": newFields(&meta.ResponseJSONFieldsAttributes{}),
+		"LogFieldsAttribute":           newFields(&meta.LogFieldsAttribute{}),
 	}
 
 	blockNamesMap := map[string]string{
+		"apikey":          "api_key",
 		"oauth2_ac":       "beta_oauth2",
 		"oauth2_req_auth": "oauth2",
 	}
@@ -80,19 +83,27 @@ func main() {
 
 	for _, impl := range []interface{}{
 		&config.API{},
+		&config.APIKey{},
+		&config.Authorization{},
+		&config.AuthorizationRule{},
 		&config.Backend{},
 		&config.BackendTLS{},
 		&config.BasicAuth{},
+		&config.CircuitBreaker{},
+		&config.ClientRateLimit{},
 		&config.CORS{},
 		&config.Defaults{},
 		&config.Definitions{},
 		&config.Endpoint{},
 		&config.ErrorHandler{},
+		&config.Fallback{},
 		&config.Files{},
 		&config.Health{},
 		&config.JWTSigningProfile{},
+		&config.Introspection{},
 		&config.JWT{},
 		&config.Job{},
+		&config.LDAP{},
 		&config.OAuth2AC{},
 		&config.OAuth2ReqAuth{},
 		&config.OIDC{},
@@ -101,14 +112,21 @@ func main() {
 		&config.RateLimit{},
 		&config.Request{},
 		&config.Response{},
+		&config.ResponseCache{},
+		&config.Retry{},
 		&config.SAML{},
 		&config.Server{},
+		&config.Session{},
+		&config.Signature{},
 		&config.ClientCertificate{},
+		&config.ClientCertificateAC{},
 		&config.ServerCertificate{},
 		&config.ServerTLS{},
 		&config.Settings{},
 		&config.Spa{},
+		&config.SSE{},
 		&config.TokenRequest{},
+		&config.Upstream{},
 		&config.Websockets{},
 	} {
 		t := reflect.TypeOf(impl).Elem()
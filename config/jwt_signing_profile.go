@@ -3,11 +3,15 @@ package config
 import "github.com/hashicorp/hcl/v2"
 
 type JWTSigningProfile struct {
-	Claims             Claims         `hcl:"claims,optional" docs:"Claims for the JWT payload, claim values are evaluated per request."`
-	Headers            hcl.Expression `hcl:"headers,optional" docs:"Additional HTTP header fields for the JWT, {typ} has the default value {JWT}, {alg} cannot be set."`
-	Key                string         `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*} and {ES*} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
-	KeyFile            string         `hcl:"key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {key}. See {key} for more information."`
-	Name               string         `hcl:"name,label,optional"`
-	SignatureAlgorithm string         `hcl:"signature_algorithm" docs:"Algorithm used for signing: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}."`
-	TTL                string         `hcl:"ttl" docs:"The token's time-to-live, creates the {exp} claim."`
+	Claims                     Claims         `hcl:"claims,optional" docs:"Claims for the JWT payload, claim values are evaluated per request."`
+	ContentEncryptionAlgorithm string         `hcl:"content_encryption_algorithm,optional" docs:"Algorithm used to encrypt the signed token: {\"A128GCM\"}, {\"A192GCM\"}, {\"A256GCM\"}. Only used together with {key_management_algorithm}." default:"A256GCM"`
+	EncryptionKey              string         `hcl:"encryption_key,optional" docs:"Public key (in PEM format) of the recipient for the {RSA-OAEP}, {RSA-OAEP-256} and {ECDH-ES} key management algorithms or the shared key for {dir}. Mutually exclusive with {encryption_key_file}."`
+	EncryptionKeyFile          string         `hcl:"encryption_key_file,optional" docs:"Reference to file containing the encryption key. Mutually exclusive with {encryption_key}. See {encryption_key} for more information."`
+	Headers                    hcl.Expression `hcl:"headers,optional" docs:"Additional HTTP header fields for the JWT, {typ} has the default value {JWT}, {alg} cannot be set."`
+	Key                        string         `hcl:"key,optional" docs:"Private key (in PEM format) for {RS*}, {PS*}, {ES*} and {EdDSA} variants or the secret for {HS*} algorithms. Mutually exclusive with {key_file}."`
+	KeyFile                    string         `hcl:"key_file,optional" docs:"Reference to file containing signing key. Mutually exclusive with {key}. See {key} for more information."`
+	KeyManagementAlgorithm     string         `hcl:"key_management_algorithm,optional" docs:"If set, the signed token created by {jwt_sign()} is encrypted (nested JWT as JWE). Valid values: {\"RSA-OAEP\"}, {\"RSA-OAEP-256\"}, {\"ECDH-ES\"}, {\"dir\"}."`
+	Name                       string         `hcl:"name,label,optional"`
+	SignatureAlgorithm         string         `hcl:"signature_algorithm" docs:"Algorithm used for signing: {\"RS256\"}, {\"RS384\"}, {\"RS512\"}, {\"HS256\"}, {\"HS384\"}, {\"HS512\"}, {\"ES256\"}, {\"ES384\"}, {\"ES512\"}, {\"PS256\"}, {\"PS384\"}, {\"PS512\"}, {\"EdDSA\"}."`
+	TTL                        string         `hcl:"ttl" docs:"The token's time-to-live, creates the {exp} claim."`
 }
@@ -10,6 +10,8 @@ var RequestHeadersAttributesSchema, _ = gohcl.ImpliedBodySchema(&RequestHeadersA
 var ResponseHeadersAttributesSchema, _ = gohcl.ImpliedBodySchema(&ResponseHeadersAttributes{})
 var FormParamsAttributesSchema, _ = gohcl.ImpliedBodySchema(&FormParamsAttributes{})
 var QueryParamsAttributesSchema, _ = gohcl.ImpliedBodySchema(&QueryParamsAttributes{})
+var RequestJSONFieldsAttributesSchema, _ = gohcl.ImpliedBodySchema(&RequestJSONFieldsAttributes{})
+var ResponseJSONFieldsAttributesSchema, _ = gohcl.ImpliedBodySchema(&ResponseJSONFieldsAttributes{})
 var LogFieldsAttributeSchema, _ = gohcl.ImpliedBodySchema(&LogFieldsAttribute{})
 
 var ModifierAttributesSchema = MergeSchemas(
@@ -17,6 +19,8 @@ var ModifierAttributesSchema = MergeSchemas(
 	ResponseHeadersAttributesSchema,
 	FormParamsAttributesSchema,
 	QueryParamsAttributesSchema,
+	RequestJSONFieldsAttributesSchema,
+	ResponseJSONFieldsAttributesSchema,
 )
 
 // Attributes are commonly shared attributes which gets evaluated during runtime.
@@ -49,6 +53,20 @@ type ResponseHeadersAttributes struct {
 	SetResponseHeaders map[string]string `hcl:"set_response_headers,optional" docs:"Key/value pairs to set as response headers in the client response."`
 }
 
+type RequestJSONFieldsAttributes struct {
+	// Request JSON Body Modifiers
+	AddRequestJSONFields map[string]cty.Value `hcl:"add_request_json_fields,optional" docs:"Key/value pairs to add fields to the JSON request body. Keys are [JSON paths](/configuration/modifiers#json-paths)."`
+	DelRequestJSONFields []string             `hcl:"remove_request_json_fields,optional" docs:"List of [JSON paths](/configuration/modifiers#json-paths) to remove fields from the JSON request body."`
+	SetRequestJSONFields map[string]cty.Value `hcl:"set_request_json_fields,optional" docs:"Key/value pairs to set fields in the JSON request body. Keys are [JSON paths](/configuration/modifiers#json-paths)."`
+}
+
+type ResponseJSONFieldsAttributes struct {
+	// Response JSON Body Modifiers
+	AddResponseJSONFields map[string]cty.Value `hcl:"add_response_json_fields,optional" docs:"Key/value pairs to add fields to the JSON response body. Keys are [JSON paths](/configuration/modifiers#json-paths)."`
+	DelResponseJSONFields []string             `hcl:"remove_response_json_fields,optional" docs:"List of [JSON paths](/configuration/modifiers#json-paths) to remove fields from the JSON response body."`
+	SetResponseJSONFields map[string]cty.Value `hcl:"set_response_json_fields,optional" docs:"Key/value pairs to set fields in the JSON response body. Keys are [JSON paths](/configuration/modifiers#json-paths)."`
+}
+
 type LogFieldsAttribute struct {
 	LogFields map[string]hcl.Expression `hcl:"custom_log_fields,optional" docs:"Log fields for [custom logging](/observation/logging#custom-logging). Inherited by nested blocks."`
 }
@@ -36,3 +36,10 @@ type OAuth2Authorization interface {
 	GetScope() string
 	GetVerifierMethod() (string, error)
 }
+
+// OIDCLogout represents the configuration for the OIDC logout URL function
+type OIDCLogout interface {
+	GetClientID() string
+	GetEndSessionEndpoint() (string, error)
+	GetPostLogoutRedirectURI() string
+}
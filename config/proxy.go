@@ -17,9 +17,11 @@ var (
 // Proxy represents the <Proxy> object.
 type Proxy struct {
 	BackendName string   `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for the proxy request. Mutually exclusive with {backend} block."`
+	GRPCWeb     bool     `hcl:"grpc_web,optional" docs:"Translates [gRPC-Web](https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md) requests of browser clients into native gRPC requests and the related responses back to gRPC-Web. Requires an HTTP/2 backend."`
 	Name        string   `hcl:"name,label,optional"`
 	Remain      hcl.Body `hcl:",remain"`
 	ReqName     string   `hcl:"name,optional" docs:"Defines the proxy request name. Allowed only in the [{definitions} block](definitions)." default:"default"`
+	SSE         *bool    `hcl:"sse,optional" docs:"Enables the [Server-Sent Events](/configuration/block/sse) mode: the backend {timeout} does not apply to event streams which are closed after an idle timeout instead. Mutually exclusive with {sse} block."`
 	Websockets  *bool    `hcl:"websockets,optional" docs:"Allows support for WebSockets. This attribute is only allowed in the \"default\" proxy block. Other {proxy} blocks, {request} blocks or {response} blocks are not allowed within the current {endpoint} block. Mutually exclusive with {websockets} block."`
 
 	// internally used
@@ -46,10 +48,14 @@ func (p Proxy) Inline() interface{} {
 		meta.ResponseHeadersAttributes
 		meta.FormParamsAttributes
 		meta.QueryParamsAttributes
-		Backend        *Backend    `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for the proxy request (zero or one). Mutually exclusive with {backend} attribute."`
-		ExpectedStatus []int       `hcl:"expected_status,optional" docs:"If defined, the response status code will be verified against this list of codes. If the status code not included in this list an {unexpected_status} error will be thrown which can be handled with an [{error_handler}](error_handler)."`
-		URL            string      `hcl:"url,optional" docs:"URL of the resource to request. May be relative to an origin specified in a referenced or nested {backend} block."`
-		Websockets     *Websockets `hcl:"websockets,block" docs:"Configures support for [websockets](/configuration/block/websockets) connections (zero or one). Mutually exclusive with {websockets} attribute."`
+		meta.RequestJSONFieldsAttributes
+		meta.ResponseJSONFieldsAttributes
+		Backend        *Backend       `hcl:"backend,block" docs:"Configures a [backend](/configuration/block/backend) for the proxy request (zero or one). Mutually exclusive with {backend} attribute."`
+		ExpectedStatus []int          `hcl:"expected_status,optional" docs:"If defined, the response status code will be verified against this list of codes. If the status code not included in this list an {unexpected_status} error will be thrown which can be handled with an [{error_handler}](error_handler)."`
+		ResponseCache  *ResponseCache `hcl:"response_cache,block" docs:"Configures a [response cache](/configuration/block/response_cache) for this proxy request (zero or one). Overrides the {response_cache} of the backend."`
+		SSE            *SSE           `hcl:"sse,block" docs:"Configures the [Server-Sent Events](/configuration/block/sse) mode (zero or one). Mutually exclusive with {sse} attribute."`
+		URL            string         `hcl:"url,optional" docs:"URL of the resource to request. May be relative to an origin specified in a referenced or nested {backend} block."`
+		Websockets     *Websockets    `hcl:"websockets,block" docs:"Configures support for [websockets](/configuration/block/websockets) connections (zero or one). Mutually exclusive with {websockets} attribute."`
 	}
 
 	return &Inline{}
@@ -6,13 +6,19 @@ const (
 	ContextType ContextKey = iota
 	APIName
 	AccessControls
+	BackendAttempts
 	BackendBytes
+	BackendEventStream
+	BackendFallbackFor
 	BackendName
 	BackendParams
 	BufferOptions
 	ConfigDryRun
 	ConnectTimeout
 	ContextVariablesSynced
+	CredentialCookies
+	CredentialHeaders
+	CredentialQueryParams
 	Endpoint
 	EndpointExpectedStatus
 	EndpointKind
@@ -29,11 +35,16 @@ const (
 	PathParams
 	RequiredPermission
 	ResponseBlock
+	ResponseBodyLimit
+	ResponseCache
+	ResponseCacheStatus
 	ResponseWriter
 	RoundTripName
 	RoundTripProxy
 	ServerName
 	ServerTimings
+	SessionAccessToken
+	SSEIdleTimeout
 	StartTime
 	TokenRequest
 	TokenRequestRetries
@@ -0,0 +1,10 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// ResponseCache represents the <config.ResponseCache> object.
+type ResponseCache struct {
+	CacheKey     hcl.Expression `hcl:"cache_key,optional" docs:"Expression which result is added to the cache key. The cache key always consists of the request method and URL of the backend request." type:"string"`
+	MaxEntrySize string         `hcl:"max_entry_size,optional" docs:"Maximum body size of a cacheable response. Larger responses are not stored. Valid units are: {KiB}, {MiB}, {GiB}." default:"1MiB"`
+	MaxSize      string         `hcl:"max_size,optional" docs:"Maximum total body size of all stored responses. The least recently used responses are evicted if the limit is exceeded. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
+}
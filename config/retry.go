@@ -0,0 +1,11 @@
+package config
+
+// Retry represents the <config.Retry> object.
+type Retry struct {
+	Attempts   *uint    `hcl:"attempts,optional" docs:"Maximum number of attempts including the initial request." default:"3"`
+	Backoff    string   `hcl:"backoff,optional" docs:"Delay before the first retry. The delay is doubled for each further retry and randomized by a jitter of up to 50%." type:"duration" default:"100ms"`
+	MaxBackoff string   `hcl:"max_backoff,optional" docs:"Maximum delay between two attempts." type:"duration" default:"2s"`
+	Methods    []string `hcl:"methods,optional" docs:"Request methods which are retried. Requests with a body are only retried if the body has been buffered, which is limited by the {request_body_limit} of the related [{endpoint}](endpoint) block." default:"[\"GET\", \"HEAD\", \"OPTIONS\", \"PUT\", \"DELETE\"]"`
+	OnErrors   []string `hcl:"on_errors,optional" docs:"Error types which are retried: {\"backend\"} (connection errors like a connect timeout or a refused connection), {\"backend_timeout\"} and {\"backend_unhealthy\"}." default:"[\"backend\", \"backend_unhealthy\"]"`
+	OnStatus   []int    `hcl:"on_status,optional" docs:"Backend response status codes which are retried." default:"[502, 503, 504]"`
+}
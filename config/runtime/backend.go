@@ -22,10 +22,11 @@ import (
 	"github.com/coupergateway/couper/handler/ratelimit"
 	"github.com/coupergateway/couper/handler/transport"
 	"github.com/coupergateway/couper/handler/validation"
+	"github.com/coupergateway/couper/internal/seetie"
 )
 
 func NewBackend(ctx *hcl.EvalContext, body *hclsyntax.Body, log *logrus.Entry,
-	conf *config.Couper, store *cache.MemoryStore) (http.RoundTripper, error) {
+	conf *config.Couper, store cache.Store) (http.RoundTripper, error) {
 	const prefix = "backend_"
 	name, err := getBackendName(ctx, body)
 
@@ -52,7 +53,7 @@ func NewBackend(ctx *hcl.EvalContext, body *hclsyntax.Body, log *logrus.Entry,
 }
 
 func newBackend(evalCtx *hcl.EvalContext, backendCtx *hclsyntax.Body, log *logrus.Entry,
-	conf *config.Couper, memStore *cache.MemoryStore) (http.RoundTripper, error) {
+	conf *config.Couper, memStore cache.Store) (http.RoundTripper, error) {
 	beConf := &config.Backend{}
 	if diags := gohcl.DecodeBody(backendCtx, evalCtx, beConf); diags.HasErrors() {
 		return nil, diags
@@ -106,7 +107,64 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx *hclsyntax.Body, log *logru
 
 	options.OpenAPI = opts
 
-	if beConf.Health != nil {
+	if beConf.ResponseCache != nil {
+		options.ResponseCache, err = transport.NewResponseCache(beConf.ResponseCache, beConf.Name, memStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if beConf.CircuitBreaker != nil {
+		options.CircuitBreaker, err = transport.NewCircuitBreaker(beConf.CircuitBreaker, log.WithField("backend", beConf.Name))
+		if err != nil {
+			return nil, errors.Configuration.Label(beConf.Name).Message("circuit_breaker").With(err)
+		}
+	}
+
+	if beConf.Retry != nil {
+		options.Retry, err = transport.NewRetry(beConf.Retry)
+		if err != nil {
+			return nil, errors.Configuration.Label(beConf.Name).Message("retry").With(err)
+		}
+	}
+
+	originsVal, diags := eval.ValueFromBodyAttribute(evalCtx, backendCtx, "origins")
+	if diags != nil {
+		return nil, diags
+	}
+
+	if originsVal != cty.NilVal || len(beConf.Upstreams) > 0 {
+		if _, exist := backendCtx.Attributes["origin"]; exist {
+			return nil, errors.Configuration.Label(beConf.Name).
+				Message("the origin attribute must not be combined with origins or upstream blocks")
+		}
+
+		if originsVal != cty.NilVal && len(beConf.Upstreams) > 0 {
+			return nil, errors.Configuration.Label(beConf.Name).
+				Message("the origins attribute must not be combined with upstream blocks")
+		}
+
+		upstreams := beConf.Upstreams
+		for _, origin := range seetie.ValueToStringSlice(originsVal) {
+			upstreams = append(upstreams, &config.Upstream{Origin: origin})
+		}
+
+		options.LoadBalancer, err = transport.NewLoadBalancer(beConf.LoadBalancing, upstreams, beConf.Health, conf)
+		if err != nil {
+			return nil, errors.Configuration.Label(beConf.Name).Message("load_balancing").With(err)
+		}
+
+		if _, exist := backendCtx.Attributes["hash_key"]; !exist &&
+			options.LoadBalancer.Strategy() == transport.LoadBalancingConsistentHash {
+			return nil, errors.Configuration.Label(beConf.Name).
+				Message("the consistent_hash strategy requires a hash_key attribute")
+		}
+	} else if beConf.LoadBalancing != "" {
+		return nil, errors.Configuration.Label(beConf.Name).
+			Message("the load_balancing attribute requires origins or upstream blocks")
+	}
+
+	if beConf.Health != nil && options.LoadBalancer == nil {
 		origin, diags := eval.ValueFromBodyAttribute(evalCtx, backendCtx, "origin")
 		if diags != nil {
 			return nil, diags
@@ -138,11 +196,36 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx *hclsyntax.Body, log *logru
 	}
 
 	b := transport.NewBackend(backendCtx, tc, options, log)
+
+	if beConf.Fallback != nil {
+		fallbackBlocks := hclbody.BlocksOfType(backendCtx, "fallback")
+		backendBlocks := hclbody.BlocksOfType(fallbackBlocks[0].Body, "backend")
+		if len(backendBlocks) == 0 {
+			r := fallbackBlocks[0].Body.SrcRange
+			diag := &hcl.Diagnostics{&hcl.Diagnostic{
+				Subject: &r,
+				Summary: "missing backend initialization",
+			}}
+			return nil, errors.Configuration.Label("unexpected").With(diag)
+		}
+
+		// backend block is set by configload package
+		fallbackBackend, ferr := NewBackend(evalCtx, backendBlocks[0].Body, log, conf, memStore)
+		if ferr != nil {
+			return nil, ferr
+		}
+
+		b, err = transport.NewFallback(beConf.Fallback, beConf.Name, b, fallbackBackend)
+		if err != nil {
+			return nil, errors.Configuration.Label(beConf.Name).Message("fallback").With(err)
+		}
+	}
+
 	return b, nil
 }
 
 func newRequestAuthorizer(evalCtx *hcl.EvalContext, block *hclsyntax.Block,
-	log *logrus.Entry, conf *config.Couper, memStore *cache.MemoryStore) (transport.RequestAuthorizer, error) {
+	log *logrus.Entry, conf *config.Couper, memStore cache.Store) (transport.RequestAuthorizer, error) {
 	var authorizerConfig interface{}
 	switch block.Type {
 	case config.OAuthBlockSchema.Blocks[0].Type:
@@ -2,19 +2,23 @@ package runtime
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/sirupsen/logrus"
 
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
+	hclbody "github.com/coupergateway/couper/config/body"
 	"github.com/coupergateway/couper/config/runtime/server"
 	"github.com/coupergateway/couper/config/sequence"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval/buffer"
 	"github.com/coupergateway/couper/handler"
 	"github.com/coupergateway/couper/handler/producer"
+	"github.com/coupergateway/couper/handler/transport"
 )
 
 func newEndpointMap(srvConf *config.Server, serverOptions *server.Options) (endpointMap, error) {
@@ -84,7 +88,7 @@ func newEndpointMap(srvConf *config.Server, serverOptions *server.Options) (endp
 }
 
 func NewEndpointOptions(confCtx *hcl.EvalContext, endpointConf *config.Endpoint, apiConf *config.API,
-	serverOptions *server.Options, log *logrus.Entry, conf *config.Couper, memStore *cache.MemoryStore) (*handler.EndpointOptions, error) {
+	serverOptions *server.Options, log *logrus.Entry, conf *config.Couper, memStore cache.Store) (*handler.EndpointOptions, error) {
 	var errTpl *errors.Template
 
 	if endpointConf.ErrorFile != "" {
@@ -128,8 +132,24 @@ func NewEndpointOptions(confCtx *hcl.EvalContext, endpointConf *config.Endpoint,
 			}
 		}
 
+		if cacheBlocks := hclbody.BlocksOfType(proxyBody, "response_cache"); len(cacheBlocks) > 0 {
+			cacheConf := &config.ResponseCache{}
+			if diags := gohcl.DecodeBody(cacheBlocks[0].Body, confCtx, cacheConf); diags.HasErrors() {
+				return nil, diags
+			}
+
+			responseCache, err := transport.NewResponseCache(cacheConf, fmt.Sprintf("proxy_%p", proxyConf), memStore)
+			if err != nil {
+				return nil, errors.Configuration.Label(proxyConf.Name).With(err)
+			}
+			backend = transport.NewResponseCacheContext(responseCache, backend)
+		}
+
 		allowWebsockets := proxyConf.Websockets != nil || hasWSblock
-		proxyHandler := handler.NewProxy(backend, proxyBody, allowWebsockets, log)
+		var proxyHandler http.RoundTripper = handler.NewProxy(backend, proxyBody, allowWebsockets, log)
+		if proxyConf.GRPCWeb {
+			proxyHandler = handler.NewGRPCWeb(proxyHandler)
+		}
 
 		p := &producer.Proxy{
 			Content:   proxyBody,
@@ -181,6 +201,16 @@ func NewEndpointOptions(confCtx *hcl.EvalContext, endpointConf *config.Endpoint,
 		}}
 	}
 
+	resBodyLimit, err := parseBodyLimit(endpointConf.ResponseBodyLimit)
+	if err != nil {
+		r := endpointConf.HCLBody().SrcRange
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("endpoint: %q: parsing response body limit", endpointConf.Pattern),
+			Subject:  &r,
+		}}
+	}
+
 	bufferOpts := buffer.Must(append(blockBodies, endpointConf.Remain)...)
 
 	apiName := ""
@@ -196,6 +226,7 @@ func NewEndpointOptions(confCtx *hcl.EvalContext, endpointConf *config.Endpoint,
 		LogPattern:        endpointConf.Pattern,
 		Producers:         allProducers,
 		ReqBodyLimit:      bodyLimit,
+		ResBodyLimit:      resBodyLimit,
 		BufferOpts:        bufferOpts,
 		SendServerTimings: conf.Settings.SendServerTimings,
 		Response:          response,
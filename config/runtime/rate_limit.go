@@ -0,0 +1,54 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/handler/middleware"
+	"github.com/coupergateway/couper/handler/ratelimit"
+)
+
+// clientLimiters holds one limiter per configured rate_limit block, so all
+// endpoints of an api or server block share the counters of their parent.
+type clientLimiters map[*config.ClientRateLimit]*ratelimit.ClientLimiter
+
+// newClientRateLimitHandler wraps the given handler with the configured client rate limits.
+// The first given limit is the outermost one, nil entries are skipped.
+func newClientRateLimitHandler(ctx context.Context, limiters clientLimiters, h, errorHandler http.Handler,
+	limits ...*config.ClientRateLimit) (http.Handler, error) {
+	return limiters.wrap(ctx, h, errorHandler, (*ratelimit.ClientLimiter).NewHandler, limits)
+}
+
+// newClientRateLimitPreHandler wraps the given handler, including its access controls, with the
+// configured client rate limits, so requests failing the access controls are counted, too.
+func newClientRateLimitPreHandler(ctx context.Context, limiters clientLimiters, h, errorHandler http.Handler,
+	limits ...*config.ClientRateLimit) (http.Handler, error) {
+	return limiters.wrap(ctx, h, errorHandler, (*ratelimit.ClientLimiter).NewPreHandler, limits)
+}
+
+func (limiters clientLimiters) wrap(ctx context.Context, h, errorHandler http.Handler,
+	newHandler func(*ratelimit.ClientLimiter, http.Handler) middleware.Next,
+	limits []*config.ClientRateLimit) (http.Handler, error) {
+	for i := len(limits) - 1; i >= 0; i-- {
+		limit := limits[i]
+		if limit == nil {
+			continue
+		}
+
+		limiter, exist := limiters[limit]
+		if !exist {
+			var err error
+			limiter, err = ratelimit.NewClientLimiter(ctx, limit)
+			if err != nil {
+				return nil, fmt.Errorf("rate_limit: %w", err)
+			}
+			limiters[limit] = limiter
+		}
+
+		h = newHandler(limiter, errorHandler)(h)
+	}
+
+	return h, nil
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/sirupsen/logrus"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/accesscontrol/jwe"
 	"github.com/coupergateway/couper/accesscontrol/jwk"
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/configload/collect"
@@ -30,6 +32,7 @@ import (
 	"github.com/coupergateway/couper/eval/buffer"
 	"github.com/coupergateway/couper/handler"
 	"github.com/coupergateway/couper/handler/middleware"
+	"github.com/coupergateway/couper/internal/seetie"
 	"github.com/coupergateway/couper/oauth2"
 	"github.com/coupergateway/couper/oauth2/oidc"
 	"github.com/coupergateway/couper/utils"
@@ -81,7 +84,7 @@ func GetHostPort(hostPort string) (string, int, error) {
 
 // NewServerConfiguration sets http handler specific defaults and validates the given gateway configuration.
 // Wire up all endpoints and maps them within the returned Server.
-func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *cache.MemoryStore) (ServerConfiguration, error) {
+func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore cache.Store) (ServerConfiguration, error) {
 	evalContext := conf.Context.Value(request.ContextType).(*eval.Context) // usually environment vars
 	confCtx := evalContext.HCLContext()
 
@@ -102,6 +105,7 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 		serverConfiguration = make(ServerConfiguration)
 		defaultPort         = conf.Settings.DefaultPort
 		endpointHandlers    = make(endpointHandler)
+		rateLimiters        = make(clientLimiters)
 		isHostsMandatory    = len(conf.Servers) > 1
 	)
 
@@ -203,6 +207,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			}
 
 			epOpts := &handler.EndpointOptions{ErrorTemplate: serverOptions.ServerErrTpl}
+			spaHandler, err = newClientRateLimitHandler(conf.Context, rateLimiters, spaHandler,
+				handler.NewErrorHandler(nil, epOpts.ErrorTemplate), srvConf.RateLimit)
+			if err != nil {
+				return nil, err
+			}
+
 			notAllowedMethodsHandler := epOpts.ErrorTemplate.WithError(errors.MethodNotAllowed)
 			allowedMethodsHandler := middleware.NewAllowedMethodsHandler(nil, middleware.DefaultFileSpaAllowedMethods, spaHandler, notAllowedMethodsHandler)
 			spaHandler = allowedMethodsHandler
@@ -220,6 +230,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 				return nil, err
 			}
 
+			spaHandler, err = newClientRateLimitPreHandler(conf.Context, rateLimiters, spaHandler,
+				handler.NewErrorHandler(nil, epOpts.ErrorTemplate), srvConf.RateLimit)
+			if err != nil {
+				return nil, err
+			}
+
 			corsOptions, cerr := middleware.NewCORSOptions(whichCORS(srvConf, spaConf), allowedMethodsHandler.MethodAllowed)
 			if cerr != nil {
 				return nil, cerr
@@ -260,6 +276,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			}
 
 			epOpts := &handler.EndpointOptions{ErrorTemplate: serverOptions.FilesErrTpls[i]}
+			fileHandler, err = newClientRateLimitHandler(conf.Context, rateLimiters, fileHandler,
+				handler.NewErrorHandler(nil, epOpts.ErrorTemplate), srvConf.RateLimit)
+			if err != nil {
+				return nil, err
+			}
+
 			notAllowedMethodsHandler := epOpts.ErrorTemplate.WithError(errors.MethodNotAllowed)
 			allowedMethodsHandler := middleware.NewAllowedMethodsHandler(nil, middleware.DefaultFileSpaAllowedMethods, fileHandler, notAllowedMethodsHandler)
 			fileHandler = allowedMethodsHandler
@@ -277,6 +299,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 				return nil, err
 			}
 
+			fileHandler, err = newClientRateLimitPreHandler(conf.Context, rateLimiters, fileHandler,
+				handler.NewErrorHandler(nil, epOpts.ErrorTemplate), srvConf.RateLimit)
+			if err != nil {
+				return nil, err
+			}
+
 			corsOptions, cerr := middleware.NewCORSOptions(whichCORS(srvConf, filesConf), allowedMethodsHandler.MethodAllowed)
 			if cerr != nil {
 				return nil, cerr
@@ -312,11 +340,19 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			}
 
 			// Evaluate access-control related buffer options.
-			acBodies := bodiesWithACBodies(conf.Definitions,
-				newAC(srvConf, parentAPI).
-					Merge(config.
-						NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl)).List(), nil)
+			acList := newAC(srvConf, parentAPI).
+				Merge(config.
+					NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl)).List()
+			acBodies := bodiesWithACBodies(conf.Definitions, acList, nil)
 			epOpts.BufferOpts |= buffer.Must(acBodies...)
+			// signature access controls verify the raw request body
+			for _, signatureConf := range conf.Definitions.Signature {
+				for _, name := range acList {
+					if name == signatureConf.Name {
+						epOpts.BufferOpts |= buffer.Request
+					}
+				}
+			}
 
 			errorHandlerDefinitions := ACDefinitions{ // misuse of definitions obj for now
 				"endpoint": &AccessControl{ErrorHandler: endpointConf.ErrorHandler},
@@ -374,6 +410,32 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 				}
 			}
 
+			var (
+				apiRateLimit          *config.ClientRateLimit
+				rateLimitErrorHandler http.Handler
+			)
+			if parentAPI != nil {
+				apiRateLimit = parentAPI.RateLimit
+			}
+			if srvConf.RateLimit != nil || apiRateLimit != nil || endpointConf.RateLimit != nil {
+				var ehBufferOption buffer.Option
+				rateLimitErrorHandler, ehBufferOption, err = newErrorHandler(confCtx, conf, &protectedOptions{
+					epOpts:   epOpts,
+					memStore: memStore,
+					srvOpts:  serverOptions,
+				}, log, errorHandlerDefinitions, "api", "endpoint") // sequence of ref is important: api, endpoint (endpoint error_handler overrides api error_handler)
+				if err != nil {
+					return nil, err
+				}
+				epOpts.BufferOpts |= ehBufferOption
+
+				protectedHandler, err = newClientRateLimitHandler(conf.Context, rateLimiters, protectedHandler, rateLimitErrorHandler,
+					srvConf.RateLimit, apiRateLimit, endpointConf.RateLimit)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			accessControl := newAC(srvConf, parentAPI)
 
 			allowedMethods := endpointConf.AllowedMethods
@@ -397,6 +459,14 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 				return nil, err
 			}
 
+			if rateLimitErrorHandler != nil {
+				epHandler, err = newClientRateLimitPreHandler(conf.Context, rateLimiters, epHandler, rateLimitErrorHandler,
+					srvConf.RateLimit, apiRateLimit, endpointConf.RateLimit)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			corsOptions, err := middleware.NewCORSOptions(whichCORS(srvConf, parentAPI), allowedMethodsHandler.MethodAllowed)
 			if err != nil {
 				return nil, err
@@ -484,7 +554,7 @@ func whichCORS(parent *config.Server, this interface{}) *config.CORS {
 	return corsData
 }
 
-func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry, memStore *cache.MemoryStore) (oidc.Configs, error) {
+func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry, memStore cache.Store) (oidc.Configs, error) {
 	oidcConfigs := make(oidc.Configs)
 	if conf.Definitions != nil {
 		for _, oidcConf := range conf.Definitions.OIDC {
@@ -511,11 +581,31 @@ func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *lo
 }
 
 func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry,
-	memStore *cache.MemoryStore, oidcConfigs oidc.Configs) (ACDefinitions, error) {
+	memStore cache.Store, oidcConfigs oidc.Configs) (ACDefinitions, error) {
 
 	accessControls := make(ACDefinitions)
 
 	if conf.Definitions != nil {
+		for _, akConf := range conf.Definitions.APIKey {
+			confErr := errors.Configuration.Label(akConf.Name)
+			apiKey, err := newAPIKey(akConf)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(akConf.Name, apiKey, akConf.ErrorHandler)
+		}
+
+		for _, authzConf := range conf.Definitions.Authorization {
+			confErr := errors.Configuration.Label(authzConf.Name)
+			authorization, err := ac.NewAuthorization(authzConf)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(authzConf.Name, authorization, authzConf.ErrorHandler)
+		}
+
 		for _, baConf := range conf.Definitions.BasicAuth {
 			confErr := errors.Configuration.Label(baConf.Name)
 			basicAuth, err := ac.NewBasicAuth(baConf.Name, baConf.User, baConf.Pass, baConf.File)
@@ -526,6 +616,32 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			accessControls.Add(baConf.Name, basicAuth, baConf.ErrorHandler)
 		}
 
+		for _, ccConf := range conf.Definitions.ClientCertificate {
+			confErr := errors.Configuration.Label(ccConf.Name)
+			permissionsMap, err := reader.ReadFromAttrFileJSONObjectOptional("client_certificate permissions map", ccConf.PermissionsMap, ccConf.PermissionsMapFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			clientCertificate, err := ac.NewClientCertificate(ccConf.Name, ccConf.AllowedSubjects, ccConf.AllowedSANs,
+				ccConf.SubjectPermissions, ccConf.SANPermissions, permissionsMap)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(ccConf.Name, clientCertificate, ccConf.ErrorHandler)
+		}
+
+		for _, introspectionConf := range conf.Definitions.Introspection {
+			confErr := errors.Configuration.Label(introspectionConf.Name)
+			introspection, err := newIntrospection(introspectionConf, conf, confCtx, log, memStore)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(introspectionConf.Name, introspection, introspectionConf.ErrorHandler)
+		}
+
 		for _, jwtConf := range conf.Definitions.JWT {
 			confErr := errors.Configuration.Label(jwtConf.Name)
 
@@ -537,9 +653,19 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			accessControls.Add(jwtConf.Name, jwt, jwtConf.ErrorHandler)
 		}
 
+		for _, ldapConf := range conf.Definitions.LDAP {
+			confErr := errors.Configuration.Label(ldapConf.Name)
+			ldap, err := newLDAP(ldapConf, memStore)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(ldapConf.Name, ldap, ldapConf.ErrorHandler)
+		}
+
 		for _, saml := range conf.Definitions.SAML {
 			confErr := errors.Configuration.Label(saml.Name)
-			s, err := ac.NewSAML2ACS(saml.MetadataBytes, saml.Name, saml.SpAcsURL, saml.SpEntityID, saml.ArrayAttributes)
+			s, err := ac.NewSAML2ACS(saml)
 			if err != nil {
 				return nil, confErr.With(err)
 			}
@@ -576,13 +702,124 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 
 			accessControls.Add(oidcConf.Name, oa, oidcConf.ErrorHandler)
 		}
+
+		for _, sessionConf := range conf.Definitions.Session {
+			confErr := errors.Configuration.Label(sessionConf.Name)
+			session, err := newSession(sessionConf, accessControls, memStore)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(sessionConf.Name, session, sessionConf.ErrorHandler)
+		}
+
+		for _, signatureConf := range conf.Definitions.Signature {
+			confErr := errors.Configuration.Label(signatureConf.Name)
+			key, err := reader.ReadFromAttrFile("signature key", signatureConf.Key, signatureConf.KeyFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			signature, err := ac.NewSignature(signatureConf, key)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(signatureConf.Name, signature, signatureConf.ErrorHandler)
+		}
 	}
 
 	return accessControls, nil
 }
 
+func newAPIKey(akConf *config.APIKey) (*ac.APIKey, error) {
+	source, err := ac.NewKeySource(akConf.Cookie, akConf.Header, akConf.QueryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsMap, err := reader.ReadFromAttrFileJSONObjectOptional("api_key permissions map", akConf.PermissionsMap, akConf.PermissionsMapFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]map[string]interface{}, len(akConf.Keys))
+	for key, metadata := range akConf.Keys {
+		if metadataType := metadata.Type(); !metadata.IsNull() && !(metadataType.IsObjectType() || metadataType.IsMapType()) {
+			return nil, fmt.Errorf("keys: metadata must be an object")
+		}
+		keys[key] = seetie.ValueToMap(metadata)
+	}
+
+	return ac.NewAPIKey(akConf.Name, source, keys, akConf.KeysFile, permissionsMap)
+}
+
+func newLDAP(ldapConf *config.LDAP, memStore cache.Store) (*ac.LDAP, error) {
+	permissionsMap, err := reader.ReadFromAttrFileJSONObjectOptional("ldap permissions map", ldapConf.PermissionsMap, ldapConf.PermissionsMapFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var caCertificates []byte
+	if ldapConf.CAFile != "" {
+		if caCertificates, err = reader.ReadFromFile("ldap ca_file", ldapConf.CAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return ac.NewLDAP(ldapConf, caCertificates, permissionsMap, memStore)
+}
+
+func newSession(sessionConf *config.Session, accessControls ACDefinitions, memStore cache.Store) (*ac.Session, error) {
+	var callback *ac.OAuth2Callback
+	if definition, exists := accessControls[sessionConf.OAuth2]; exists {
+		callback, _ = definition.Control.(*ac.OAuth2Callback)
+	}
+	if callback == nil {
+		return nil, fmt.Errorf("oauth2: %q must reference an oidc or beta_oauth2 block", sessionConf.OAuth2)
+	}
+
+	var encryptionKey []byte
+	if sessionConf.EncryptionKey != "" || sessionConf.EncryptionKeyFile != "" {
+		key, err := reader.ReadFromAttrFile("session encryption key", sessionConf.EncryptionKey, sessionConf.EncryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		encryptionKey = key
+	}
+
+	session, err := ac.NewSession(sessionConf, encryptionKey, callback.Client(), memStore)
+	if err != nil {
+		return nil, err
+	}
+
+	callback.SetSession(session)
+	return session, nil
+}
+
+func newIntrospection(introspectionConf *config.Introspection, conf *config.Couper, confCtx *hcl.EvalContext,
+	log *logrus.Entry, memStore cache.Store) (*ac.Introspection, error) {
+	backend, err := NewBackend(confCtx, introspectionConf.Backend, log, conf, memStore)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := oauth2.NewIntrospectionClient(confCtx, introspectionConf, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsMap, err := reader.ReadFromAttrFileJSONObjectOptional("introspection permissions map",
+		introspectionConf.PermissionsMap, introspectionConf.PermissionsMapFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return ac.NewIntrospection(introspectionConf, client, permissionsMap, memStore)
+}
+
 func newJWT(jwtConf *config.JWT, conf *config.Couper, confCtx *hcl.EvalContext,
-	log *logrus.Entry, memStore *cache.MemoryStore) (*ac.JWT, error) {
+	log *logrus.Entry, memStore cache.Store) (*ac.JWT, error) {
 	var (
 		jwt *ac.JWT
 		err error
@@ -614,10 +851,31 @@ func newJWT(jwtConf *config.JWT, conf *config.Couper, confCtx *hcl.EvalContext,
 		return nil, err
 	}
 
+	if jwtConf.DecryptionKey != "" || jwtConf.DecryptionKeyFile != "" {
+		decryptionKey, derr := reader.ReadFromAttrFile("jwt decryption key", jwtConf.DecryptionKey, jwtConf.DecryptionKeyFile)
+		if derr != nil {
+			return nil, derr
+		}
+
+		decrypter, derr := jwe.NewDecrypter(decryptionKey)
+		if derr != nil {
+			return nil, derr
+		}
+		jwt.SetDecrypter(decrypter)
+	}
+
+	if jwtConf.RevocationURL != "" {
+		revocationList, rerr := configureRevocationList(jwtConf, confCtx, log, conf, memStore)
+		if rerr != nil {
+			return nil, rerr
+		}
+		jwt.SetRevocationList(revocationList)
+	}
+
 	return jwt, nil
 }
 
-func configureJWKS(jwtConf *config.JWT, confContext *hcl.EvalContext, log *logrus.Entry, conf *config.Couper, memStore *cache.MemoryStore) (*jwk.JWKS, error) {
+func configureJWKS(jwtConf *config.JWT, confContext *hcl.EvalContext, log *logrus.Entry, conf *config.Couper, memStore cache.Store) (*jwk.JWKS, error) {
 	backend, err := NewBackend(confContext, jwtConf.Backend, log, conf, memStore)
 	if err != nil {
 		return nil, err
@@ -626,10 +884,19 @@ func configureJWKS(jwtConf *config.JWT, confContext *hcl.EvalContext, log *logru
 	return jwk.NewJWKS(conf.Context, jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, backend)
 }
 
+func configureRevocationList(jwtConf *config.JWT, confContext *hcl.EvalContext, log *logrus.Entry, conf *config.Couper, memStore cache.Store) (*acjwt.RevocationList, error) {
+	backend, err := NewBackend(confContext, jwtConf.RevocationBackend, log, conf, memStore)
+	if err != nil {
+		return nil, err
+	}
+
+	return acjwt.NewRevocationList(conf.Context, jwtConf.RevocationURL, jwtConf.RevocationTTL, jwtConf.RevocationMaxStale, backend, memStore)
+}
+
 type protectedOptions struct {
 	epOpts   *handler.EndpointOptions
 	handler  http.Handler
-	memStore *cache.MemoryStore
+	memStore cache.Store
 	srvOpts  *server.Options
 }
 
@@ -9,6 +9,7 @@ import (
 const otelCollectorEndpoint = "localhost:4317"
 
 var defaultSettings = Settings{
+	CacheStore:               "memory",
 	DefaultPort:              8080,
 	Environment:              "",
 	HealthPath:               "/healthz",
@@ -39,8 +40,12 @@ type Settings struct {
 	AcceptForwardedURL            List   `hcl:"accept_forwarded_url,optional" docs:"Which {X-Forwarded-*} request HTTP header fields should be accepted to change the [request variables](../variables#request) {url}, {origin}, {protocol}, {host}, {port}. Valid values: {\"proto\"}, {\"host\"} and {\"port\"}. The port in a {X-Forwarded-Port} header takes precedence over a port in {X-Forwarded-Host}. Affects relative URL values for [{sp_acs_url}](saml) attribute and {redirect_uri} attribute within [{beta_oauth2}](oauth2) and [{oidc}](oidc)."`
 	BindAddress                   string `hcl:"bind_address,optional" docs:"A comma-separated list of addresses to bind." default:"*"`
 	CAFile                        string `hcl:"ca_file,optional" docs:"Adds the given PEM encoded CA certificate to the existing system certificate pool for all outgoing connections."`
+	CacheStore                    string `hcl:"cache_store,optional" docs:"Storage for cached tokens, e.g. of [{beta_token_request}](token_request) or [{oauth2}](oauth2) blocks. If set to {\"file\"} or {\"redis\"}, tokens are shared across Couper instances using the same storage and kept across restarts. Valid values: {\"memory\"}, {\"file\"} or {\"redis\"}." default:"memory"`
+	CacheStorePath                string `hcl:"cache_store_path,optional" docs:"Directory for the {\"file\"} cache store."`
+	CacheStoreURL                 string `hcl:"cache_store_url,optional" docs:"URL of the server for the {\"redis\"} cache store: {redis[s]://[[user]:password@]host[:port][/database]}."`
 	DefaultPort                   int    `hcl:"default_port,optional" docs:"Port which will be used if not explicitly specified per host within the [{hosts}](server) attribute." default:"8080"`
 	Environment                   string `hcl:"environment,optional" docs:"The [environment](../command-line#basic-options) Couper is to run in."`
+	H2C                           bool   `hcl:"h2c,optional" docs:"Enables HTTP/2 without TLS (h2c) for client connections, e.g. for gRPC clients. Servers with TLS support HTTP/2 anyway."`
 	HealthPath                    string `hcl:"health_path,optional" docs:"Health path for all configured servers and ports." default:"/healthz"`
 	LogFormat                     string `hcl:"log_format,optional" docs:"Tab/field based colored logs or JSON logs: {\"common\"} or {\"json\"}." default:"common"`
 	LogLevel                      string `hcl:"log_level,optional" docs:"Sets the log level: {\"panic\"}, {\"fatal\"}, {\"error\"}, {\"warn\"}, {\"info\"}, {\"debug\"}, {\"trace\"}." default:"info"`
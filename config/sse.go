@@ -0,0 +1,34 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+var _ Inline = &SSE{}
+
+type SSE struct {
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// Inline implements the <Inline> interface.
+func (s SSE) Inline() interface{} {
+	type Inline struct {
+		IdleTimeout string `hcl:"idle_timeout,optional" docs:"The [duration](#duration) between two chunks of an event stream after which the stream is closed." default:"60s"`
+		Retry       string `hcl:"retry,optional" docs:"The reconnection time [duration](#duration) sent to the client with a {retry} field at the start of the event stream."`
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (s SSE) Schema(inline bool) *hcl.BodySchema {
+	schema, _ := gohcl.ImpliedBodySchema(s)
+	if !inline {
+		return schema
+	}
+
+	schema, _ = gohcl.ImpliedBodySchema(s.Inline())
+
+	return schema
+}
@@ -0,0 +1,7 @@
+package config
+
+// Upstream represents the <config.Upstream> object.
+type Upstream struct {
+	Origin string `hcl:"origin" docs:"URL to connect to for backend requests."`
+	Weight *uint  `hcl:"weight,optional" docs:"Relative share of requests for the {\"weighted\"} strategy and of the hash ring for the {\"consistent_hash\"} strategy." default:"1"`
+}
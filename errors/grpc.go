@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/coupergateway/couper/internal/grpc"
+)
+
+// grpcKindStatus maps error kinds to gRPC status codes.
+var grpcKindStatus = map[string]int{
+	"access_control":                   grpc.StatusUnauthenticated,
+	"backend":                          grpc.StatusUnavailable,
+	"backend_openapi_validation":       grpc.StatusInvalidArgument,
+	"backend_timeout":                  grpc.StatusDeadlineExceeded,
+	"backend_unhealthy":                grpc.StatusUnavailable,
+	"beta_backend_rate_limit_exceeded": grpc.StatusResourceExhausted,
+	"insufficient_permissions":         grpc.StatusPermissionDenied,
+	"rate_limit_exceeded":              grpc.StatusResourceExhausted,
+}
+
+// GRPCStatus returns the gRPC status code for the given error. The most specific
+// error kind takes precedence over the HTTP status code of the error.
+func GRPCStatus(err GoError) int {
+	if gerr, ok := err.(*Error); ok {
+		for _, kind := range gerr.Kinds() {
+			if code, exist := grpcKindStatus[kind]; exist {
+				return code
+			}
+		}
+	}
+
+	// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+	switch err.HTTPStatus() {
+	case http.StatusBadRequest:
+		return grpc.StatusInvalidArgument
+	case http.StatusUnauthorized:
+		return grpc.StatusUnauthenticated
+	case http.StatusForbidden:
+		return grpc.StatusPermissionDenied
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return grpc.StatusUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return grpc.StatusUnavailable
+	case http.StatusGatewayTimeout:
+		return grpc.StatusDeadlineExceeded
+	case http.StatusInternalServerError:
+		return grpc.StatusInternal
+	default:
+		return grpc.StatusUnknown
+	}
+}
+
+// writeGRPCError writes a gRPC "trailers-only" response since gRPC clients
+// expect the status within the grpc-status header field instead of a body.
+func writeGRPCError(rw http.ResponseWriter, contentType string, err GoError) {
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set(grpc.StatusHeader, strconv.Itoa(GRPCStatus(err)))
+	rw.Header().Set(grpc.MessageHeader, url.PathEscape(err.Error()))
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,29 @@
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestGRPCStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err    errors.GoError
+		expect int
+	}{
+		{errors.AccessControl, 16},
+		{errors.JwtTokenExpired, 16},
+		{errors.InsufficientPermissions, 7},
+		{errors.Backend, 14},
+		{errors.BackendTimeout, 4},
+		{errors.BackendUnhealthy, 14},
+		{errors.BetaBackendRateLimitExceeded, 8},
+		{errors.RouteNotFound, 12},
+		{errors.ClientRequest, 3},
+		{errors.Server, 13},
+	} {
+		if code := errors.GRPCStatus(tc.err); code != tc.expect {
+			t.Errorf("%s: want grpc-status %d, got %d", tc.err.Error(), tc.expect, code)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/coupergateway/couper/assets"
 	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/internal/grpc"
 )
 
 var (
@@ -112,6 +113,11 @@ func (t *Template) WithError(err error) http.Handler {
 			t.ctxHandler.ServeHTTP(rw, req)
 		}
 
+		if ct := req.Header.Get("Content-Type"); grpc.IsGRPC(ct) || grpc.IsGRPCWeb(ct) {
+			writeGRPCError(rw, ct, goErr)
+			return
+		}
+
 		rw.WriteHeader(statusCode)
 
 		if req.Method == http.MethodHead { // Its fine to send CT
@@ -7,19 +7,43 @@ import "net/http"
 var Definitions = []*Error{
 	AccessControl,
 
+	AccessControl.Kind("api_key").Status(http.StatusUnauthorized),
+	AccessControl.Kind("api_key").Kind("api_key_missing").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("authorization").Status(http.StatusForbidden),
+
 	AccessControl.Kind("basic_auth").Status(http.StatusUnauthorized),
 	AccessControl.Kind("basic_auth").Kind("basic_auth_credentials_missing").Status(http.StatusUnauthorized),
 
+	AccessControl.Kind("client_certificate"),
+	AccessControl.Kind("client_certificate").Kind("client_certificate_missing").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("introspection").Status(http.StatusUnauthorized),
+	AccessControl.Kind("introspection").Kind("introspection_token_missing").Status(http.StatusUnauthorized),
+
 	AccessControl.Kind("jwt").Status(http.StatusUnauthorized),
+	AccessControl.Kind("jwt").Kind("jwt_certificate_binding_invalid").Status(http.StatusUnauthorized),
+	AccessControl.Kind("jwt").Kind("jwt_dpop_proof_invalid").Status(http.StatusUnauthorized),
 	AccessControl.Kind("jwt").Kind("jwt_token_expired").Status(http.StatusUnauthorized),
 	AccessControl.Kind("jwt").Kind("jwt_token_invalid").Status(http.StatusUnauthorized),
 	AccessControl.Kind("jwt").Kind("jwt_token_missing").Status(http.StatusUnauthorized),
+	AccessControl.Kind("jwt").Kind("jwt_token_revoked").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("ldap").Status(http.StatusUnauthorized),
+	AccessControl.Kind("ldap").Kind("ldap_credentials_missing").Status(http.StatusUnauthorized),
 
 	AccessControl.Kind("oauth2"),
 
 	AccessControl.Kind("saml2"),
 	AccessControl.Kind("saml2").Kind("saml"),
 
+	AccessControl.Kind("session").Status(http.StatusUnauthorized),
+	AccessControl.Kind("session").Kind("session_logout_token_invalid").Status(http.StatusBadRequest),
+	AccessControl.Kind("session").Kind("session_missing").Status(http.StatusUnauthorized),
+
+	AccessControl.Kind("signature").Status(http.StatusUnauthorized),
+	AccessControl.Kind("signature").Kind("signature_missing").Status(http.StatusUnauthorized),
+
 	AccessControl.Kind("insufficient_permissions").Context("api").Context("endpoint"),
 
 	Backend,
@@ -32,4 +56,6 @@ var Definitions = []*Error{
 	Endpoint,
 	Endpoint.Kind("sequence"),
 	Endpoint.Kind("unexpected_status"),
+
+	ClientRequest.Kind("rate_limit_exceeded").Status(http.StatusTooManyRequests).Context("api").Context("endpoint"),
 }
@@ -3,23 +3,41 @@
 package errors
 
 var (
-	BasicAuth                    = Definitions[1]
-	BasicAuthCredentialsMissing  = Definitions[2]
-	Jwt                          = Definitions[3]
-	JwtTokenExpired              = Definitions[4]
-	JwtTokenInvalid              = Definitions[5]
-	JwtTokenMissing              = Definitions[6]
-	Oauth2                       = Definitions[7]
-	Saml2                        = Definitions[8]
-	Saml                         = Definitions[9]
-	InsufficientPermissions      = Definitions[10]
-	BackendOpenapiValidation     = Definitions[12]
-	BetaBackendRateLimitExceeded = Definitions[13]
-	BackendTimeout               = Definitions[14]
-	BetaBackendTokenRequest      = Definitions[15]
-	BackendUnhealthy             = Definitions[16]
-	Sequence                     = Definitions[18]
-	UnexpectedStatus             = Definitions[19]
+	ApiKey                       = Definitions[1]
+	ApiKeyMissing                = Definitions[2]
+	Authorization                = Definitions[3]
+	BasicAuth                    = Definitions[4]
+	BasicAuthCredentialsMissing  = Definitions[5]
+	ClientCertificate            = Definitions[6]
+	ClientCertificateMissing     = Definitions[7]
+	Introspection                = Definitions[8]
+	IntrospectionTokenMissing    = Definitions[9]
+	Jwt                          = Definitions[10]
+	JwtCertificateBindingInvalid = Definitions[11]
+	JwtDpopProofInvalid          = Definitions[12]
+	JwtTokenExpired              = Definitions[13]
+	JwtTokenInvalid              = Definitions[14]
+	JwtTokenMissing              = Definitions[15]
+	JwtTokenRevoked              = Definitions[16]
+	Ldap                         = Definitions[17]
+	LdapCredentialsMissing       = Definitions[18]
+	Oauth2                       = Definitions[19]
+	Saml2                        = Definitions[20]
+	Saml                         = Definitions[21]
+	Session                      = Definitions[22]
+	SessionLogoutTokenInvalid    = Definitions[23]
+	SessionMissing               = Definitions[24]
+	Signature                    = Definitions[25]
+	SignatureMissing             = Definitions[26]
+	InsufficientPermissions      = Definitions[27]
+	BackendOpenapiValidation     = Definitions[29]
+	BetaBackendRateLimitExceeded = Definitions[30]
+	BackendTimeout               = Definitions[31]
+	BetaBackendTokenRequest      = Definitions[32]
+	BackendUnhealthy             = Definitions[33]
+	Sequence                     = Definitions[35]
+	UnexpectedStatus             = Definitions[36]
+	RateLimitExceeded            = Definitions[37]
 )
 
 // typeDefinitions holds all related error definitions which are
@@ -30,15 +48,32 @@ type typeDefinitions map[string]*Error
 // snake-name for fallback purposes. See TypeToSnake usage and reference.
 var types = typeDefinitions{
 	"access_control":                   AccessControl,
+	"api_key":                          ApiKey,
+	"api_key_missing":                  ApiKeyMissing,
+	"authorization":                    Authorization,
 	"basic_auth":                       BasicAuth,
 	"basic_auth_credentials_missing":   BasicAuthCredentialsMissing,
+	"client_certificate":               ClientCertificate,
+	"client_certificate_missing":       ClientCertificateMissing,
+	"introspection":                    Introspection,
+	"introspection_token_missing":      IntrospectionTokenMissing,
 	"jwt":                              Jwt,
+	"jwt_certificate_binding_invalid":  JwtCertificateBindingInvalid,
+	"jwt_dpop_proof_invalid":           JwtDpopProofInvalid,
 	"jwt_token_expired":                JwtTokenExpired,
 	"jwt_token_invalid":                JwtTokenInvalid,
 	"jwt_token_missing":                JwtTokenMissing,
+	"jwt_token_revoked":                JwtTokenRevoked,
+	"ldap":                             Ldap,
+	"ldap_credentials_missing":         LdapCredentialsMissing,
 	"oauth2":                           Oauth2,
 	"saml2":                            Saml2,
 	"saml":                             Saml,
+	"session":                          Session,
+	"session_logout_token_invalid":     SessionLogoutTokenInvalid,
+	"session_missing":                  SessionMissing,
+	"signature":                        Signature,
+	"signature_missing":                SignatureMissing,
 	"insufficient_permissions":         InsufficientPermissions,
 	"backend":                          Backend,
 	"backend_openapi_validation":       BackendOpenapiValidation,
@@ -49,6 +84,7 @@ var types = typeDefinitions{
 	"endpoint":                         Endpoint,
 	"sequence":                         Sequence,
 	"unexpected_status":                UnexpectedStatus,
+	"rate_limit_exceeded":              RateLimitExceeded,
 }
 
 // IsKnown tells the configuration callee if Couper
@@ -60,4 +96,4 @@ func IsKnown(errorType string) bool {
 
 // SuperTypesMapsByContext holds maps for error super-types to sub-types
 // by a given context block type (e.g. api or endpoint).
-var SuperTypesMapsByContext = map[string]map[string][]string{"api": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}}, "endpoint": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy", "sequence", "unexpected_status"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "endpoint": []string{"sequence", "unexpected_status"}}}
+var SuperTypesMapsByContext = map[string]map[string][]string{"api": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy", "rate_limit_exceeded"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}}, "endpoint": map[string][]string{"*": []string{"insufficient_permissions", "backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy", "sequence", "unexpected_status", "rate_limit_exceeded"}, "access_control": []string{"insufficient_permissions"}, "backend": []string{"backend_openapi_validation", "beta_backend_rate_limit_exceeded", "backend_timeout", "beta_backend_token_request", "backend_unhealthy"}, "endpoint": []string{"sequence", "unexpected_status"}}}
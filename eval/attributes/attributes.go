@@ -12,6 +12,13 @@ const (
 	DelFormParams  = "remove_form_params"
 	SetFormParams  = "set_form_params"
 
+	AddReqJSONFields = "add_request_json_fields"
+	DelReqJSONFields = "remove_request_json_fields"
+	SetReqJSONFields = "set_request_json_fields"
+	AddResJSONFields = "add_response_json_fields"
+	DelResJSONFields = "remove_response_json_fields"
+	SetResJSONFields = "set_response_json_fields"
+
 	SetResHeaders = "set_response_headers"
 	AddResHeaders = "add_response_headers"
 	DelResHeaders = "remove_response_headers"
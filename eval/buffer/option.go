@@ -162,6 +162,10 @@ func bufferWithAttribute(attrName string) Option {
 	switch attrName {
 	case attributes.AddFormParams, attributes.SetFormParams, attributes.DelFormParams:
 		return Request
+	case attributes.AddReqJSONFields, attributes.SetReqJSONFields, attributes.DelReqJSONFields:
+		return Request | JSONParseRequest
+	case attributes.AddResJSONFields, attributes.SetResJSONFields, attributes.DelResJSONFields:
+		return Response | JSONParseResponse
 	}
 	return None
 }
@@ -170,6 +174,8 @@ func bufferWithBlock(name string) Option {
 	switch name {
 	case "openapi":
 		return Response
+	case "fallback", "retry": // request body replay
+		return Request
 	}
 	return None
 }
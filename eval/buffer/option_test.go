@@ -15,6 +15,7 @@ func TestMustBuffer(t *testing.T) {
 	}{
 		{"no buffer", `endpoint "/" {}`, None},
 		{"buffer req/resp with openapi block", `openapi { file = "test.yaml" }`, Response},
+		{"buffer request with retry block", `retry {}`, Request},
 		{"buffer with context reference", `endpoint "/" { set_response_headers = { x = request.context } }`, Request},
 		{"buffer with nested context reference", `endpoint "/" { set_response_headers = { x = request.context.foo } }`, Request},
 		{"buffer request", `endpoint "/" { set_response_headers = { x = request } }`, Request},
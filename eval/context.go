@@ -49,9 +49,10 @@ type Context struct {
 	backendsFn        sync.Once
 	eval              *hcl.EvalContext
 	inner             context.Context
-	memStore          *cache.MemoryStore
+	memStore          cache.Store
 	memorize          map[string]interface{}
 	oauth2            map[string]config.OAuth2Authorization
+	oidcLogout        map[string]config.OIDCLogout
 	jwtSigningConfigs map[string]*lib.JWTSigningConfig
 	saml              []*config.SAML
 	syncedVariables   *SyncedVariables
@@ -240,6 +241,7 @@ func (c *Context) clone() *Context {
 		memStore:          c.memStore,
 		memorize:          make(map[string]interface{}),
 		oauth2:            c.oauth2,
+		oidcLogout:        c.oidcLogout,
 		jwtSigningConfigs: c.jwtSigningConfigs,
 		saml:              c.saml[:],
 		syncedVariables:   NewSyncedVariables(),
@@ -300,11 +302,21 @@ func newBerespValues(ctx context.Context, beresp *http.Response) (roundtripName
 		_ = beresp.Body.Close()
 	} // otherwise "default" gets closed by endpoint handler
 
-	berespVal = cty.ObjectVal(ContextMap{
+	berespMap := ContextMap{
 		variables.HTTPStatus: cty.NumberIntVal(int64(beresp.StatusCode)),
 		variables.JSONBody:   respJSONBody,
 		variables.Body:       respBody,
-	}.Merge(newVariable(ctx, beresp.Cookies(), beresp.Header)))
+	}
+
+	if backendName, ok := bereq.Context().Value(request.BackendName).(string); ok && backendName != "" {
+		berespMap[variables.BackendName] = cty.StringVal(backendName)
+	}
+
+	if cacheStatus, ok := bereq.Context().Value(request.ResponseCacheStatus).(string); ok {
+		berespMap[variables.CacheStatus] = cty.StringVal(cacheStatus)
+	}
+
+	berespVal = cty.ObjectVal(berespMap.Merge(newVariable(ctx, beresp.Cookies(), beresp.Header)))
 
 	return roundtripName, bereqVal, berespVal
 }
@@ -358,13 +370,17 @@ func (c *Context) WithOidcConfig(confs oidc.Configs) *Context {
 	if c.oauth2 == nil {
 		c.oauth2 = make(map[string]config.OAuth2Authorization)
 	}
+	if c.oidcLogout == nil {
+		c.oidcLogout = make(map[string]config.OIDCLogout)
+	}
 	for _, oidcConf := range confs {
 		c.oauth2[oidcConf.Name] = oidcConf
+		c.oidcLogout[oidcConf.Name] = oidcConf
 	}
 	return c
 }
 
-func (c *Context) WithMemStore(store *cache.MemoryStore) *Context {
+func (c *Context) WithMemStore(store cache.Store) *Context {
 	c.cloneMu.Lock()
 	defer c.cloneMu.Unlock()
 
@@ -437,13 +453,21 @@ func (c *Context) updateRequestRelatedFunctions(origin *url.URL) {
 	} else {
 		c.eval.Functions[lib.FnOAuthAuthorizationURL] = lib.NoOpOAuthAuthorizationURLFunction
 	}
+	if len(c.oidcLogout) > 0 {
+		c.eval.Functions[lib.FnOidcLogoutURL] = lib.NewOidcLogoutURLFunction(c.oidcLogout, origin)
+	} else {
+		c.eval.Functions[lib.FnOidcLogoutURL] = lib.NoOpOidcLogoutURLFunction
+	}
 	c.eval.Functions[lib.FnOAuthVerifier] = lib.NewOAuthCodeVerifierFunction(c.getCodeVerifier)
 	c.eval.Functions[lib.InternalFnOAuthHashedVerifier] = lib.NewOAuthCodeChallengeFunction(c.getCodeVerifier)
 
 	if len(c.saml) > 0 {
-		samlfn := lib.NewSamlSsoURLFunction(c.saml, origin)
-		c.eval.Functions[lib.FnSamlSsoURL] = samlfn
+		for name, samlfn := range lib.NewSamlFunctions(c.saml, origin) {
+			c.eval.Functions[name] = samlfn
+		}
 	} else {
+		c.eval.Functions[lib.FnSamlLogoutURL] = lib.NoOpSamlLogoutURLFunction
+		c.eval.Functions[lib.FnSamlSpMetadata] = lib.NoOpSamlSpMetadataFunction
 		c.eval.Functions[lib.FnSamlSsoURL] = lib.NoOpSamlSsoURLFunction
 	}
 }
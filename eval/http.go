@@ -169,7 +169,11 @@ func ApplyRequestContext(httpCtx *hcl.EvalContext, body *hclsyntax.Body, req *ht
 		req.URL.RawQuery = strings.ReplaceAll(values.Encode(), "+", "%20")
 	}
 
-	return getFormParams(httpCtx, req, attrs)
+	if err = getFormParams(httpCtx, req, attrs); err != nil {
+		return err
+	}
+
+	return applyRequestJSONFields(httpCtx, req, attrs)
 }
 
 func getFormParams(ctx *hcl.EvalContext, req *http.Request, attrs map[string]*hcl.Attribute) error {
@@ -369,6 +373,10 @@ func ApplyResponseContext(ctx *hcl.EvalContext, body *hclsyntax.Body, beresp *ht
 		return nil
 	}
 
+	if err := applyResponseJSONFields(ctx, body, beresp); err != nil {
+		return err
+	}
+
 	if attr, ok := body.Attributes["set_response_status"]; ok {
 		_, err := ApplyResponseStatus(ctx, attr, beresp)
 		return err
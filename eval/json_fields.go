@@ -0,0 +1,480 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval/attributes"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+// jsonPath contains the reference tokens of a JSON pointer (RFC 6901)
+// or of a JSONPath expression in dot or bracket notation.
+type jsonPath []string
+
+func (p jsonPath) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteString("/")
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
+
+// defaultResponseBodyLimit is the maximum size of a response body modified
+// by JSON fields if no endpoint limit applies.
+const defaultResponseBodyLimit = int64(64 << 20)
+
+type jsonField struct {
+	path  jsonPath
+	value interface{}
+}
+
+// jsonFieldOps are the evaluated JSON body modifiers of a context block.
+type jsonFieldOps struct {
+	del []jsonPath
+	set []jsonField
+	add []jsonField
+}
+
+func applyRequestJSONFields(ctx *hcl.EvalContext, req *http.Request, attrs map[string]*hcl.Attribute) error {
+	ops, err := newJSONFieldOps(ctx, attrs, attributes.DelReqJSONFields, attributes.SetReqJSONFields, attributes.AddReqJSONFields)
+	if err != nil || ops == nil {
+		return err
+	}
+
+	log := req.Context().Value(request.LogEntry).(*logrus.Entry).WithContext(req.Context())
+
+	if ct := req.Header.Get("Content-Type"); !isJSONMediaType(ct) {
+		log.WithError(errors.Evaluation.Label("request_json_fields").
+			Messagef("content-type mismatch: %s", ct)).Warn()
+		return nil
+	}
+
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return errors.Evaluation.Label("request_json_fields").With(err)
+	}
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return errors.Evaluation.Label("request_json_fields").With(err)
+	}
+
+	result, err := ops.apply(b, log, "request_json_fields")
+	if err != nil {
+		log.WithError(errors.Evaluation.Label("request_json_fields").With(err)).Warn()
+		return nil
+	}
+
+	SetBody(req, result)
+	return nil
+}
+
+func applyResponseJSONFields(ctx *hcl.EvalContext, body hcl.Body, beresp *http.Response) error {
+	attrs, err := getAllAttributes(body)
+	if err != nil {
+		return err
+	}
+
+	ops, err := newJSONFieldOps(ctx, attrs, attributes.DelResJSONFields, attributes.SetResJSONFields, attributes.AddResJSONFields)
+	if err != nil || ops == nil || beresp.Body == nil {
+		return err
+	}
+
+	log := logrus.NewEntry(logrus.StandardLogger())
+	if beresp.Request != nil {
+		if entry, ok := beresp.Request.Context().Value(request.LogEntry).(*logrus.Entry); ok {
+			log = entry.WithContext(beresp.Request.Context())
+		}
+	}
+
+	if ct := beresp.Header.Get("Content-Type"); !isJSONMediaType(ct) {
+		log.WithError(errors.Evaluation.Label("response_json_fields").
+			Messagef("content-type mismatch: %s", ct)).Warn()
+		return nil
+	}
+
+	// compressed bodies are decoded only if the response has to be buffered anyway
+	if ce := beresp.Header.Get("Content-Encoding"); ce != "" {
+		log.WithError(errors.Evaluation.Label("response_json_fields").
+			Messagef("content-encoding not supported: %s", ce)).Warn()
+		return nil
+	}
+
+	bodyLimit := defaultResponseBodyLimit
+	if beresp.Request != nil {
+		if limit, ok := beresp.Request.Context().Value(request.ResponseBodyLimit).(int64); ok {
+			bodyLimit = limit
+		}
+	}
+
+	if beresp.ContentLength > bodyLimit {
+		log.WithError(errors.Evaluation.Label("response_json_fields").
+			Message("body size exceeded: " + units.HumanSize(float64(bodyLimit)))).Warn()
+		return nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(beresp.Body, bodyLimit+1))
+	if err != nil {
+		_ = beresp.Body.Close()
+		return errors.Evaluation.Label("response_json_fields").With(err)
+	}
+
+	if int64(len(b)) > bodyLimit {
+		log.WithError(errors.Evaluation.Label("response_json_fields").
+			Message("body size exceeded: " + units.HumanSize(float64(bodyLimit)))).Warn()
+		beresp.Body = NewReadCloser(io.MultiReader(bytes.NewReader(b), beresp.Body), beresp.Body)
+		return nil
+	}
+	_ = beresp.Body.Close()
+
+	result, err := ops.apply(b, log, "response_json_fields")
+	if err != nil {
+		log.WithError(errors.Evaluation.Label("response_json_fields").With(err)).Warn()
+		result = b
+	}
+
+	beresp.Body = io.NopCloser(bytes.NewReader(result))
+	beresp.ContentLength = int64(len(result))
+	beresp.Header.Set("Content-Length", strconv.Itoa(len(result)))
+	return nil
+}
+
+// newJSONFieldOps evaluates the given modifier attributes. The result is nil if none of them is configured.
+func newJSONFieldOps(ctx *hcl.EvalContext, attrs map[string]*hcl.Attribute, delName, setName, addName string) (*jsonFieldOps, error) {
+	attrDel, okDel := attrs[delName]
+	attrSet, okSet := attrs[setName]
+	attrAdd, okAdd := attrs[addName]
+
+	if !okAdd && !okDel && !okSet {
+		return nil, nil
+	}
+
+	ops := &jsonFieldOps{}
+
+	if okDel {
+		val, err := Value(ctx, attrDel.Expr)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range seetie.ValueToStringSlice(val) {
+			path, perr := parseJSONPath(key)
+			if perr != nil {
+				return nil, errors.Evaluation.Label(delName).With(perr)
+			}
+			ops.del = append(ops.del, path)
+		}
+	}
+
+	var err error
+	if okSet {
+		if ops.set, err = evalJSONFields(ctx, attrSet); err != nil {
+			return nil, err
+		}
+	}
+
+	if okAdd {
+		if ops.add, err = evalJSONFields(ctx, attrAdd); err != nil {
+			return nil, err
+		}
+	}
+
+	return ops, nil
+}
+
+func evalJSONFields(ctx *hcl.EvalContext, attr *hcl.Attribute) ([]jsonField, error) {
+	val, err := Value(ctx, attr.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if val.IsNull() {
+		return nil, nil
+	}
+
+	if valType := val.Type(); !(valType.IsObjectType() || valType.IsMapType()) {
+		return nil, errors.Evaluation.Label(attr.Name).Message("value must be an object")
+	}
+
+	keys := make([]string, 0, val.LengthInt())
+	values := val.AsValueMap()
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]jsonField, 0, len(keys))
+	for _, key := range keys {
+		path, perr := parseJSONPath(key)
+		if perr != nil {
+			return nil, errors.Evaluation.Label(attr.Name).With(perr)
+		}
+
+		value, verr := ctyToJSON(values[key])
+		if verr != nil {
+			return nil, errors.Evaluation.Label(attr.Name).Messagef("%s: %s", key, verr)
+		}
+
+		fields = append(fields, jsonField{path: path, value: value})
+	}
+
+	return fields, nil
+}
+
+func ctyToJSON(val cty.Value) (interface{}, error) {
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("unknown value")
+	}
+
+	b, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(b)
+}
+
+// apply modifies the given JSON document in hierarchical and logical order: delete, set, add.
+// Fields which cannot be modified due to the structure of the document are logged and skipped.
+func (ops *jsonFieldOps) apply(b []byte, log *logrus.Entry, label string) ([]byte, error) {
+	doc, err := decodeJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range ops.del {
+		doc = removeJSONField(doc, path)
+	}
+
+	for _, field := range ops.set {
+		if doc, err = setJSONField(doc, field.path, field.value, false); err != nil {
+			log.WithError(errors.Evaluation.Label(label).Messagef("%s: %s", field.path, err)).Warn()
+		}
+	}
+
+	for _, field := range ops.add {
+		if doc, err = setJSONField(doc, field.path, field.value, true); err != nil {
+			log.WithError(errors.Evaluation.Label(label).Messagef("%s: %s", field.path, err)).Warn()
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// decodeJSON keeps numbers as they are to prevent a loss of precision.
+func decodeJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+
+	return doc, nil
+}
+
+// setJSONField sets the value at the given path and creates missing containers along the path;
+// an array if the next token is "0" or "-", an object otherwise. With insert, an array element is
+// inserted at the given index like the JSON Patch (RFC 6902) "add" operation instead of being
+// replaced. The "-" index or the length of an array appends the value.
+func setJSONField(node interface{}, path jsonPath, value interface{}, insert bool) (interface{}, error) {
+	token := path[0]
+	last := len(path) == 1
+
+	switch n := node.(type) {
+	case nil: // create missing containers
+		if token == "-" || token == "0" {
+			return setJSONField([]interface{}{}, path, value, insert)
+		}
+		return setJSONField(map[string]interface{}{}, path, value, insert)
+	case map[string]interface{}:
+		if last {
+			n[token] = value
+			return n, nil
+		}
+
+		child, err := setJSONField(n[token], path[1:], value, insert)
+		if err != nil {
+			return n, err
+		}
+		n[token] = child
+		return n, nil
+	case []interface{}:
+		if token == "-" {
+			if !last {
+				return n, fmt.Errorf("index \"-\" references a nonexistent element")
+			}
+			return append(n, value), nil
+		}
+
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 {
+			return n, fmt.Errorf("invalid array index: %q", token)
+		}
+
+		if last && (insert || idx == len(n)) && idx <= len(n) {
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = value
+			return n, nil
+		}
+
+		if idx >= len(n) {
+			return n, fmt.Errorf("array index out of range: %d", idx)
+		}
+
+		if last {
+			n[idx] = value
+			return n, nil
+		}
+
+		child, err := setJSONField(n[idx], path[1:], value, insert)
+		if err != nil {
+			return n, err
+		}
+		n[idx] = child
+		return n, nil
+	default:
+		return n, fmt.Errorf("cannot set field %q of a non-container value", token)
+	}
+}
+
+// removeJSONField removes the value at the given path. Nonexistent paths are ignored.
+func removeJSONField(node interface{}, path jsonPath) interface{} {
+	token := path[0]
+	last := len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, exist := n[token]
+		if !exist {
+			return n
+		}
+
+		if last {
+			delete(n, token)
+		} else {
+			n[token] = removeJSONField(child, path[1:])
+		}
+		return n
+	case []interface{}:
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 || idx >= len(n) {
+			return n
+		}
+
+		if last {
+			return append(n[:idx], n[idx+1:]...)
+		}
+		n[idx] = removeJSONField(n[idx], path[1:])
+		return n
+	}
+
+	return node
+}
+
+// parseJSONPath parses a JSON pointer, e.g. "/items/0/name", or a JSONPath expression
+// in dot or bracket notation, e.g. "$.items[0].name" or "$['items'][0]['name']".
+func parseJSONPath(key string) (jsonPath, error) {
+	var (
+		path jsonPath
+		err  error
+	)
+
+	switch {
+	case strings.HasPrefix(key, "/"):
+		unescape := strings.NewReplacer("~1", "/", "~0", "~")
+		for _, token := range strings.Split(key[1:], "/") {
+			path = append(path, unescape.Replace(token))
+		}
+	case strings.HasPrefix(key, "$"):
+		path, err = parseJSONPathExpression(key[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid json path %q: %w", key, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid json path %q: must start with \"/\" or \"$\"", key)
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid json path %q: the document root cannot be modified", key)
+	}
+
+	return path, nil
+}
+
+func parseJSONPathExpression(expr string) (jsonPath, error) {
+	var path jsonPath
+
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			end := strings.IndexAny(expr[1:], ".[")
+			if end < 0 {
+				end = len(expr) - 1
+			}
+
+			name := expr[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty field name")
+			}
+			if name == "*" {
+				return nil, fmt.Errorf("wildcards are not supported")
+			}
+
+			path = append(path, name)
+			expr = expr[end+1:]
+		case '[':
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("missing \"]\"")
+			}
+
+			selector := expr[1:end]
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				path = append(path, selector[1:len(selector)-1])
+			} else if _, err := strconv.Atoi(selector); err == nil || selector == "-" {
+				path = append(path, selector)
+			} else {
+				return nil, fmt.Errorf("unsupported selector: %q", selector)
+			}
+
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q", expr[0])
+		}
+	}
+
+	return path, nil
+}
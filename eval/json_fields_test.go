@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		exp    jsonPath
+		expErr string
+	}{
+		{"/a/b", jsonPath{"a", "b"}, ""},
+		{"/a~1b/c~0d", jsonPath{"a/b", "c~d"}, ""},
+		{"/items/-", jsonPath{"items", "-"}, ""},
+		{"$.a.b", jsonPath{"a", "b"}, ""},
+		{"$.items[0].name", jsonPath{"items", "0", "name"}, ""},
+		{"$['a.b'][\"c\"]", jsonPath{"a.b", "c"}, ""},
+		{"$", nil, `invalid json path "$": the document root cannot be modified`},
+		{"a.b", nil, `invalid json path "a.b": must start with "/" or "$"`},
+		{"$..a", nil, `invalid json path "$..a": empty field name`},
+		{"$.items[*]", nil, `invalid json path "$.items[*]": unsupported selector: "*"`},
+		{"$.items[0", nil, `invalid json path "$.items[0": missing "]"`},
+	} {
+		t.Run(tc.key, func(st *testing.T) {
+			path, err := parseJSONPath(tc.key)
+			if tc.expErr != "" {
+				if err == nil || err.Error() != tc.expErr {
+					st.Errorf("want error %q, got %v", tc.expErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(path, tc.exp) {
+				st.Errorf("want %#v, got %#v", tc.exp, path)
+			}
+		})
+	}
+}
+
+func TestJSONFieldOps_apply(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+
+	for _, tc := range []struct {
+		name   string
+		ops    *jsonFieldOps
+		body   string
+		exp    string
+		expLog string
+	}{
+		{"remove", &jsonFieldOps{del: []jsonPath{{"a"}, {"b", "1"}, {"c", "x"}}}, `{"a":1,"b":[1,2,3]}`, `{"b":[1,3]}`, ""},
+		{"set", &jsonFieldOps{set: []jsonField{{jsonPath{"b", "0"}, "x"}, {jsonPath{"c", "d"}, true}}}, `{"b":[1,2]}`, `{"b":["x",2],"c":{"d":true}}`, ""},
+		{"add", &jsonFieldOps{add: []jsonField{{jsonPath{"b", "0"}, "x"}, {jsonPath{"b", "-"}, "y"}}}, `{"b":[1]}`, `{"b":["x",1,"y"]}`, ""},
+		{"create array", &jsonFieldOps{add: []jsonField{{jsonPath{"b", "-"}, "y"}}}, `{}`, `{"b":["y"]}`, ""},
+		{"out of range", &jsonFieldOps{set: []jsonField{{jsonPath{"b", "5"}, "x"}}}, `{"b":[1]}`, `{"b":[1]}`, "expression evaluation error: json_fields: /b/5: array index out of range: 5"},
+		{"scalar", &jsonFieldOps{set: []jsonField{{jsonPath{"a", "b"}, "x"}}}, `{"a":1}`, `{"a":1}`, `expression evaluation error: json_fields: /a/b: cannot set field "b" of a non-container value`},
+		{"precision", &jsonFieldOps{}, `{"n":1.000000000000000000001}`, `{"n":1.000000000000000000001}`, ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			hook.Reset()
+
+			b, err := tc.ops.apply([]byte(tc.body), logrus.NewEntry(logger), "json_fields")
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			if string(b) != tc.exp {
+				st.Errorf("want %s, got %s", tc.exp, string(b))
+			}
+
+			if tc.expLog != "" {
+				entry := hook.LastEntry()
+				if entry == nil {
+					st.Fatalf("want log %q", tc.expLog)
+				}
+				if logErr, ok := entry.Data[logrus.ErrorKey].(errors.GoError); !ok || logErr.LogError() != tc.expLog {
+					st.Errorf("want log %q, got %v", tc.expLog, entry.Data)
+				}
+			}
+		})
+	}
+}
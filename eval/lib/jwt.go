@@ -12,6 +12,7 @@ import (
 	"github.com/zclconf/go-cty/cty/function"
 	"github.com/zclconf/go-cty/cty/function/stdlib"
 
+	"github.com/coupergateway/couper/accesscontrol/jwe"
 	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/reader"
@@ -22,6 +23,7 @@ const FnJWTSign = "jwt_sign"
 
 type JWTSigningConfig struct {
 	Claims             config.Claims
+	Encrypter          *jwe.Encrypter
 	Headers            hcl.Expression
 	Key                interface{}
 	SignatureAlgorithm string
@@ -51,10 +53,12 @@ func getKey(keyBytes []byte, signatureAlgorithm string) (interface{}, error) {
 		parseErr error
 	)
 	key = keyBytes
-	if strings.HasPrefix(signatureAlgorithm, "RS") {
+	if strings.HasPrefix(signatureAlgorithm, "RS") || strings.HasPrefix(signatureAlgorithm, "PS") {
 		key, parseErr = jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
 	} else if strings.HasPrefix(signatureAlgorithm, "ES") {
 		key, parseErr = jwt.ParseECPrivateKeyFromPEM(keyBytes)
+	} else if signatureAlgorithm == "EdDSA" {
+		key, parseErr = jwt.ParseEdPrivateKeyFromPEM(keyBytes)
 	}
 
 	return key, parseErr
@@ -82,8 +86,14 @@ func NewJWTSigningConfigFromJWTSigningProfile(j *config.JWTSigningProfile, algCh
 		return nil, err
 	}
 
+	encrypter, err := newEncrypter(j)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &JWTSigningConfig{
 		Claims:             j.Claims,
+		Encrypter:          encrypter,
 		Headers:            j.Headers,
 		Key:                key,
 		SignatureAlgorithm: j.SignatureAlgorithm,
@@ -92,6 +102,27 @@ func NewJWTSigningConfigFromJWTSigningProfile(j *config.JWTSigningProfile, algCh
 	return c, nil
 }
 
+func newEncrypter(j *config.JWTSigningProfile) (*jwe.Encrypter, error) {
+	if j.KeyManagementAlgorithm == "" {
+		if j.EncryptionKey != "" || j.EncryptionKeyFile != "" || j.ContentEncryptionAlgorithm != "" {
+			return nil, fmt.Errorf("key_management_algorithm is required for encryption")
+		}
+		return nil, nil
+	}
+
+	keyBytes, err := reader.ReadFromAttrFile("jwt_signing_profile encryption key", j.EncryptionKey, j.EncryptionKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	contentEncryptionAlgorithm := j.ContentEncryptionAlgorithm
+	if contentEncryptionAlgorithm == "" {
+		contentEncryptionAlgorithm = "A256GCM"
+	}
+
+	return jwe.NewEncrypter(j.KeyManagementAlgorithm, contentEncryptionAlgorithm, keyBytes)
+}
+
 func NewJWTSigningConfigFromJWT(j *config.JWT) (*JWTSigningConfig, error) {
 	if j.SigningTTL == "" {
 		return nil, nil
@@ -216,6 +247,14 @@ func NewJwtSignFunction(ctx *hcl.EvalContext, jwtSigningConfigs map[string]*JWTS
 				return cty.StringVal(""), err
 			}
 
+			if signingConfig.Encrypter != nil {
+				// nested JWT, see RFC 7519, section 5.2
+				tokenString, err = signingConfig.Encrypter.Encrypt([]byte(tokenString), map[string]interface{}{"cty": "JWT"})
+				if err != nil {
+					return cty.StringVal(""), err
+				}
+			}
+
 			return cty.StringVal(tokenString), nil
 		},
 	})
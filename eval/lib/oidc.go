@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/coupergateway/couper/config"
+)
+
+const FnOidcLogoutURL = "oidc_logout_url"
+
+var NoOpOidcLogoutURLFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "oidc_label",
+			Type: cty.String,
+		},
+		{
+			Name: "id_token_hint",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		if len(args) > 0 {
+			return cty.StringVal(""), fmt.Errorf("missing oidc block with referenced label %q", args[0].AsString())
+		}
+		return cty.StringVal(""), fmt.Errorf("missing oidc definitions")
+	},
+})
+
+// NewOidcLogoutURLFunction creates the function for the RP-initiated logout URL,
+// see OpenID Connect RP-Initiated Logout 1.0, section 2.
+func NewOidcLogoutURLFunction(oidcs map[string]config.OIDCLogout, origin *url.URL) function.Function {
+	emptyStringVal := cty.StringVal("")
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "oidc_label",
+				Type: cty.String,
+			},
+			{
+				Name:      "id_token_hint",
+				Type:      cty.String,
+				AllowNull: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			label := args[0].AsString()
+			oidc, exist := oidcs[label]
+			if !exist {
+				return NoOpOidcLogoutURLFunction.Call(args)
+			}
+
+			endSessionEndpoint, err := oidc.GetEndSessionEndpoint()
+			if err != nil {
+				return emptyStringVal, err
+			}
+			if endSessionEndpoint == "" {
+				return emptyStringVal, fmt.Errorf("missing end_session_endpoint in OpenID configuration")
+			}
+
+			logoutURL, err := url.Parse(endSessionEndpoint)
+			if err != nil {
+				return emptyStringVal, err
+			}
+
+			query := logoutURL.Query()
+			query.Set("client_id", oidc.GetClientID())
+			if !args[1].IsNull() && args[1].AsString() != "" {
+				query.Set("id_token_hint", args[1].AsString())
+			}
+
+			if redirectURI := oidc.GetPostLogoutRedirectURI(); redirectURI != "" {
+				absRedirectURI, err := AbsoluteURL(redirectURI, origin)
+				if err != nil {
+					return emptyStringVal, err
+				}
+				query.Set("post_logout_redirect_uri", absRedirectURI)
+			}
+			logoutURL.RawQuery = query.Encode()
+
+			return cty.StringVal(logoutURL.String()), nil
+		},
+	})
+}
@@ -1,12 +1,15 @@
 package lib
 
 import (
+	"crypto/rsa"
+	"crypto/tls"
 	"encoding/xml"
 	"fmt"
 	"net/url"
 
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 
@@ -14,6 +17,8 @@ import (
 )
 
 const (
+	FnSamlLogoutURL         = "saml_logout_url"
+	FnSamlSpMetadata        = "saml_sp_metadata"
 	FnSamlSsoURL            = "saml_sso_url"
 	NameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
 )
@@ -26,32 +31,81 @@ var NoOpSamlSsoURLFunction = function.New(&function.Spec{
 		},
 	},
 	Type: function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
-		if len(args) > 0 {
-			return cty.StringVal(""), fmt.Errorf("missing saml block with referenced label %q", args[0].AsString())
-		}
-		return cty.StringVal(""), fmt.Errorf("missing saml definitions")
+	Impl: noOpSamlImpl,
+})
+
+var NoOpSamlSpMetadataFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "saml_label",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: noOpSamlImpl,
+})
+
+var NoOpSamlLogoutURLFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "saml_label",
+			Type: cty.String,
+		},
+		{
+			Name: "name_id",
+			Type: cty.String,
+		},
+		{
+			Name: "session_index",
+			Type: cty.String,
+		},
 	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: noOpSamlImpl,
 })
 
-func NewSamlSsoURLFunction(configs []*config.SAML, origin *url.URL) function.Function {
-	type entity struct {
-		config     *config.SAML
-		descriptor *types.EntityDescriptor
-		err        error
+func noOpSamlImpl(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+	if len(args) > 0 {
+		return cty.StringVal(""), fmt.Errorf("missing saml block with referenced label %q", args[0].AsString())
 	}
+	return cty.StringVal(""), fmt.Errorf("missing saml definitions")
+}
 
-	samlEntities := make(map[string]*entity)
+type samlEntity struct {
+	config     *config.SAML
+	descriptor *types.EntityDescriptor
+	err        error
+}
+
+// NewSamlFunctions creates the saml related functions for the given origin of the client request.
+func NewSamlFunctions(configs []*config.SAML, origin *url.URL) map[string]function.Function {
+	samlEntities := make(map[string]*samlEntity)
 	for _, conf := range configs {
 		metadata := &types.EntityDescriptor{}
 		err := xml.Unmarshal(conf.MetadataBytes, metadata)
-		samlEntities[conf.Name] = &entity{
+		samlEntities[conf.Name] = &samlEntity{
 			config:     conf,
 			descriptor: metadata,
 			err:        err,
 		}
 	}
 
+	serviceProvider := func(label string) (*saml2.SAMLServiceProvider, error) {
+		ent := samlEntities[label]
+		if ent.err != nil {
+			return nil, ent.err
+		}
+		return NewSAMLServiceProvider(ent.config, ent.descriptor, origin)
+	}
+
+	return map[string]function.Function{
+		FnSamlLogoutURL:  newSamlLogoutURLFunction(samlEntities, serviceProvider),
+		FnSamlSpMetadata: newSamlSpMetadataFunction(samlEntities, serviceProvider),
+		FnSamlSsoURL:     newSamlSsoURLFunction(samlEntities, serviceProvider),
+	}
+}
+
+func newSamlSsoURLFunction(samlEntities map[string]*samlEntity, serviceProvider func(string) (*saml2.SAMLServiceProvider, error)) function.Function {
 	return function.New(&function.Spec{
 		Params: []function.Parameter{
 			{
@@ -62,47 +116,195 @@ func NewSamlSsoURLFunction(configs []*config.SAML, origin *url.URL) function.Fun
 		Type: function.StaticReturnType(cty.String),
 		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
 			label := args[0].AsString()
-			ent, exist := samlEntities[label]
-			if !exist {
+			if _, exist := samlEntities[label]; !exist {
 				return NoOpSamlSsoURLFunction.Call(args)
 			}
 
-			metadata := ent.descriptor
-			var ssoURL string
-			for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
-				if ssoService.Binding == "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" {
-					ssoURL = ssoService.Location
-					continue
+			sp, err := serviceProvider(label)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			// The redirect binding signs the query instead of the request document.
+			doc, err := sp.BuildAuthRequestDocumentNoSig()
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			samlSsoURL, err := sp.BuildAuthURLRedirect("", doc)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(samlSsoURL), nil
+		},
+	})
+}
+
+func newSamlSpMetadataFunction(samlEntities map[string]*samlEntity, serviceProvider func(string) (*saml2.SAMLServiceProvider, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			if _, exist := samlEntities[label]; !exist {
+				return NoOpSamlSpMetadataFunction.Call(args)
+			}
+
+			sp, err := serviceProvider(label)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			metadata, err := sp.Metadata()
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			if sp.ServiceProviderSLOURL != "" {
+				metadata.SPSSODescriptor.SingleLogoutServices = []types.Endpoint{
+					{Binding: saml2.BindingHttpRedirect, Location: sp.ServiceProviderSLOURL},
+					{Binding: saml2.BindingHttpPost, Location: sp.ServiceProviderSLOURL},
 				}
 			}
 
-			nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats)
+			b, err := xml.MarshalIndent(metadata, "", "  ")
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(xml.Header + string(b)), nil
+		},
+	})
+}
+
+func newSamlLogoutURLFunction(samlEntities map[string]*samlEntity, serviceProvider func(string) (*saml2.SAMLServiceProvider, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+			{
+				Name: "name_id",
+				Type: cty.String,
+			},
+			{
+				Name:      "session_index",
+				Type:      cty.String,
+				AllowNull: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			ent, exist := samlEntities[label]
+			if !exist {
+				return NoOpSamlLogoutURLFunction.Call(args)
+			}
+
+			if ent.config.SpKeyPair == nil {
+				return cty.StringVal(""), fmt.Errorf("saml %q: sp_private_key required to sign logout requests", label)
+			}
 
-			absAcsURL, err := AbsoluteURL(ent.config.SpAcsURL, origin)
+			sp, err := serviceProvider(label)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
 
-			sp := &saml2.SAMLServiceProvider{
-				AssertionConsumerServiceURL: absAcsURL,
-				IdentityProviderSSOURL:      ssoURL,
-				ServiceProviderIssuer:       ent.config.SpEntityID,
-				SignAuthnRequests:           false,
+			if sp.IdentityProviderSLOURL == "" {
+				return cty.StringVal(""), fmt.Errorf("saml %q: no SingleLogoutService with HTTP-Redirect binding in the IdP metadata", label)
 			}
-			if nameIDFormat != "" {
-				sp.NameIdFormat = nameIDFormat
+
+			var sessionIndex string
+			if !args[2].IsNull() {
+				sessionIndex = args[2].AsString()
 			}
 
-			samlSsoURL, err := sp.BuildAuthURL("")
+			doc, err := sp.BuildLogoutRequestDocumentNoSig(args[1].AsString(), sessionIndex)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
 
-			return cty.StringVal(samlSsoURL), nil
+			logoutURL, err := sp.BuildLogoutURLRedirect("", doc)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(logoutURL), nil
 		},
 	})
 }
 
+// NewSAMLServiceProvider creates the service provider for the given saml block and IdP metadata.
+// Relative SP URLs are resolved against the given origin. Requests are signed if the
+// saml block configures a key pair.
+func NewSAMLServiceProvider(conf *config.SAML, metadata *types.EntityDescriptor, origin *url.URL) (*saml2.SAMLServiceProvider, error) {
+	if metadata.IDPSSODescriptor == nil {
+		return nil, fmt.Errorf("missing IDPSSODescriptor in IdP metadata")
+	}
+
+	absAcsURL, err := AbsoluteURL(conf.SpAcsURL, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &saml2.SAMLServiceProvider{
+		AssertionConsumerServiceURL: absAcsURL,
+		AudienceURI:                 conf.SpEntityID,
+		IdentityProviderIssuer:      metadata.EntityID,
+		ServiceProviderIssuer:       conf.SpEntityID,
+	}
+
+	for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
+		if ssoService.Binding == saml2.BindingHttpRedirect {
+			sp.IdentityProviderSSOURL = ssoService.Location
+		}
+	}
+
+	for _, sloService := range metadata.IDPSSODescriptor.SingleLogoutServices {
+		if sloService.Binding == saml2.BindingHttpRedirect {
+			sp.IdentityProviderSLOURL = sloService.Location
+		}
+	}
+
+	if nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats); nameIDFormat != "" {
+		sp.NameIdFormat = nameIDFormat
+	}
+
+	if conf.SpSloURL != "" {
+		if sp.ServiceProviderSLOURL, err = AbsoluteURL(conf.SpSloURL, origin); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.SpKeyPair != nil {
+		sp.SPKeyStore = dsig.TLSCertKeyStore(*conf.SpKeyPair)
+		sp.SignAuthnRequests = true
+	}
+
+	return sp, nil
+}
+
+// NewSAMLKeyPair parses the PEM encoded certificate and RSA private key of a Service Provider.
+func NewSAMLKeyPair(certificate, privateKey []byte) (*tls.Certificate, error) {
+	keyPair, err := tls.X509KeyPair(certificate, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := keyPair.PrivateKey.(*rsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("sp_private_key: RSA key required")
+	}
+
+	return &keyPair, nil
+}
+
 func getNameIDFormat(supportedNameIDFormats []types.NameIDFormat) string {
 	nameIDFormat := ""
 	if isSupportedNameIDFormat(supportedNameIDFormats, NameIDFormatUnspecified) {
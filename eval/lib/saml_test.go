@@ -4,14 +4,18 @@ import (
 	"bytes"
 	"compress/flate"
 	"context"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"encoding/xml"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/russellhaering/gosaml2/types"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/coupergateway/couper/config/configload"
@@ -189,3 +193,202 @@ func TestSamlSsoURLError(t *testing.T) {
 		})
 	}
 }
+
+func Test_SamlSsoURL_Signed(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(`
+	server "test" {
+	}
+	definitions {
+		saml "MySAML" {
+			idp_metadata_file = "testdata/idp-metadata.xml"
+			sp_entity_id = "the-sp"
+			sp_acs_url = "https://sp.example.com/saml/acs"
+			sp_certificate_file = "testdata/saml-sp.crt"
+			sp_private_key_file = "testdata/saml-sp.key"
+		}
+	}
+	`), "couper.hcl")
+	h.Must(err)
+
+	evalContext := cf.Context.Value(request.ContextType).(*eval.Context)
+	req, err := http.NewRequest(http.MethodGet, "https://www.example.com/foo", nil)
+	h.Must(err)
+	evalContext = evalContext.WithClientRequest(req)
+
+	ssoURL, err := evalContext.HCLContext().Functions[lib.FnSamlSsoURL].Call([]cty.Value{cty.StringVal("MySAML")})
+	h.Must(err)
+
+	u, err := url.Parse(ssoURL.AsString())
+	h.Must(err)
+
+	q := u.Query()
+	if sigAlg := q.Get("SigAlg"); sigAlg != "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256" {
+		t.Errorf("unexpected SigAlg: %q", sigAlg)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(q.Get("Signature"))
+	h.Must(err)
+
+	certPEM, err := os.ReadFile("testdata/saml-sp.crt")
+	h.Must(err)
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	h.Must(err)
+
+	signed := url.Values{"SAMLRequest": {q.Get("SAMLRequest")}}.Encode() + "&" + url.Values{"SigAlg": {q.Get("SigAlg")}}.Encode()
+	h.Must(cert.CheckSignature(x509.SHA256WithRSA, []byte(signed), signature))
+}
+
+func Test_SamlSpMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes string
+		wantKeys   int
+		wantSLO    bool
+	}{
+		{"without key pair", ``, 0, false},
+		{"with key pair and slo", `
+			sp_certificate_file = "testdata/saml-sp.crt"
+			sp_private_key_file = "testdata/saml-sp.key"
+			sp_slo_url = "/saml/slo"
+		`, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			h := test.New(subT)
+			cf, err := configload.LoadBytes([]byte(`
+			server "test" {
+			}
+			definitions {
+				saml "MySAML" {
+					idp_metadata_file = "testdata/idp-metadata.xml"
+					sp_entity_id = "the-sp"
+					sp_acs_url = "/saml/acs"
+					`+tt.attributes+`
+				}
+			}
+			`), "couper.hcl")
+			h.Must(err)
+
+			evalContext := cf.Context.Value(request.ContextType).(*eval.Context)
+			req, err := http.NewRequest(http.MethodGet, "https://www.example.com/saml/metadata", nil)
+			h.Must(err)
+			evalContext = evalContext.WithClientRequest(req)
+
+			metadata, err := evalContext.HCLContext().Functions[lib.FnSamlSpMetadata].Call([]cty.Value{cty.StringVal("MySAML")})
+			h.Must(err)
+
+			descriptor := &types.EntityDescriptor{}
+			h.Must(xml.Unmarshal([]byte(metadata.AsString()), descriptor))
+
+			if descriptor.EntityID != "the-sp" {
+				subT.Errorf("unexpected entityID: %q", descriptor.EntityID)
+			}
+
+			spDescriptor := descriptor.SPSSODescriptor
+			if spDescriptor == nil {
+				subT.Fatal("expected SPSSODescriptor")
+			}
+
+			if acs := spDescriptor.AssertionConsumerServices; len(acs) != 1 || acs[0].Location != "https://www.example.com/saml/acs" {
+				subT.Errorf("unexpected AssertionConsumerServices: %#v", acs)
+			}
+
+			if len(spDescriptor.KeyDescriptors) != tt.wantKeys {
+				subT.Errorf("expected %d KeyDescriptors, got %d", tt.wantKeys, len(spDescriptor.KeyDescriptors))
+			}
+
+			if spDescriptor.AuthnRequestsSigned != tt.wantSLO {
+				subT.Errorf("expected AuthnRequestsSigned %t", tt.wantSLO)
+			}
+
+			if tt.wantSLO {
+				slo := spDescriptor.SingleLogoutServices
+				if len(slo) != 2 || slo[0].Location != "https://www.example.com/saml/slo" {
+					subT.Errorf("unexpected SingleLogoutServices: %#v", slo)
+				}
+			} else if len(spDescriptor.SingleLogoutServices) != 0 {
+				subT.Errorf("expected no SingleLogoutServices")
+			}
+		})
+	}
+}
+
+func Test_SamlLogoutURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes string
+		wantErr    string
+	}{
+		{"without key pair", ``, `saml "MySAML": sp_private_key required to sign logout requests`},
+		{"with key pair", `
+			sp_certificate_file = "testdata/saml-sp.crt"
+			sp_private_key_file = "testdata/saml-sp.key"
+		`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			h := test.New(subT)
+			cf, err := configload.LoadBytes([]byte(`
+			server "test" {
+			}
+			definitions {
+				saml "MySAML" {
+					idp_metadata_file = "testdata/idp-metadata.xml"
+					sp_entity_id = "the-sp"
+					sp_acs_url = "/saml/acs"
+					`+tt.attributes+`
+				}
+			}
+			`), "couper.hcl")
+			h.Must(err)
+
+			evalContext := cf.Context.Value(request.ContextType).(*eval.Context)
+			req, err := http.NewRequest(http.MethodGet, "https://www.example.com/logout", nil)
+			h.Must(err)
+			evalContext = evalContext.WithClientRequest(req)
+
+			logoutURL, err := evalContext.HCLContext().Functions[lib.FnSamlLogoutURL].Call([]cty.Value{
+				cty.StringVal("MySAML"), cty.StringVal("abc12345"), cty.StringVal("_session-1"),
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					subT.Errorf("\nWant:\t%q\nGot:\t%v", tt.wantErr, err)
+				}
+				return
+			}
+			h.Must(err)
+
+			u, err := url.Parse(logoutURL.AsString())
+			h.Must(err)
+
+			if u.Host != "idp.example.org" || u.Path != "/saml/SLOService" {
+				subT.Errorf("unexpected logout URL: %q", u.String())
+			}
+
+			q := u.Query()
+			if q.Get("Signature") == "" || q.Get("SigAlg") == "" {
+				subT.Error("expected signed query")
+			}
+
+			b64Decoded, err := base64.StdEncoding.DecodeString(q.Get("SAMLRequest"))
+			h.Must(err)
+
+			deflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(b64Decoded)))
+			h.Must(err)
+
+			logoutRequest := &struct {
+				NameID       string `xml:"NameID"`
+				SessionIndex string `xml:"SessionIndex"`
+			}{}
+			h.Must(xml.Unmarshal(deflated, logoutRequest))
+
+			if logoutRequest.NameID != "abc12345" || logoutRequest.SessionIndex != "_session-1" {
+				subT.Errorf("unexpected logout request: %s", string(deflated))
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ const (
 	BackendRequests  = "backend_requests"
 	BackendResponse  = "backend_response"
 	BackendResponses = "backend_responses"
+	BackendName      = "backend_name"
 	Body             = "body"
+	CacheStatus      = "cache_status"
 	ClientRequest    = "request"
 	CTX              = "context"
 	Cookies          = "cookies"
@@ -48,6 +48,7 @@ type EndpointOptions struct {
 	LogHandlerKind    string
 	LogPattern        string
 	ReqBodyLimit      int64
+	ResBodyLimit      int64
 	SendServerTimings bool
 	ServerOpts        *server.Options
 
@@ -196,6 +197,9 @@ func (e *Endpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		log.WithError(errors.Server.With(err).Message("body copy failed")).Error()
 	}
 
+	// trailers are available after the body has been read, e.g. the gRPC status
+	copyTrailer(rw.Header(), clientres.Trailer)
+
 	_ = clientres.Body.Close()
 }
 
@@ -236,6 +240,9 @@ func (e *Endpoint) withContext(req *http.Request) context.Context {
 	reqCtx = context.WithValue(reqCtx, request.EndpointKind, e.opts.LogHandlerKind)
 	reqCtx = context.WithValue(reqCtx, request.APIName, e.opts.APIName)
 	reqCtx = context.WithValue(reqCtx, request.BufferOptions, e.opts.BufferOpts)
+	if e.opts.ResBodyLimit > 0 {
+		reqCtx = context.WithValue(reqCtx, request.ResponseBodyLimit, e.opts.ResBodyLimit)
+	}
 	*req = *req.WithContext(reqCtx)
 	return reqCtx
 }
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/coupergateway/couper/internal/grpc"
+)
+
+// grpcWebTrailerFlag marks the last gRPC-Web frame which contains the trailer fields.
+const grpcWebTrailerFlag byte = 0x80
+
+var _ http.RoundTripper = &GRPCWeb{}
+
+// GRPCWeb translates gRPC-Web requests of browser clients into native gRPC ones
+// and the related native gRPC responses back to gRPC-Web.
+type GRPCWeb struct {
+	proxy http.RoundTripper
+}
+
+func NewGRPCWeb(proxy http.RoundTripper) *GRPCWeb {
+	return &GRPCWeb{proxy: proxy}
+}
+
+// RoundTrip implements the <http.RoundTripper> interface. Other requests than gRPC-Web ones are passed as is.
+func (g *GRPCWeb) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCT := req.Header.Get("Content-Type")
+	if !grpc.IsGRPCWeb(reqCT) {
+		return g.proxy.RoundTrip(req)
+	}
+
+	text := grpc.IsGRPCWebText(reqCT)
+
+	req.Header.Set("Content-Type", grpc.WebToGRPC(reqCT))
+	req.Header.Set("Te", "trailers")
+
+	if text && req.Body != nil && req.Body != http.NoBody {
+		req.Body = newBase64Body(req.Body)
+		req.ContentLength = -1
+
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return newBase64Body(body), nil
+			}
+		}
+	}
+
+	beresp, err := g.proxy.RoundTrip(req)
+	if err != nil || beresp == nil || !grpc.IsGRPC(beresp.Header.Get("Content-Type")) {
+		return beresp, err
+	}
+
+	// The trailers are written as last gRPC-Web frame instead of http trailers.
+	// Since the transport sets them on the backend response after reading the
+	// body, a copy is passed to the client.
+	clientres := *beresp
+	clientres.ContentLength = -1
+	clientres.Trailer = nil
+	clientres.Body = &grpcWebBody{
+		chunk: make([]byte, 32*1024),
+		res:   beresp,
+		text:  text,
+	}
+
+	clientres.Header.Set("Content-Type", grpc.GRPCToWeb(beresp.Header.Get("Content-Type"), text))
+	clientres.Header.Del("Content-Length")
+
+	// browsers require the status fields to be exposed for cross-origin requests
+	if req.Header.Get("Origin") != "" {
+		clientres.Header.Add("Access-Control-Expose-Headers", strings.ToLower(grpc.StatusHeader)+", "+strings.ToLower(grpc.MessageHeader))
+	}
+
+	return &clientres, nil
+}
+
+// grpcWebBody appends the trailer frame to the backend response body
+// and encodes all frames with base64 for "grpc-web-text" clients.
+type grpcWebBody struct {
+	buf   []byte
+	chunk []byte
+	eof   bool
+	res   *http.Response
+	text  bool
+}
+
+func (b *grpcWebBody) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		if b.eof {
+			return 0, io.EOF
+		}
+
+		n, err := b.res.Body.Read(b.chunk)
+		if n > 0 {
+			b.buf = b.encode(b.chunk[:n])
+		}
+
+		if err == io.EOF {
+			b.eof = true
+			if len(b.res.Trailer) > 0 {
+				b.buf = append(b.buf, b.encode(grpcWebTrailerFrame(b.res.Trailer))...)
+			}
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *grpcWebBody) Close() error {
+	return b.res.Body.Close()
+}
+
+// encode returns the given bytes as base64 for text clients. Padded chunks
+// are valid since clients have to decode them one by one.
+func (b *grpcWebBody) encode(p []byte) []byte {
+	if !b.text {
+		return p
+	}
+	dst := make([]byte, base64.StdEncoding.EncodedLen(len(p)))
+	base64.StdEncoding.Encode(dst, p)
+	return dst
+}
+
+// grpcWebTrailerFrame creates the length-prefixed frame with the
+// given trailer fields in HTTP/1 header format with lower-case names.
+func grpcWebTrailerFrame(trailer http.Header) []byte {
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var fields []byte
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			fields = append(fields, strings.ToLower(k)+": "+v+"\r\n"...)
+		}
+	}
+
+	frame := make([]byte, 5, 5+len(fields))
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(fields)))
+	return append(frame, fields...)
+}
+
+// base64Body decodes a "grpc-web-text" request body. The
+// body may consist of multiple padded base64 chunks.
+type base64Body struct {
+	io.ReadCloser
+	chunk []byte
+	eof   bool
+	in    []byte
+	out   []byte
+}
+
+func newBase64Body(body io.ReadCloser) *base64Body {
+	return &base64Body{ReadCloser: body, chunk: make([]byte, 32*1024)}
+}
+
+func (b *base64Body) Read(p []byte) (int, error) {
+	for len(b.out) == 0 {
+		if b.eof {
+			return 0, io.EOF
+		}
+
+		n, err := b.ReadCloser.Read(b.chunk)
+		b.in = append(b.in, b.chunk[:n]...)
+
+		// decode quantum-wise to support padding within the body
+		var quantum [3]byte
+		complete := len(b.in) - len(b.in)%4
+		for i := 0; i < complete; i += 4 {
+			m, derr := base64.StdEncoding.Decode(quantum[:], b.in[i:i+4])
+			if derr != nil {
+				return 0, derr
+			}
+			b.out = append(b.out, quantum[:m]...)
+		}
+		b.in = b.in[complete:]
+
+		if err == io.EOF {
+			if len(b.in) > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, b.out)
+	b.out = b.out[n:]
+	return n, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpguts"
 
 	hclbody "github.com/coupergateway/couper/config/body"
 	"github.com/coupergateway/couper/config/request"
@@ -20,10 +21,13 @@ import (
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/handler/ascii"
 	"github.com/coupergateway/couper/handler/transport"
+	"github.com/coupergateway/couper/internal/grpc"
 	"github.com/coupergateway/couper/internal/seetie"
 	"github.com/coupergateway/couper/server/writer"
 )
 
+const defaultSSEIdleTimeout = time.Minute
+
 // headerBlacklist lists all header keys which will be removed after
 // context variable evaluation to ensure to not pass them upstream.
 var headerBlacklist = []string{"Authorization", "Cookie"}
@@ -53,6 +57,10 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 	for _, key := range headerBlacklist {
 		req.Header.Del(key)
 	}
+	// The access token of a session access control is forwarded unless overridden by the proxy-body.
+	if accessToken, ok := req.Context().Value(request.SessionAccessToken).(string); ok && accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
 
 	hclCtx := eval.ContextFromRequest(req).HCLContextSync()
 
@@ -68,7 +76,13 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
-	// 4. apply some hcl context
+	// 4. Apply sse-body
+	outCtx, retry, err := p.applySSERequest(hclCtx, outCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. apply some hcl context
 	expStatusVal, err := eval.ValueFromBodyAttribute(hclCtx, p.context, "expected_status")
 	if err != nil {
 		return nil, err
@@ -98,6 +112,10 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	transport.RemoveConnectionHeaders(req.Header)
 
+	// Required by gRPC to detect incompatible proxies, see
+	// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+	acceptsTrailers := httpguts.HeaderValuesContainsToken(req.Header["Te"], "trailers")
+
 	// Remove hop-by-hop headers to the backend. Especially
 	// important is "Connection" because we want a persistent
 	// connection, regardless of what the client sent to us.
@@ -105,7 +123,9 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.Header.Del(h)
 	}
 
-	// TODO: trailer header here
+	if acceptsTrailers {
+		req.Header.Set("Te", "trailers")
+	}
 
 	// After stripping all the hop-by-hop connection headers above, add back any
 	// necessary for protocol upgrades, such as for websockets.
@@ -127,6 +147,13 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 	transport.RemoveConnectionHeaders(beresp.Header)
 	transport.RemoveHopHeaders(beresp.Header)
 
+	if retry > 0 && beresp.Body != nil && transport.IsEventStream(beresp) {
+		beresp.Body = &prefixBody{
+			prefix:     strings.NewReader(fmt.Sprintf("retry: %d\n\n", retry.Milliseconds())),
+			ReadCloser: beresp.Body,
+		}
+	}
+
 	evalCtx := eval.ContextFromRequest(req)
 	err = eval.ApplyResponseContext(evalCtx.HCLContextSync(), p.context, beresp)
 
@@ -189,6 +216,34 @@ func (p *Proxy) applyWebsocketsRequest(hclCtx *hcl.EvalContext, req *http.Reques
 	return outCtx, nil
 }
 
+// applySSERequest enables the idle timeout for event streams and returns the configured reconnection time.
+func (p *Proxy) applySSERequest(hclCtx *hcl.EvalContext, outCtx context.Context) (context.Context, time.Duration, error) {
+	sse := hclbody.BlocksOfType(p.context, "sse")
+	if len(sse) != 1 {
+		return outCtx, 0, nil
+	}
+
+	idleTimeout := defaultSSEIdleTimeout
+	var retry time.Duration
+	for attrName, target := range map[string]*time.Duration{
+		"idle_timeout": &idleTimeout,
+		"retry":        &retry,
+	} {
+		val, err := eval.ValueFromBodyAttribute(hclCtx, sse[0].Body, attrName)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if str := seetie.ValueToString(val); str != "" {
+			if *target, err = time.ParseDuration(str); err != nil {
+				return nil, 0, errors.Configuration.Messagef("sse: %s: %s", attrName, err)
+			}
+		}
+	}
+
+	return context.WithValue(outCtx, request.SSEIdleTimeout, idleTimeout), retry, nil
+}
+
 func (p *Proxy) registerWebsocketsResponse(req *http.Request) error {
 	if !eval.IsUpgradeRequest(req) {
 		return nil
@@ -281,6 +336,16 @@ func copyHeader(dst, src http.Header) {
 	}
 }
 
+// copyTrailer sets the given trailer fields with the <http.TrailerPrefix>
+// since they are not announced with the "Trailer" header field.
+func copyTrailer(dst, src http.Header) {
+	for k, vv := range src {
+		for _, v := range vv {
+			dst.Add(http.TrailerPrefix+k, v)
+		}
+	}
+}
+
 func flushInterval(res *http.Response) time.Duration {
 	resCT := res.Header.Get("Content-Type")
 
@@ -290,6 +355,11 @@ func flushInterval(res *http.Response) time.Duration {
 		return -1 // negative means immediately
 	}
 
+	// gRPC streams consist of length-prefixed messages which must not be delayed.
+	if grpc.IsGRPC(resCT) || grpc.IsGRPCWeb(resCT) {
+		return -1
+	}
+
 	// We might have the case of streaming for which Content-Length might be unset.
 	if res.ContentLength == -1 {
 		return -1
@@ -412,6 +482,26 @@ func (m *maxLatencyWriter) stop() {
 	}
 }
 
+// prefixBody writes the given prefix in front of the body.
+type prefixBody struct {
+	io.ReadCloser
+	prefix io.Reader
+}
+
+func (p *prefixBody) Read(b []byte) (int, error) {
+	if p.prefix != nil {
+		n, err := p.prefix.Read(b)
+		if err != io.EOF {
+			return n, err
+		}
+		p.prefix = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return p.ReadCloser.Read(b)
+}
+
 // switchProtocolCopier exists so goroutines proxying data back and
 // forth have nice names in stacks.
 type switchProtocolCopier struct {
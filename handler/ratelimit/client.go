@@ -0,0 +1,322 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/handler/middleware"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// ClientLimiter limits incoming client requests per evaluated key.
+type ClientLimiter struct {
+	clients   map[string]*clientLimit
+	keyExpr   hcl.Expression
+	mu        sync.Mutex
+	period    time.Duration
+	perPeriod uint
+	window    int
+}
+
+type ctxKey uint8
+
+const countedLimiters ctxKey = iota
+
+type clientLimit struct {
+	count       uint
+	lastSeen    time.Time
+	periodStart time.Time
+	ringBuffer  *ringBuffer
+}
+
+func NewClientLimiter(ctx context.Context, limit *config.ClientRateLimit) (*ClientLimiter, error) {
+	period, err := parsePeriod(limit.Period, limit.PerPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := parsePeriodWindow(limit.PeriodWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := &ClientLimiter{
+		clients:   make(map[string]*clientLimit),
+		keyExpr:   limit.Key,
+		period:    period,
+		perPeriod: limit.PerPeriod,
+		window:    window,
+	}
+
+	go cl.gc(ctx.Done())
+
+	return cl, nil
+}
+
+// NewHandler returns a middleware which counts the client request for its key and
+// serves the given error handler with a RateLimitExceeded error if the limit is reached.
+// Requests already counted by the handler of NewPreHandler are passed through.
+func (cl *ClientLimiter) NewHandler(errorHandler http.Handler) middleware.Next {
+	return func(handler http.Handler) *middleware.NextHandler {
+		return middleware.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			counted, _ := req.Context().Value(countedLimiters).(map[*ClientLimiter]bool)
+			if counted[cl] {
+				handler.ServeHTTP(rw, req)
+				return
+			}
+
+			key, err := cl.key(req)
+			if err != nil {
+				*req = *req.WithContext(context.WithValue(req.Context(), request.Error, err))
+				errorHandler.ServeHTTP(rw, req)
+				return
+			}
+
+			if !cl.count(rw, req, key, errorHandler) {
+				return
+			}
+			if counted != nil {
+				counted[cl] = true
+			}
+
+			handler.ServeHTTP(rw, req)
+		}), handler)
+	}
+}
+
+// NewPreHandler returns a middleware to be placed in front of the access controls of the handler
+// created by NewHandler. Requests are counted there if their key can already be evaluated.
+// Otherwise, e.g. for a key referring to request.context, requests which do not reach the
+// handler of NewHandler (like failed authentications) are counted for the client IP address,
+// and further requests are rejected as soon as the limit for the client IP address is reached.
+func (cl *ClientLimiter) NewPreHandler(errorHandler http.Handler) middleware.Next {
+	return func(handler http.Handler) *middleware.NextHandler {
+		return middleware.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			counted, exist := req.Context().Value(countedLimiters).(map[*ClientLimiter]bool)
+			if !exist {
+				counted = make(map[*ClientLimiter]bool)
+				*req = *req.WithContext(context.WithValue(req.Context(), countedLimiters, counted))
+			}
+
+			if key, err := cl.evalKey(req); err == nil && (key != "" || cl.keyExpr == nil) {
+				if key == "" {
+					key = clientIP(req)
+				}
+				if !cl.count(rw, req, key, errorHandler) {
+					return
+				}
+				counted[cl] = true
+				handler.ServeHTTP(rw, req)
+				return
+			}
+
+			ip := clientIP(req)
+			if reset, exceeded := cl.exceeded(ip, time.Now()); exceeded {
+				cl.setHeaders(rw.Header(), 0, reset)
+				cl.serveExceeded(rw, req, reset, errorHandler)
+				return
+			}
+
+			handler.ServeHTTP(rw, req)
+
+			if !counted[cl] {
+				cl.take(ip, time.Now())
+			}
+		}), handler)
+	}
+}
+
+// count counts the request for the given key. If the limit is reached, the
+// given error handler is served and false is returned.
+func (cl *ClientLimiter) count(rw http.ResponseWriter, req *http.Request, key string, errorHandler http.Handler) bool {
+	remaining, reset, ok := cl.take(key, time.Now())
+	cl.setHeaders(rw.Header(), remaining, reset)
+
+	if !ok {
+		cl.serveExceeded(rw, req, reset, errorHandler)
+	}
+	return ok
+}
+
+func (cl *ClientLimiter) serveExceeded(rw http.ResponseWriter, req *http.Request, reset time.Duration, errorHandler http.Handler) {
+	rw.Header().Set(HeaderRetryAfter, seconds(reset))
+	err := errors.RateLimitExceeded.Messagef("%d requests per %s", cl.perPeriod, cl.period)
+	*req = *req.WithContext(context.WithValue(req.Context(), request.Error, err))
+	errorHandler.ServeHTTP(rw, req)
+}
+
+// key evaluates the configured key expression. The client IP address is
+// used as fallback for an empty or missing key.
+func (cl *ClientLimiter) key(req *http.Request) (string, error) {
+	key, err := cl.evalKey(req)
+	if err != nil {
+		return "", errors.Evaluation.With(err)
+	}
+
+	if key != "" {
+		return key, nil
+	}
+	return clientIP(req), nil
+}
+
+// evalKey evaluates the configured key expression, if any.
+func (cl *ClientLimiter) evalKey(req *http.Request) (string, error) {
+	if cl.keyExpr == nil {
+		return "", nil
+	}
+
+	v, err := eval.Value(eval.ContextFromRequest(req).HCLContext(), cl.keyExpr)
+	if err != nil {
+		return "", err
+	}
+	return seetie.ValueToString(v), nil
+}
+
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// exceeded reports, without counting a request, whether the limit for the given key
+// is reached, and the time until the next request for this key would be permitted.
+func (cl *ClientLimiter) exceeded(key string, now time.Time) (time.Duration, bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	c, exist := cl.clients[key]
+	if !exist {
+		return 0, false
+	}
+
+	switch cl.window {
+	case windowFixed:
+		if c.periodStart.IsZero() || now.Sub(c.periodStart) >= cl.period || c.count < cl.perPeriod {
+			return 0, false
+		}
+		return c.periodStart.Add(cl.period).Sub(now), true
+	case windowSliding:
+		oldest := c.ringBuffer.get()
+		if !oldest.IsZero() && oldest.Add(cl.period).After(now) {
+			return oldest.Add(cl.period).Sub(now), true
+		}
+	}
+
+	return 0, false
+}
+
+// take counts a request for the given key if there is capacity left. The returned
+// duration describes the time until the next request for this key would be permitted
+// or the current period ends.
+func (cl *ClientLimiter) take(key string, now time.Time) (remaining uint, reset time.Duration, ok bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	c, exist := cl.clients[key]
+	if !exist {
+		c = &clientLimit{}
+		if cl.window == windowSliding {
+			c.ringBuffer = newRingBuffer(cl.perPeriod)
+		}
+		cl.clients[key] = c
+	}
+	c.lastSeen = now
+
+	switch cl.window {
+	case windowFixed:
+		if c.periodStart.IsZero() || now.Sub(c.periodStart) >= cl.period {
+			c.periodStart = now
+			c.count = 0
+		}
+
+		reset = c.periodStart.Add(cl.period).Sub(now)
+		if c.count >= cl.perPeriod {
+			return 0, reset, false
+		}
+
+		c.count++
+		return cl.perPeriod - c.count, reset, true
+	case windowSliding:
+		oldest := c.ringBuffer.get()
+		if !oldest.IsZero() && oldest.Add(cl.period).After(now) {
+			return 0, oldest.Add(cl.period).Sub(now), false
+		}
+
+		c.ringBuffer.put(now)
+
+		oldest = c.ringBuffer.get()
+		if oldest.IsZero() || !oldest.Add(cl.period).After(now) {
+			reset = cl.period
+		} else {
+			reset = oldest.Add(cl.period).Sub(now)
+		}
+		return cl.perPeriod - c.ringBuffer.countAfter(now.Add(-cl.period)), reset, true
+	}
+
+	return 0, 0, true
+}
+
+// setHeaders sets the RateLimit-* header fields. Nested limits
+// (e.g. server and endpoint) report the most restrictive one.
+func (cl *ClientLimiter) setHeaders(header http.Header, remaining uint, reset time.Duration) {
+	if r := header.Get(HeaderRemaining); r != "" {
+		if current, err := strconv.ParseUint(r, 10, 64); err == nil && current <= uint64(remaining) {
+			return
+		}
+	}
+
+	header.Set(HeaderLimit, strconv.FormatUint(uint64(cl.perPeriod), 10))
+	header.Set(HeaderRemaining, strconv.FormatUint(uint64(remaining), 10))
+	header.Set(HeaderReset, seconds(reset))
+}
+
+// gc removes the counters of keys without a request within the last period.
+func (cl *ClientLimiter) gc(quitCh <-chan struct{}) {
+	interval := cl.period
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quitCh:
+			return
+		case now := <-ticker.C:
+			cl.mu.Lock()
+			for key, c := range cl.clients {
+				if now.Sub(c.lastSeen) > cl.period {
+					delete(cl.clients, key)
+				}
+			}
+			cl.mu.Unlock()
+		}
+	}
+}
+
+// seconds returns the given duration as string of whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+)
+
+func TestClientLimiter_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		configured *config.ClientRateLimit
+		expMessage string
+	}{
+		{&config.ClientRateLimit{PerPeriod: 1}, "'period' must not be 0 (zero)"},
+		{&config.ClientRateLimit{Period: "1m"}, "'per_period' must not be 0 (zero)"},
+		{&config.ClientRateLimit{Period: "1m", PerPeriod: 1, PeriodWindow: "foo"}, `unsupported 'period_window' ("foo") given`},
+	} {
+		_, err := NewClientLimiter(context.TODO(), tc.configured)
+		if err == nil || err.Error() != tc.expMessage {
+			t.Errorf("expected error %q, got: %v", tc.expMessage, err)
+		}
+	}
+}
+
+func TestClientLimiter_Take(t *testing.T) {
+	for _, window := range []string{"fixed", "sliding"} {
+		t.Run(window, func(st *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cl, err := NewClientLimiter(ctx, &config.ClientRateLimit{Period: "1s", PerPeriod: 2, PeriodWindow: window})
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			now := time.Now()
+
+			for i, exp := range []struct {
+				key       string
+				offset    time.Duration
+				remaining uint
+				ok        bool
+			}{
+				{"a", 0, 1, true},
+				{"a", 100 * time.Millisecond, 0, true},
+				{"b", 100 * time.Millisecond, 1, true},
+				{"a", 200 * time.Millisecond, 0, false},
+				{"a", 1100 * time.Millisecond, 1, true},
+			} {
+				remaining, reset, ok := cl.take(exp.key, now.Add(exp.offset))
+				if ok != exp.ok || remaining != exp.remaining {
+					st.Errorf("%d: expected ok=%v remaining=%d, got ok=%v remaining=%d", i, exp.ok, exp.remaining, ok, remaining)
+				}
+				if reset <= 0 || reset > time.Second {
+					st.Errorf("%d: unexpected reset: %s", i, reset)
+				}
+			}
+		})
+	}
+}
+
+func TestClientLimiter_Exceeded(t *testing.T) {
+	for _, window := range []string{"fixed", "sliding"} {
+		t.Run(window, func(st *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cl, err := NewClientLimiter(ctx, &config.ClientRateLimit{Period: "1s", PerPeriod: 1, PeriodWindow: window})
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			now := time.Now()
+			if _, exceeded := cl.exceeded("a", now); exceeded {
+				st.Error("expected limit not exceeded for unknown key")
+			}
+
+			cl.take("a", now)
+			if reset, exceeded := cl.exceeded("a", now.Add(100*time.Millisecond)); !exceeded || reset != 900*time.Millisecond {
+				st.Errorf("expected limit exceeded with reset 900ms, got %v, %s", exceeded, reset)
+			}
+			// exceeded does not count
+			if _, exceeded := cl.exceeded("a", now.Add(1100*time.Millisecond)); exceeded {
+				st.Error("expected limit not exceeded in next period")
+			}
+		})
+	}
+}
@@ -42,33 +42,20 @@ func ConfigureRateLimits(ctx context.Context, limits config.RateLimits, logger *
 	uniqueDurations := make(map[time.Duration]struct{})
 
 	for _, limit := range limits {
-		d, err := config.ParseDuration("period", limit.Period, 0)
+		d, err := parsePeriod(limit.Period, limit.PerPeriod)
 		if err != nil {
 			return nil, err
 		}
 
-		if d == 0 {
-			return nil, fmt.Errorf("'period' must not be 0 (zero)")
-		}
-		if limit.PerPeriod == 0 {
-			return nil, fmt.Errorf("'per_period' must not be 0 (zero)")
-		}
-
 		if _, ok := uniqueDurations[time.Duration(d.Nanoseconds())]; ok {
 			return nil, fmt.Errorf("duplicate period (%q) found", limit.Period)
 		}
 
 		uniqueDurations[time.Duration(d.Nanoseconds())] = struct{}{}
 
-		switch limit.PeriodWindow {
-		case "":
-			fallthrough
-		case "sliding":
-			window = windowSliding
-		case "fixed":
-			window = windowFixed
-		default:
-			return nil, fmt.Errorf("unsupported 'period_window' (%q) given", limit.PeriodWindow)
+		window, err = parsePeriodWindow(limit.PeriodWindow)
+		if err != nil {
+			return nil, err
 		}
 
 		switch limit.Mode {
@@ -107,6 +94,35 @@ func ConfigureRateLimits(ctx context.Context, limits config.RateLimits, logger *
 	return rateLimits, nil
 }
 
+func parsePeriod(period string, perPeriod uint) (time.Duration, error) {
+	d, err := config.ParseDuration("period", period, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if d == 0 {
+		return 0, fmt.Errorf("'period' must not be 0 (zero)")
+	}
+	if perPeriod == 0 {
+		return 0, fmt.Errorf("'per_period' must not be 0 (zero)")
+	}
+
+	return d, nil
+}
+
+func parsePeriodWindow(periodWindow string) (int, error) {
+	switch periodWindow {
+	case "":
+		fallthrough
+	case "sliding":
+		return windowSliding, nil
+	case "fixed":
+		return windowFixed, nil
+	default:
+		return 0, fmt.Errorf("unsupported 'period_window' (%q) given", periodWindow)
+	}
+}
+
 // countRequest MUST only be called after checkCapacity()
 func (rl *RateLimit) countRequest() {
 	switch rl.window {
@@ -56,3 +56,22 @@ func (r *ringBuffer) get() time.Time {
 
 	return r.buf[r.r]
 }
+
+// countAfter returns the number of elements in the
+// ring buffer after t. r must not be empty.
+func (r *ringBuffer) countAfter(t time.Time) uint {
+	if r == nil {
+		panic("r must not be empty")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n uint
+	for _, v := range r.buf {
+		if v.After(t) {
+			n++
+		}
+	}
+	return n
+}
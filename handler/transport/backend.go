@@ -46,13 +46,17 @@ var (
 )
 
 type Backend struct {
+	circuitBreaker      *CircuitBreaker
 	context             *hclsyntax.Body
 	healthInfo          *HealthInfo
 	healthyMu           sync.RWMutex
+	loadBalancer        *LoadBalancer
 	logEntry            *logrus.Entry
 	name                string
 	openAPIValidator    *validation.OpenAPI
 	requestAuthorizer   []RequestAuthorizer
+	responseCache       *ResponseCache
+	retry               *Retry
 	transport           http.RoundTripper
 	transportConf       *Config
 	transportConfResult Config
@@ -63,24 +67,36 @@ type Backend struct {
 // NewBackend creates a new <*Backend> object by the given <*Config>.
 func NewBackend(ctx *hclsyntax.Body, tc *Config, opts *BackendOptions, log *logrus.Entry) http.RoundTripper {
 	var (
+		circuitBreaker    *CircuitBreaker
 		healthCheck       *config.HealthCheck
+		loadBalancer      *LoadBalancer
 		openAPI           *validation.OpenAPI
 		requestAuthorizer []RequestAuthorizer
+		responseCache     *ResponseCache
+		retry             *Retry
 	)
 
 	if opts != nil {
+		circuitBreaker = opts.CircuitBreaker
 		healthCheck = opts.HealthCheck
+		loadBalancer = opts.LoadBalancer
 		openAPI = validation.NewOpenAPI(opts.OpenAPI)
 		requestAuthorizer = opts.RequestAuthz
+		responseCache = opts.ResponseCache
+		retry = opts.Retry
 	}
 
 	backend := &Backend{
+		circuitBreaker:    circuitBreaker,
 		context:           ctx,
 		healthInfo:        &HealthInfo{Healthy: true, State: StateOk.String()},
+		loadBalancer:      loadBalancer,
 		logEntry:          log.WithField("backend", tc.BackendName),
 		name:              tc.BackendName,
 		openAPIValidator:  openAPI,
 		requestAuthorizer: requestAuthorizer,
+		responseCache:     responseCache,
+		retry:             retry,
 		transportConf:     tc,
 	}
 
@@ -91,15 +107,29 @@ func NewBackend(ctx *hclsyntax.Body, tc *Config, opts *BackendOptions, log *logr
 		NewProbe(backend.logEntry, tc, healthCheck, backend)
 	}
 
+	if loadBalancer != nil {
+		loadBalancer.listener = backend
+		if distinct {
+			loadBalancer.startProbes(backend.logEntry, tc)
+		}
+	}
+
 	return backend.upstreamLog
 }
 
 // initOnce ensures synced transport configuration. First request will setup the rate limits, origin, hostname and tls.
 func (b *Backend) initOnce(conf *Config) {
+	var t http.RoundTripper
+	if b.loadBalancer != nil {
+		t = b.loadBalancer
+	} else {
+		t = NewTransport(conf, b.logEntry)
+	}
+
 	if len(b.transportConf.RateLimits) > 0 {
-		b.transport = ratelimit.NewLimiter(NewTransport(conf, b.logEntry), b.transportConf.RateLimits)
+		b.transport = ratelimit.NewLimiter(t, b.transportConf.RateLimits)
 	} else {
-		b.transport = NewTransport(conf, b.logEntry)
+		b.transport = t
 	}
 
 	b.healthyMu.Lock()
@@ -114,14 +144,23 @@ func (b *Backend) initOnce(conf *Config) {
 
 // RoundTrip implements the <http.RoundTripper> interface.
 func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
+	if b.retry != nil {
+		return b.retry.Do(req, b.roundTrip)
+	}
+	return b.roundTrip(req)
+}
+
+func (b *Backend) roundTrip(req *http.Request) (*http.Response, error) {
 	ctxBody, _ := req.Context().Value(request.BackendParams).(*hclsyntax.Body)
 	if ctxBody == nil {
 		ctxBody = b.context
 	} else {
-		ctxBody = hclbody.MergeBodies(ctxBody, b.context, false)
+		// copy to keep the shared refinement body untouched
+		ctxBody = hclbody.MergeBodies(hclbody.Copy(ctxBody), b.context, false)
 	}
 
 	outreq := req.WithContext(context.WithValue(req.Context(), request.BackendName, b.name))
+	removeCredentials(outreq)
 
 	// originalReq for token-request retry purposes
 	originalReq, err := b.withTokenRequest(outreq)
@@ -150,8 +189,15 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	var upstream *Upstream
+	if b.loadBalancer != nil {
+		if upstream, err = b.selectUpstream(hclCtx, ctxBody); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: split timing eval
-	tc, err := b.evalTransport(hclCtx, ctxBody, outreq)
+	tc, err := b.evalTransport(hclCtx, ctxBody, outreq, upstream)
 	if err != nil {
 		return nil, err
 	}
@@ -162,14 +208,20 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	})
 
 	// use result and apply context timings
-	b.healthyMu.RLock()
-	tconf := b.transportConfResult
-	b.healthyMu.RUnlock()
+	var tconf Config
+	if upstream != nil {
+		tconf = upstream.TransportConf(tc, b.logEntry)
+	} else {
+		b.healthyMu.RLock()
+		tconf = b.transportConfResult
+		b.healthyMu.RUnlock()
+	}
 	tconf.ConnectTimeout = tc.ConnectTimeout
 	tconf.TTFBTimeout = tc.TTFBTimeout
 	tconf.Timeout = tc.Timeout
 
-	deadlineErr := b.withTimeout(outreq, &tconf)
+	stream := newEventStream(outreq)
+	deadlineErr := b.withTimeout(outreq, &tconf, stream.Started())
 
 	outreq.URL.Host = tconf.Origin
 	outreq.URL.Scheme = tconf.Scheme
@@ -206,11 +258,39 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		outreq.Header.Del("Upgrade")
 	}
 
+	roundTrip := func(r *http.Request) (*http.Response, error) {
+		var (
+			res   *http.Response
+			rtErr error
+		)
+		if upstream != nil {
+			release := upstream.acquire()
+			defer func() {
+				release(res, rtErr == nil && eval.IsUpgradeResponse(r, res))
+			}()
+		}
+
+		if b.openAPIValidator != nil {
+			res, rtErr = b.openAPIValidate(r, &tconf, deadlineErr)
+		} else {
+			res, rtErr = b.innerRoundTrip(r, &tconf, deadlineErr)
+		}
+
+		if stream != nil && rtErr == nil {
+			stream.start(res)
+		}
+
+		if b.circuitBreaker != nil {
+			b.circuitBreaker.Record(res, rtErr)
+		}
+		return res, rtErr
+	}
+
 	var beresp *http.Response
-	if b.openAPIValidator != nil {
-		beresp, err = b.openAPIValidate(outreq, &tconf, deadlineErr)
+	if rc := b.responseCacheFor(outreq); rc != nil {
+		beresp, err = rc.Serve(outreq, roundTrip)
 	} else {
-		beresp, err = b.innerRoundTrip(outreq, &tconf, deadlineErr)
+		beresp, err = roundTrip(outreq)
 	}
 
 	if err != nil {
@@ -228,7 +308,7 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	if retry, rerr := b.withRetryTokenRequest(outreq, beresp); rerr != nil {
 		return beresp, errors.BetaBackendTokenRequest.Label(b.name).With(rerr)
 	} else if retry {
-		return b.RoundTrip(originalReq)
+		return b.roundTrip(originalReq)
 	}
 
 	if !eval.IsUpgradeResponse(outreq, beresp) {
@@ -269,6 +349,14 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	return beresp, err
 }
 
+// responseCacheFor returns the response cache of a related proxy block or the backend one.
+func (b *Backend) responseCacheFor(req *http.Request) *ResponseCache {
+	if rc, ok := req.Context().Value(request.ResponseCache).(*ResponseCache); ok {
+		return rc
+	}
+	return b.responseCache
+}
+
 func (b *Backend) openAPIValidate(req *http.Request, tc *Config, deadlineErr <-chan error) (*http.Response, error) {
 	requestValidationInput, err := b.openAPIValidator.ValidateRequest(req)
 	if err != nil {
@@ -338,6 +426,10 @@ func (b *Backend) innerRoundTrip(req *http.Request, tc *Config, deadlineErr <-ch
 		}
 	}
 
+	if err = grpcError(b.name, beresp); err != nil {
+		return nil, err
+	}
+
 	return beresp, nil
 }
 
@@ -349,6 +441,7 @@ func (b *Backend) withTokenRequest(req *http.Request) (*http.Request, error) {
 	// Reset for upstream transport; prevent mixing values.
 	// requestAuthorizer will have their own backend configuration.
 	ctx := context.WithValue(req.Context(), request.BackendParams, nil)
+	ctx = context.WithValue(ctx, request.ResponseCache, nil)
 
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithCancel(ctx)
@@ -430,7 +523,9 @@ func (b *Backend) getAttribute(evalContext *hcl.EvalContext, name string, hclCon
 	return seetie.ValueToString(attrVal)
 }
 
-func (b *Backend) withTimeout(req *http.Request, conf *Config) <-chan error {
+// withTimeout applies the ttfb and total timeouts to the request. The total timeout
+// stops with the streaming channel since event streams have their own idle timeout.
+func (b *Backend) withTimeout(req *http.Request, conf *Config, streaming <-chan struct{}) <-chan error {
 	timeout := conf.Timeout
 	ws := false
 	if to, ok := req.Context().Value(request.WebsocketsTimeout).(time.Duration); ok {
@@ -485,7 +580,6 @@ func (b *Backend) withTimeout(req *http.Request, conf *Config) <-chan error {
 	*req = *req.WithContext(httptrace.WithClientTrace(ctx, ctxTrace))
 
 	go func(c context.Context, cancelFn func(), ec chan error) {
-		defer cancelFn()
 		deadline := make(<-chan time.Time)
 		if timeout > 0 {
 			deadlineTimer := time.NewTimer(timeout)
@@ -494,6 +588,7 @@ func (b *Backend) withTimeout(req *http.Request, conf *Config) <-chan error {
 		}
 		select {
 		case <-deadline:
+			cancelFn()
 			if ws {
 				ec <- errors.BackendTimeout.Label(b.name).Message("websockets: deadline exceeded")
 				return
@@ -501,15 +596,20 @@ func (b *Backend) withTimeout(req *http.Request, conf *Config) <-chan error {
 			ec <- errors.BackendTimeout.Label(b.name).Message("deadline exceeded")
 			return
 		case <-ttfbTimeout:
+			cancelFn()
 			ec <- errors.BackendTimeout.Label(b.name).Message("timeout awaiting response headers")
+		case <-streaming:
+			// the parent context gets canceled with the event stream
+			return
 		case <-c.Done():
+			cancelFn()
 			return
 		}
 	}(ctx, cancel, errCh)
 	return errCh
 }
 
-func (b *Backend) evalTransport(httpCtx *hcl.EvalContext, params *hclsyntax.Body, req *http.Request) (*Config, error) {
+func (b *Backend) evalTransport(httpCtx *hcl.EvalContext, params *hclsyntax.Body, req *http.Request, upstream *Upstream) (*Config, error) {
 	log := b.upstreamLog.LogEntry()
 
 	var origin, hostname, proxyURL string
@@ -535,6 +635,10 @@ func (b *Backend) evalTransport(httpCtx *hcl.EvalContext, params *hclsyntax.Body
 		}
 	}
 
+	if upstream != nil {
+		origin = upstream.Origin()
+	}
+
 	originURL, parseErr := url.Parse(origin)
 	if parseErr != nil {
 		return nil, errors.Configuration.Label(b.name).With(parseErr)
@@ -559,25 +663,74 @@ func (b *Backend) evalTransport(httpCtx *hcl.EvalContext, params *hclsyntax.Body
 		WithTimings(connectTimeout, ttfbTimeout, timeout, log), nil
 }
 
-func (b *Backend) isUnhealthy(ctx *hcl.EvalContext, params *hclsyntax.Body) error {
+// removeCredentials removes client credentials like API keys, which must not be passed upstream,
+// before the backend applies its own request modifications.
+func removeCredentials(req *http.Request) {
+	ctx := req.Context()
+	if headers, ok := ctx.Value(request.CredentialHeaders).([]string); ok {
+		for _, key := range headers {
+			req.Header.Del(key)
+		}
+	}
+	if cookies, ok := ctx.Value(request.CredentialCookies).([]string); ok {
+		utils.RemoveCookies(req.Header, cookies)
+	}
+	if params, ok := ctx.Value(request.CredentialQueryParams).([]string); ok {
+		req.URL.RawQuery = utils.RemoveQueryParams(req.URL.RawQuery, params)
+	}
+}
+
+// selectUpstream evaluates the hash_key attribute and returns the next origin of the load balancer.
+func (b *Backend) selectUpstream(ctx *hcl.EvalContext, params *hclsyntax.Body) (*Upstream, error) {
+	useUnhealthy, err := b.useWhenUnhealthy(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var key string
+	if b.loadBalancer.Strategy() == LoadBalancingConsistentHash {
+		v, verr := eval.ValueFromBodyAttribute(ctx, params, "hash_key")
+		if verr != nil {
+			return nil, errors.Evaluation.Label(b.name).With(verr)
+		}
+		key = seetie.ValueToString(v)
+	}
+
+	return b.loadBalancer.Next(key, useUnhealthy)
+}
+
+func (b *Backend) useWhenUnhealthy(ctx *hcl.EvalContext, params *hclsyntax.Body) (bool, error) {
 	val, err := eval.ValueFromBodyAttribute(ctx, params, "use_when_unhealthy")
+	if err != nil {
+		return false, err
+	}
+
+	return val.Type() == cty.Bool && val.True(), nil
+}
+
+func (b *Backend) isUnhealthy(ctx *hcl.EvalContext, params *hclsyntax.Body) error {
+	useUnhealthy, err := b.useWhenUnhealthy(ctx, params)
 	if err != nil {
 		return err
 	}
 
-	var useUnhealthy bool
-	if val.Type() == cty.Bool {
-		useUnhealthy = val.True()
-	} // else not set
+	if useUnhealthy {
+		return nil
+	}
 
 	b.healthyMu.RLock()
-	defer b.healthyMu.RUnlock()
+	healthy := b.healthInfo.Healthy
+	b.healthyMu.RUnlock()
 
-	if b.healthInfo.Healthy || useUnhealthy {
-		return nil
+	if !healthy {
+		return errors.BackendUnhealthy
+	}
+
+	if b.circuitBreaker != nil {
+		return b.circuitBreaker.Allow()
 	}
 
-	return errors.BackendUnhealthy
+	return nil
 }
 
 func (b *Backend) OnProbeChange(info *HealthInfo) {
@@ -600,12 +753,24 @@ func (b *Backend) Value() cty.Value {
 		}
 	}
 
+	health := map[string]interface{}{
+		"healthy": b.healthInfo.Healthy,
+		"error":   b.healthInfo.Error,
+		"state":   b.healthInfo.State,
+	}
+
+	if b.circuitBreaker != nil {
+		circuitState := b.circuitBreaker.State()
+		health["circuit_breaker"] = circuitState
+		if circuitState == CircuitOpen && b.healthInfo.Healthy {
+			health["healthy"] = false
+			health["error"] = "circuit breaker is open"
+			health["state"] = StateDown.String()
+		}
+	}
+
 	result := map[string]interface{}{
-		"health": map[string]interface{}{
-			"healthy": b.healthInfo.Healthy,
-			"error":   b.healthInfo.Error,
-			"state":   b.healthInfo.State,
-		},
+		"health":          health,
 		"hostname":        b.transportConfResult.Hostname,
 		"name":            b.name, // mandatory
 		"origin":          b.transportConfResult.Origin,
@@ -614,6 +779,12 @@ func (b *Backend) Value() cty.Value {
 		"timeout":         b.transportConfResult.Timeout.String(),
 	}
 
+	if b.loadBalancer != nil {
+		origins, originsHealth := b.loadBalancer.value()
+		result["origins"] = origins
+		health["origins"] = originsHealth
+	}
+
 	if tokens != nil {
 		result["beta_tokens"] = tokens
 		if token, ok := tokens["default"]; ok {
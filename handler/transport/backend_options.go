@@ -9,9 +9,13 @@ import (
 
 // BackendOptions represents the transport <BackendOptions> object.
 type BackendOptions struct {
-	RequestAuthz []RequestAuthorizer
-	HealthCheck  *config.HealthCheck
-	OpenAPI      *validation.OpenAPIOptions
+	CircuitBreaker *CircuitBreaker
+	RequestAuthz   []RequestAuthorizer
+	HealthCheck    *config.HealthCheck
+	LoadBalancer   *LoadBalancer
+	OpenAPI        *validation.OpenAPIOptions
+	ResponseCache  *ResponseCache
+	Retry          *Retry
 }
 
 type RequestAuthorizer interface {
@@ -0,0 +1,273 @@
+package transport
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitHalfOpen = "half_open"
+	CircuitOpen     = "open"
+
+	circuitWindowBuckets = 10
+)
+
+var defaultCircuitOnStatus = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// CircuitBreaker passively observes the backend requests and opens the circuit
+// after consecutive failures or if the error rate exceeds the configured threshold.
+// An open circuit fails fast until the cool-down has elapsed, then a single
+// trial request decides whether the circuit gets closed or opened again.
+type CircuitBreaker struct {
+	coolDown         time.Duration
+	errorRate        float64
+	failureThreshold uint
+	minRequests      uint
+	onStatus         map[int]struct{}
+	window           time.Duration
+
+	log *logrus.Entry
+	now func() time.Time
+
+	mu          sync.Mutex
+	buckets     [circuitWindowBuckets]circuitBucket
+	consecutive uint
+	openedAt    time.Time
+	reason      string
+	state       string
+	trialAt     time.Time
+}
+
+type circuitBucket struct {
+	failures uint
+	index    int64
+	total    uint
+}
+
+func NewCircuitBreaker(conf *config.CircuitBreaker, log *logrus.Entry) (*CircuitBreaker, error) {
+	cb := &CircuitBreaker{
+		coolDown:         30 * time.Second,
+		errorRate:        conf.ErrorRate,
+		failureThreshold: 5,
+		minRequests:      10,
+		onStatus:         make(map[int]struct{}),
+		window:           10 * time.Second,
+
+		log:   log,
+		now:   time.Now,
+		state: CircuitClosed,
+	}
+
+	var err error
+	if conf.CoolDown != "" {
+		if cb.coolDown, err = time.ParseDuration(conf.CoolDown); err != nil {
+			return nil, fmt.Errorf("cool_down: %w", err)
+		}
+	}
+
+	if conf.Window != "" {
+		if cb.window, err = time.ParseDuration(conf.Window); err != nil {
+			return nil, fmt.Errorf("window: %w", err)
+		}
+	}
+
+	if cb.window < circuitWindowBuckets {
+		return nil, fmt.Errorf("window: must be greater than %dns", circuitWindowBuckets)
+	}
+
+	if cb.errorRate < 0 || cb.errorRate > 1 {
+		return nil, fmt.Errorf("error_rate: must be between 0 and 1")
+	}
+
+	if conf.FailureThreshold != nil {
+		cb.failureThreshold = *conf.FailureThreshold
+	}
+
+	if conf.MinRequests != nil {
+		cb.minRequests = *conf.MinRequests
+	}
+
+	if cb.failureThreshold == 0 && cb.errorRate == 0 {
+		return nil, fmt.Errorf("either failure_threshold or error_rate must be greater than 0 (zero)")
+	}
+
+	onStatus := defaultCircuitOnStatus
+	if conf.OnStatus != nil {
+		onStatus = conf.OnStatus
+	}
+	for _, status := range onStatus {
+		cb.onStatus[status] = struct{}{}
+	}
+
+	return cb, nil
+}
+
+// Allow returns a backend_unhealthy error if the circuit is open. After the cool-down
+// a single trial request is allowed, further ones are allowed after another cool-down
+// if the trial does not report back, e.g. due to a configuration error.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitClosed {
+		return nil
+	}
+
+	now := cb.now()
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.coolDown {
+		cb.setState(CircuitHalfOpen)
+	}
+
+	if cb.state == CircuitHalfOpen && (cb.trialAt.IsZero() || now.Sub(cb.trialAt) >= cb.coolDown) {
+		cb.trialAt = now
+		return nil
+	}
+
+	return errors.BackendUnhealthy.Message("circuit breaker is open: " + cb.reason)
+}
+
+// Record counts the result of a backend request.
+func (cb *CircuitBreaker) Record(beresp *http.Response, err error) {
+	failed := cb.isFailure(beresp, err)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen: // e.g. use_when_unhealthy requests
+		return
+	case CircuitHalfOpen:
+		if failed {
+			cb.open("trial request failed")
+		} else {
+			cb.close()
+		}
+		return
+	}
+
+	now := cb.now()
+	bucket := cb.bucket(now)
+	bucket.total++
+
+	if !failed {
+		cb.consecutive = 0
+		return
+	}
+
+	bucket.failures++
+	cb.consecutive++
+
+	if cb.failureThreshold > 0 && cb.consecutive >= cb.failureThreshold {
+		cb.open(fmt.Sprintf("%d consecutive failures", cb.consecutive))
+		return
+	}
+
+	if cb.errorRate > 0 {
+		var total, failures uint
+		current := now.UnixNano() / int64(cb.window/circuitWindowBuckets)
+		for _, b := range cb.buckets {
+			if current-b.index < circuitWindowBuckets {
+				total += b.total
+				failures += b.failures
+			}
+		}
+
+		if total >= cb.minRequests && float64(failures)/float64(total) >= cb.errorRate {
+			cb.open(fmt.Sprintf("error rate %.2f within %s", float64(failures)/float64(total), cb.window))
+		}
+	}
+}
+
+// State returns the current circuit state: closed, half_open or open.
+func (cb *CircuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.coolDown {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) isFailure(beresp *http.Response, err error) bool {
+	if err != nil {
+		if goerrors.Is(err, context.Canceled) { // client side
+			return false
+		}
+
+		gerr, ok := err.(*errors.Error)
+		if !ok {
+			return true
+		}
+
+		kinds := gerr.Kinds()
+		return len(kinds) > 0 && (kinds[0] == "backend" || kinds[0] == "backend_timeout")
+	}
+
+	if beresp == nil {
+		return false
+	}
+
+	_, failed := cb.onStatus[beresp.StatusCode]
+	return failed
+}
+
+func (cb *CircuitBreaker) bucket(now time.Time) *circuitBucket {
+	index := now.UnixNano() / int64(cb.window/circuitWindowBuckets)
+	b := &cb.buckets[index%circuitWindowBuckets]
+	if b.index != index {
+		*b = circuitBucket{index: index}
+	}
+	return b
+}
+
+func (cb *CircuitBreaker) open(reason string) {
+	cb.openedAt = cb.now()
+	cb.reason = reason
+	cb.trialAt = time.Time{}
+	cb.setState(CircuitOpen)
+}
+
+func (cb *CircuitBreaker) close() {
+	cb.buckets = [circuitWindowBuckets]circuitBucket{}
+	cb.consecutive = 0
+	cb.reason = ""
+	cb.trialAt = time.Time{}
+	cb.setState(CircuitClosed)
+}
+
+func (cb *CircuitBreaker) setState(state string) {
+	if cb.state == state {
+		return
+	}
+	cb.state = state
+
+	if cb.log == nil {
+		return
+	}
+
+	message := "new circuit breaker state: " + state
+	switch state {
+	case CircuitOpen:
+		cb.log.WithError(errors.BackendUnhealthy.Message(cb.reason + ": " + message)).Error()
+	case CircuitHalfOpen:
+		cb.log.Warn(message)
+	default:
+		cb.log.Info(message)
+	}
+}
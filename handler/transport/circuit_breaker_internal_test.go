@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestCircuitBreaker_Config(t *testing.T) {
+	zero := uint(0)
+
+	for _, tc := range []struct {
+		conf   *config.CircuitBreaker
+		expErr string
+	}{
+		{&config.CircuitBreaker{CoolDown: "1x"}, `cool_down: time: unknown unit "x" in duration "1x"`},
+		{&config.CircuitBreaker{ErrorRate: 1.5}, "error_rate: must be between 0 and 1"},
+		{&config.CircuitBreaker{FailureThreshold: &zero}, "either failure_threshold or error_rate must be greater than 0 (zero)"},
+	} {
+		_, err := NewCircuitBreaker(tc.conf, nil)
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("want error %q, got: %v", tc.expErr, err)
+		}
+	}
+}
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	threshold := uint(2)
+	cb, err := NewCircuitBreaker(&config.CircuitBreaker{FailureThreshold: &threshold, CoolDown: "10s"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	cb.now = func() time.Time { return now }
+
+	failed := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	cb.Record(failed, nil)
+	cb.Record(ok, nil) // resets
+	cb.Record(nil, errors.Backend)
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("want state %q, got %q", CircuitClosed, state)
+	}
+
+	cb.Record(nil, errors.BackendTimeout)
+	if state := cb.State(); state != CircuitOpen {
+		t.Fatalf("want state %q, got %q", CircuitOpen, state)
+	}
+
+	if gerr, ok := cb.Allow().(*errors.Error); !ok || gerr.Kinds()[0] != "backend_unhealthy" ||
+		gerr.LogError() != "backend error: circuit breaker is open: 2 consecutive failures" {
+		t.Errorf("unexpected error: %#v", gerr)
+	}
+
+	now = now.Add(10 * time.Second)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("want state %q, got %q", CircuitHalfOpen, state)
+	}
+
+	if err = cb.Allow(); err != nil {
+		t.Errorf("expected trial request, got: %v", err)
+	}
+	if err = cb.Allow(); err == nil {
+		t.Error("expected a single trial request")
+	}
+
+	cb.Record(failed, nil)
+	if state := cb.State(); state != CircuitOpen {
+		t.Fatalf("want state %q, got %q", CircuitOpen, state)
+	}
+
+	now = now.Add(10 * time.Second)
+	if err = cb.Allow(); err != nil {
+		t.Errorf("expected trial request, got: %v", err)
+	}
+
+	cb.Record(ok, nil)
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("want state %q, got %q", CircuitClosed, state)
+	}
+}
+
+func TestCircuitBreaker_ErrorRate(t *testing.T) {
+	zero, minRequests := uint(0), uint(4)
+	cb, err := NewCircuitBreaker(&config.CircuitBreaker{
+		ErrorRate:        0.5,
+		FailureThreshold: &zero,
+		MinRequests:      &minRequests,
+		Window:           "10s",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	cb.now = func() time.Time { return now }
+
+	failed := &http.Response{StatusCode: http.StatusInternalServerError}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	cb.Record(failed, nil)
+	cb.Record(ok, nil)
+	cb.Record(ok, nil)
+	now = now.Add(11 * time.Second) // previous ones are outside the window
+	cb.Record(failed, nil)
+	cb.Record(ok, nil)
+	cb.Record(failed, nil)
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("want state %q, got %q", CircuitClosed, state)
+	}
+
+	cb.Record(ok, nil)
+	cb.Record(failed, nil)
+	if state := cb.State(); state != CircuitOpen {
+		t.Fatalf("want state %q, got %q", CircuitOpen, state)
+	}
+}
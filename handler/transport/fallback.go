@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+var (
+	defaultFallbackOnErrors = []string{"backend", "backend_timeout", "backend_unhealthy"}
+	defaultFallbackOnStatus = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+var (
+	_ http.RoundTripper = &Fallback{}
+	_ seetie.Object     = &Fallback{}
+)
+
+// Fallback reissues a failed backend request to another backend.
+type Fallback struct {
+	backend  http.RoundTripper
+	fallback http.RoundTripper
+	name     string
+	onErrors map[string]struct{}
+	onStatus map[int]struct{}
+}
+
+// NewFallback wraps the named backend with the given fallback backend.
+func NewFallback(conf *config.Fallback, name string, backend, fallback http.RoundTripper) (*Fallback, error) {
+	f := &Fallback{
+		backend:  backend,
+		fallback: fallback,
+		name:     name,
+		onErrors: make(map[string]struct{}),
+		onStatus: make(map[int]struct{}),
+	}
+
+	onErrors := defaultFallbackOnErrors
+	if conf.OnErrors != nil {
+		onErrors = conf.OnErrors
+	}
+	for _, errType := range onErrors {
+		if _, ok := backendErrorTypes[errType]; !ok {
+			return nil, fmt.Errorf("on_errors: unsupported error type %q", errType)
+		}
+		f.onErrors[errType] = struct{}{}
+	}
+
+	onStatus := defaultFallbackOnStatus
+	if conf.OnStatus != nil {
+		onStatus = conf.OnStatus
+	}
+	for _, status := range onStatus {
+		f.onStatus[status] = struct{}{}
+	}
+
+	return f, nil
+}
+
+// RoundTrip implements the <http.RoundTripper> interface. Request bodies are replayed
+// via GetBody; requests with a non-replayable body are not sent to the fallback backend.
+func (f *Fallback) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if !replayable {
+		return f.backend.RoundTrip(req)
+	}
+
+	// the backend modifies the request url
+	origReq := req.Clone(req.Context())
+
+	beresp, err := f.backend.RoundTrip(req)
+	if !f.shouldFallback(beresp, err) || req.Context().Err() != nil {
+		return beresp, err
+	}
+
+	if beresp != nil && beresp.Body != nil {
+		// drain a small body to allow connection reuse
+		_, _ = io.Copy(io.Discard, io.LimitReader(beresp.Body, retryMaxDrain))
+		_ = beresp.Body.Close()
+	}
+
+	outreq := origReq.WithContext(context.WithValue(origReq.Context(), request.BackendFallbackFor, f.name))
+	if req.GetBody != nil {
+		if outreq.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.fallback.RoundTrip(outreq)
+}
+
+func (f *Fallback) shouldFallback(beresp *http.Response, err error) bool {
+	if err != nil {
+		if gerr, ok := err.(*errors.Error); ok {
+			if kinds := gerr.Kinds(); len(kinds) > 0 {
+				_, fallback := f.onErrors[kinds[0]]
+				return fallback
+			}
+		}
+		return false
+	}
+
+	if beresp == nil {
+		return false
+	}
+
+	_, fallback := f.onStatus[beresp.StatusCode]
+	return fallback
+}
+
+// Value implements the <seetie.Object> interface.
+func (f *Fallback) Value() cty.Value {
+	if obj, ok := f.backend.(seetie.Object); ok {
+		return obj.Value()
+	}
+	return cty.NilVal
+}
@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFallback_Config(t *testing.T) {
+	_, err := NewFallback(&config.Fallback{OnErrors: []string{"endpoint"}}, "be", nil, nil)
+	if err == nil || err.Error() != `on_errors: unsupported error type "endpoint"` {
+		t.Errorf("want unsupported error type, got: %v", err)
+	}
+}
+
+func TestFallback_RoundTrip(t *testing.T) {
+	var primaryStatus int
+	var primaryErr error
+
+	primary := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, _ = io.ReadAll(req.Body)
+		req.URL.Host = "primary.test" // backends modify the request url
+		if primaryErr != nil {
+			return nil, primaryErr
+		}
+		return &http.Response{StatusCode: primaryStatus, Body: http.NoBody}, nil
+	})
+
+	var fallbackBody, fallbackHost string
+	fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		fallbackBody, fallbackHost = string(b), req.URL.Host
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	f, err := NewFallback(&config.Fallback{}, "be", primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		status    int
+		err       error
+		getBody   bool
+		expStatus int
+	}{
+		{"ok", http.StatusOK, nil, true, http.StatusOK},
+		{"client error", http.StatusNotFound, nil, true, http.StatusNotFound},
+		{"server error", http.StatusServiceUnavailable, nil, true, http.StatusOK},
+		{"backend error", 0, errors.Backend, true, http.StatusOK},
+		{"unhealthy", 0, errors.BackendUnhealthy, true, http.StatusOK},
+		{"non-replayable body", http.StatusServiceUnavailable, nil, false, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			primaryStatus, primaryErr = tc.status, tc.err
+			fallbackBody, fallbackHost = "", ""
+
+			req, _ := http.NewRequest(http.MethodPost, "http://origin.test/", strings.NewReader("payload"))
+			if !tc.getBody {
+				req.GetBody = nil
+			}
+
+			res, rerr := f.RoundTrip(req)
+			if tc.err != nil && tc.expStatus != http.StatusOK && rerr == nil {
+				st.Fatal("expected an error")
+			}
+
+			if res != nil && res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expStatus == http.StatusOK && tc.status != http.StatusOK {
+				if fallbackBody != "payload" || fallbackHost != "origin.test" {
+					st.Errorf("want the original request, got body %q and host %q", fallbackBody, fallbackHost)
+				}
+			} else if fallbackHost != "" {
+				st.Error("expected no fallback request")
+			}
+		})
+	}
+}
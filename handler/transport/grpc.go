@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/internal/grpc"
+)
+
+// grpcError maps the transport related status of a gRPC "trailers-only" response to the related
+// backend error kind. Other codes are application errors and passed through as is, like the
+// status of a streamed response which is sent with its trailers.
+func grpcError(name string, beresp *http.Response) error {
+	if beresp == nil || !grpc.IsGRPC(beresp.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	code, ok := grpc.Status(beresp.Header.Get(grpc.StatusHeader))
+	if !ok {
+		return nil
+	}
+
+	var err *errors.Error
+	switch code {
+	case grpc.StatusDeadlineExceeded:
+		err = errors.BackendTimeout
+	case grpc.StatusResourceExhausted, grpc.StatusUnavailable:
+		err = errors.Backend
+	default:
+		return nil
+	}
+
+	if beresp.Body != nil {
+		_ = beresp.Body.Close()
+	}
+
+	msg, uerr := url.PathUnescape(beresp.Header.Get(grpc.MessageHeader))
+	if uerr != nil {
+		msg = beresp.Header.Get(grpc.MessageHeader)
+	}
+	return err.Label(name).Messagef("grpc-status %d: %s", code, msg)
+}
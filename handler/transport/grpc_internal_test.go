@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_grpcError(t *testing.T) {
+	for _, tc := range []struct {
+		status string
+		expErr *errors.Error
+	}{
+		{"0", nil},
+		{"4", errors.BackendTimeout},
+		{"5", nil},
+		{"8", errors.Backend},
+		{"14", errors.Backend},
+		{"16", nil},
+	} {
+		beresp := &http.Response{Header: http.Header{
+			"Content-Type": {"application/grpc"},
+			"Grpc-Status":  {tc.status},
+		}}
+
+		err := grpcError("be", beresp)
+		if tc.expErr == nil {
+			if err != nil {
+				t.Errorf("grpc-status %s: expected no error, got %v", tc.status, err)
+			}
+			continue
+		}
+		if !errors.Equals(err, tc.expErr) {
+			t.Errorf("grpc-status %s: want %v, got %v", tc.status, tc.expErr, err)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package transport
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+const (
+	LoadBalancingConsistentHash   = "consistent_hash"
+	LoadBalancingLeastConnections = "least_connections"
+	LoadBalancingRoundRobin       = "round_robin"
+	LoadBalancingWeighted         = "weighted"
+
+	hashRingReplicas = 100
+)
+
+var (
+	_ http.RoundTripper = &LoadBalancer{}
+	_ ProbeStateChange  = &Upstream{}
+)
+
+// LoadBalancer distributes the backend requests across multiple upstream origins.
+// Upstreams with an unhealthy probe state are excluded from the selection.
+type LoadBalancer struct {
+	hashRing  []hashRingNode
+	listener  ProbeStateChange
+	strategy  string
+	upstreams []*Upstream
+
+	mu   sync.Mutex
+	next int
+
+	transportsMu sync.RWMutex
+	transports   map[string]http.RoundTripper
+}
+
+// Upstream represents one origin of a load balanced backend.
+type Upstream struct {
+	healthCheck *config.HealthCheck
+	lb          *LoadBalancer
+	origin      string
+	weight      uint
+
+	current  int   // smooth weighted round-robin state, guarded by lb.mu
+	inflight int64 // atomic
+
+	healthMu   sync.RWMutex
+	healthInfo *HealthInfo
+
+	transportConf Config
+	transportOnce sync.Once
+}
+
+type hashRingNode struct {
+	hash     uint64
+	upstream *Upstream
+}
+
+// NewLoadBalancer creates a new <*LoadBalancer> object with the given strategy. A health check
+// is created for every upstream origin if health options are given.
+func NewLoadBalancer(strategy string, confs []*config.Upstream, health *config.Health, conf *config.Couper) (*LoadBalancer, error) {
+	switch strategy {
+	case "":
+		strategy = LoadBalancingRoundRobin
+	case LoadBalancingConsistentHash, LoadBalancingLeastConnections, LoadBalancingRoundRobin, LoadBalancingWeighted:
+	default:
+		return nil, fmt.Errorf("load_balancing: unsupported strategy %q", strategy)
+	}
+
+	if len(confs) == 0 {
+		return nil, fmt.Errorf("missing origins")
+	}
+
+	lb := &LoadBalancer{
+		strategy:   strategy,
+		transports: make(map[string]http.RoundTripper),
+	}
+
+	unique := make(map[string]struct{})
+	for _, c := range confs {
+		u, err := url.Parse(c.Origin)
+		if err != nil {
+			return nil, err
+		} else if !u.IsAbs() || u.Hostname() == "" || u.Path != "" && u.Path != "/" {
+			return nil, fmt.Errorf("the origin has to contain an absolute URL with a valid hostname and without a path: %q", c.Origin)
+		}
+
+		if _, exist := unique[u.Host]; exist {
+			return nil, fmt.Errorf("duplicate origin: %q", c.Origin)
+		}
+		unique[u.Host] = struct{}{}
+
+		up := &Upstream{
+			healthInfo: &HealthInfo{Healthy: true, Origin: c.Origin, State: StateOk.String()},
+			lb:         lb,
+			origin:     c.Origin,
+			weight:     1,
+		}
+
+		if c.Weight != nil {
+			if *c.Weight == 0 {
+				return nil, fmt.Errorf("weight of %q must be greater than 0 (zero)", c.Origin)
+			}
+			up.weight = *c.Weight
+		}
+
+		if health != nil {
+			if up.healthCheck, err = config.NewHealthCheck(c.Origin, health, conf); err != nil {
+				return nil, err
+			}
+		}
+
+		lb.upstreams = append(lb.upstreams, up)
+	}
+
+	if strategy == LoadBalancingConsistentHash {
+		for _, up := range lb.upstreams {
+			for i := 0; i < hashRingReplicas*int(up.weight); i++ {
+				lb.hashRing = append(lb.hashRing, hashRingNode{hash: hashKey(up.origin + "#" + strconv.Itoa(i)), upstream: up})
+			}
+		}
+		sort.Slice(lb.hashRing, func(i, j int) bool {
+			return lb.hashRing[i].hash < lb.hashRing[j].hash
+		})
+	}
+
+	return lb, nil
+}
+
+// Strategy returns the configured load balancing strategy.
+func (lb *LoadBalancer) Strategy() string {
+	return lb.strategy
+}
+
+// Next selects the upstream for the next request. The given key is used
+// for the consistent_hash strategy, an empty one falls back to round-robin.
+func (lb *LoadBalancer) Next(key string, useUnhealthy bool) (*Upstream, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var up *Upstream
+	switch {
+	case lb.strategy == LoadBalancingConsistentHash && key != "":
+		up = lb.nextHash(key, useUnhealthy)
+	case lb.strategy == LoadBalancingLeastConnections:
+		up = lb.nextLeastConnections(useUnhealthy)
+	case lb.strategy == LoadBalancingWeighted:
+		up = lb.nextWeighted(useUnhealthy)
+	default:
+		up = lb.nextRoundRobin(useUnhealthy)
+	}
+
+	if up == nil {
+		return nil, errors.BackendUnhealthy.Message("all origins are unhealthy")
+	}
+	return up, nil
+}
+
+func (lb *LoadBalancer) nextRoundRobin(useUnhealthy bool) *Upstream {
+	for i := 0; i < len(lb.upstreams); i++ {
+		idx := (lb.next + i) % len(lb.upstreams)
+		if up := lb.upstreams[idx]; useUnhealthy || up.healthy() {
+			lb.next = idx + 1
+			return up
+		}
+	}
+	return nil
+}
+
+// nextWeighted implements the smooth weighted round-robin algorithm.
+func (lb *LoadBalancer) nextWeighted(useUnhealthy bool) *Upstream {
+	var (
+		best  *Upstream
+		total int
+	)
+	for _, up := range lb.upstreams {
+		if !useUnhealthy && !up.healthy() {
+			continue
+		}
+		up.current += int(up.weight)
+		total += int(up.weight)
+		if best == nil || up.current > best.current {
+			best = up
+		}
+	}
+
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+func (lb *LoadBalancer) nextLeastConnections(useUnhealthy bool) *Upstream {
+	var best *Upstream
+	// start with a rotating offset to distribute equal loads
+	for i := 0; i < len(lb.upstreams); i++ {
+		up := lb.upstreams[(lb.next+i)%len(lb.upstreams)]
+		if !useUnhealthy && !up.healthy() {
+			continue
+		}
+		// compare inflight/weight ratios
+		if best == nil || atomic.LoadInt64(&up.inflight)*int64(best.weight) < atomic.LoadInt64(&best.inflight)*int64(up.weight) {
+			best = up
+		}
+	}
+	lb.next++
+	return best
+}
+
+func (lb *LoadBalancer) nextHash(key string, useUnhealthy bool) *Upstream {
+	h := hashKey(key)
+	start := sort.Search(len(lb.hashRing), func(i int) bool {
+		return lb.hashRing[i].hash >= h
+	})
+
+	for i := 0; i < len(lb.hashRing); i++ {
+		if up := lb.hashRing[(start+i)%len(lb.hashRing)].upstream; useUnhealthy || up.healthy() {
+			return up
+		}
+	}
+	return nil
+}
+
+// RoundTrip implements the <http.RoundTripper> interface and passes the request
+// to the transport of the origin which has been set by <Upstream.TransportConf()>.
+func (lb *LoadBalancer) RoundTrip(req *http.Request) (*http.Response, error) {
+	lb.transportsMu.RLock()
+	t, exist := lb.transports[req.URL.Host]
+	lb.transportsMu.RUnlock()
+
+	if !exist {
+		return nil, fmt.Errorf("no transport for origin %q", req.URL.Host)
+	}
+	return t.RoundTrip(req)
+}
+
+func (lb *LoadBalancer) startProbes(log *logrus.Entry, tc *Config) {
+	for _, up := range lb.upstreams {
+		if up.healthCheck != nil {
+			NewProbe(log, tc, up.healthCheck, up)
+		}
+	}
+}
+
+// healthInfo aggregates the upstream health states: the backend is healthy
+// as long as one upstream is healthy.
+func (lb *LoadBalancer) healthInfo() *HealthInfo {
+	var unhealthy int
+	for _, up := range lb.upstreams {
+		if !up.healthy() {
+			unhealthy++
+		}
+	}
+
+	switch unhealthy {
+	case 0:
+		return &HealthInfo{Healthy: true, State: StateOk.String()}
+	case len(lb.upstreams):
+		return &HealthInfo{Error: "all origins are unhealthy", State: StateDown.String()}
+	default:
+		return &HealthInfo{
+			Error:   fmt.Sprintf("%d of %d origins are unhealthy", unhealthy, len(lb.upstreams)),
+			Healthy: true,
+			State:   StateFailing.String(),
+		}
+	}
+}
+
+func (lb *LoadBalancer) value() (origins []string, health map[string]interface{}) {
+	health = make(map[string]interface{}, len(lb.upstreams))
+	for _, up := range lb.upstreams {
+		origins = append(origins, up.origin)
+
+		up.healthMu.RLock()
+		health[up.origin] = map[string]interface{}{
+			"error":   up.healthInfo.Error,
+			"healthy": up.healthInfo.Healthy,
+			"state":   up.healthInfo.State,
+		}
+		up.healthMu.RUnlock()
+	}
+	return origins, health
+}
+
+// OnProbeChange implements the <ProbeStateChange> interface.
+func (u *Upstream) OnProbeChange(info *HealthInfo) {
+	u.healthMu.Lock()
+	u.healthInfo = info
+	u.healthMu.Unlock()
+
+	if u.lb.listener != nil {
+		u.lb.listener.OnProbeChange(u.lb.healthInfo())
+	}
+}
+
+// TransportConf pins the first given configuration for this origin and
+// registers a related transport with the load balancer.
+func (u *Upstream) TransportConf(conf *Config, log *logrus.Entry) Config {
+	u.transportOnce.Do(func() {
+		u.transportConf = *conf
+		u.lb.transportsMu.Lock()
+		u.lb.transports[conf.Origin] = NewTransport(conf, log)
+		u.lb.transportsMu.Unlock()
+	})
+	return u.transportConf
+}
+
+// Origin returns the configured origin URL.
+func (u *Upstream) Origin() string {
+	return u.origin
+}
+
+func (u *Upstream) healthy() bool {
+	u.healthMu.RLock()
+	defer u.healthMu.RUnlock()
+	return u.healthInfo.Healthy
+}
+
+// acquire counts the request as active until the response body gets closed.
+func (u *Upstream) acquire() func(res *http.Response, upgrade bool) {
+	atomic.AddInt64(&u.inflight, 1)
+	return func(res *http.Response, upgrade bool) {
+		if res == nil || res.Body == nil || upgrade {
+			atomic.AddInt64(&u.inflight, -1)
+			return
+		}
+		res.Body = &releaseBody{ReadCloser: res.Body, release: func() {
+			atomic.AddInt64(&u.inflight, -1)
+		}}
+	}
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseBody) Close() error {
+	r.once.Do(r.release)
+	return r.ReadCloser.Close()
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
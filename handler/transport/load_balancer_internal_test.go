@@ -0,0 +1,176 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/config"
+)
+
+func newTestUpstreams(weights ...uint) []*config.Upstream {
+	var confs []*config.Upstream
+	for i, w := range weights {
+		weight := w
+		confs = append(confs, &config.Upstream{
+			Origin: "http://origin-" + string(rune('a'+i)) + ".test",
+			Weight: &weight,
+		})
+	}
+	return confs
+}
+
+func TestLoadBalancer_Config(t *testing.T) {
+	zero := uint(0)
+
+	for _, tc := range []struct {
+		strategy string
+		confs    []*config.Upstream
+		expErr   string
+	}{
+		{"random", newTestUpstreams(1), `load_balancing: unsupported strategy "random"`},
+		{"", nil, "missing origins"},
+		{"", []*config.Upstream{{Origin: "/relative"}}, `the origin has to contain an absolute URL with a valid hostname and without a path: "/relative"`},
+		{"", []*config.Upstream{{Origin: "http://a.test/path"}}, `the origin has to contain an absolute URL with a valid hostname and without a path: "http://a.test/path"`},
+		{"", []*config.Upstream{{Origin: "http://a.test"}, {Origin: "http://a.test"}}, `duplicate origin: "http://a.test"`},
+		{"", []*config.Upstream{{Origin: "http://a.test", Weight: &zero}}, `weight of "http://a.test" must be greater than 0 (zero)`},
+	} {
+		_, err := NewLoadBalancer(tc.strategy, tc.confs, nil, nil)
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("want error %q, got: %v", tc.expErr, err)
+		}
+	}
+}
+
+func TestLoadBalancer_Strategies(t *testing.T) {
+	for _, tc := range []struct {
+		strategy string
+		weights  []uint
+		expected []string
+	}{
+		{LoadBalancingRoundRobin, []uint{1, 3}, []string{"a", "b", "a", "b"}},
+		{LoadBalancingWeighted, []uint{1, 3}, []string{"b", "a", "b", "b", "b", "a", "b", "b"}},
+		{LoadBalancingLeastConnections, []uint{1, 1, 1}, []string{"a", "b", "c", "a"}},
+	} {
+		t.Run(tc.strategy, func(st *testing.T) {
+			lb, err := NewLoadBalancer(tc.strategy, newTestUpstreams(tc.weights...), nil, nil)
+			if err != nil {
+				st.Fatal(err)
+			}
+
+			for i, exp := range tc.expected {
+				up, nerr := lb.Next("", false)
+				if nerr != nil {
+					st.Fatal(nerr)
+				}
+				if up.Origin() != "http://origin-"+exp+".test" {
+					st.Errorf("%d: want origin %q, got %q", i, exp, up.Origin())
+				}
+			}
+		})
+	}
+}
+
+func TestLoadBalancer_LeastConnections(t *testing.T) {
+	lb, err := NewLoadBalancer(LoadBalancingLeastConnections, newTestUpstreams(1, 1), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := lb.Next("", false)
+	release := first.acquire()
+
+	for i := 0; i < 3; i++ {
+		if up, _ := lb.Next("", false); up == first {
+			t.Errorf("%d: expected the upstream without active requests", i)
+		}
+	}
+
+	res := &http.Response{Body: http.NoBody}
+	release(res, false)
+	if first.inflight != 1 {
+		t.Errorf("expected an active request until the body is closed, got: %d", first.inflight)
+	}
+
+	_ = res.Body.Close()
+	_ = res.Body.Close()
+	if first.inflight != 0 {
+		t.Errorf("expected no active requests, got: %d", first.inflight)
+	}
+}
+
+func TestLoadBalancer_ConsistentHash(t *testing.T) {
+	lb, err := NewLoadBalancer(LoadBalancingConsistentHash, newTestUpstreams(1, 1, 1), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selected := make(map[string]*Upstream)
+	for _, key := range []string{"alice", "bob", "carol", "dave", "eve", "frank"} {
+		up, nerr := lb.Next(key, false)
+		if nerr != nil {
+			t.Fatal(nerr)
+		}
+		selected[key] = up
+
+		for i := 0; i < 3; i++ {
+			if again, _ := lb.Next(key, false); again != up {
+				t.Errorf("%s: expected a stable origin", key)
+			}
+		}
+	}
+
+	// exclude an unhealthy upstream, other keys must not be moved
+	unhealthy := selected["alice"]
+	unhealthy.OnProbeChange(&HealthInfo{State: StateDown.String()})
+
+	for key, up := range selected {
+		next, _ := lb.Next(key, false)
+		if up == unhealthy && next == unhealthy {
+			t.Errorf("%s: expected another origin than the unhealthy one", key)
+		} else if up != unhealthy && next != up {
+			t.Errorf("%s: expected an unchanged origin", key)
+		}
+	}
+}
+
+func TestLoadBalancer_Unhealthy(t *testing.T) {
+	lb, err := NewLoadBalancer(LoadBalancingRoundRobin, newTestUpstreams(1, 1), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := &probeListener{}
+	lb.listener = listener
+
+	lb.upstreams[0].OnProbeChange(&HealthInfo{State: StateDown.String()})
+	if listener.info.State != StateFailing.String() || !listener.info.Healthy {
+		t.Errorf("expected a failing but healthy backend, got: %#v", listener.info)
+	}
+
+	for i := 0; i < 3; i++ {
+		if up, _ := lb.Next("", false); up != lb.upstreams[1] {
+			t.Errorf("%d: expected the healthy upstream", i)
+		}
+	}
+
+	lb.upstreams[1].OnProbeChange(&HealthInfo{State: StateDown.String()})
+	if listener.info.Healthy || listener.info.Error != "all origins are unhealthy" {
+		t.Errorf("expected an unhealthy backend, got: %#v", listener.info)
+	}
+
+	if _, err = lb.Next("", false); err == nil {
+		t.Error("expected a backend_unhealthy error")
+	}
+
+	if up, _ := lb.Next("", true); up == nil {
+		t.Error("expected an upstream with use_when_unhealthy")
+	}
+}
+
+type probeListener struct {
+	info *HealthInfo
+}
+
+func (p *probeListener) OnProbeChange(info *HealthInfo) {
+	p.info = info
+}
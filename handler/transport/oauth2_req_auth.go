@@ -117,7 +117,7 @@ func (ac *assertionCreatorFromJSP) createAssertion(_ *hcl.EvalContext) (string,
 type OAuth2ReqAuth struct {
 	config           *config.OAuth2ReqAuth
 	mu               sync.Mutex
-	memStore         *cache.MemoryStore
+	memStore         cache.Store
 	oauth2Client     *oauth2.Client
 	storageKey       string
 	assertionCreator assertionCreator
@@ -125,7 +125,7 @@ type OAuth2ReqAuth struct {
 
 // NewOAuth2ReqAuth implements the http.RoundTripper interface to wrap an existing Backend / http.RoundTripper
 // to retrieve a valid token before passing the initial out request.
-func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memStore *cache.MemoryStore,
+func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memStore cache.Store,
 	asBackend http.RoundTripper) (RequestAuthorizer, error) {
 
 	if _, supported := supportedGrantTypes[conf.GrantType]; !supported {
@@ -181,7 +181,8 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 		memStore:         memStore,
 		assertionCreator: assertionCreator,
 	}
-	reqAuth.storageKey = fmt.Sprintf("oauth2-%p", reqAuth)
+	reqAuth.storageKey = newStorageKey("oauth2-", conf.Remain,
+		conf.TokenEndpoint, conf.GrantType, conf.ClientID, conf.Username, conf.Scope)
 	return reqAuth, nil
 }
 
@@ -0,0 +1,536 @@
+package transport
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const (
+	CacheStatusHit         = "HIT"
+	CacheStatusMiss        = "MISS"
+	CacheStatusRevalidated = "REVALIDATED"
+	CacheStatusStale       = "STALE"
+
+	responseCachePrefix = "response_cache_"
+	// staleRetention is the duration a stale response with validators
+	// is kept for revalidation purposes.
+	staleRetention = time.Hour
+)
+
+// cacheableStatus lists the status codes which are cacheable by default, see RFC 9110, section 15.1.
+var cacheableStatus = map[int]struct{}{
+	http.StatusOK:                   {},
+	http.StatusNonAuthoritativeInfo: {},
+	http.StatusNoContent:            {},
+	http.StatusMultipleChoices:      {},
+	http.StatusMovedPermanently:     {},
+	http.StatusPermanentRedirect:    {},
+	http.StatusNotFound:             {},
+	http.StatusMethodNotAllowed:     {},
+	http.StatusGone:                 {},
+	http.StatusRequestURITooLong:    {},
+	http.StatusNotImplemented:       {},
+}
+
+// conditionalHeaders are request header fields which make a request conditional.
+var conditionalHeaders = []string{"If-Match", "If-Modified-Since", "If-None-Match", "If-Range", "If-Unmodified-Since"}
+
+// ResponseCache is a shared HTTP cache (RFC 9111) for backend responses
+// of GET and HEAD requests which is backed by the memory store. Stored responses
+// are runtime objects, so a shared store backend is never queried.
+type ResponseCache struct {
+	keyExpr      hcl.Expression
+	maxEntrySize int64
+	maxSize      int64
+	prefix       string
+	store        cache.Store
+
+	// entries holds the stored variant keys in least recently used order
+	// to keep the total body size below maxSize.
+	entries *list.List
+	index   map[string]*list.Element
+	mu      sync.Mutex
+	size    int64
+}
+
+type cacheEntry struct {
+	expiresAt time.Time
+	key       string
+	size      int64
+}
+
+type cachedResponse struct {
+	age           time.Duration
+	body          []byte
+	contentLength int64
+	header        http.Header
+	lifetime      time.Duration
+	status        int
+	storedAt      time.Time
+}
+
+func NewResponseCache(conf *config.ResponseCache, name string, store cache.Store) (*ResponseCache, error) {
+	maxEntrySize := int64(units.MiB)
+	if conf.MaxEntrySize != "" {
+		size, err := units.RAMInBytes(conf.MaxEntrySize)
+		if err != nil {
+			return nil, fmt.Errorf("max_entry_size: %w", err)
+		}
+		maxEntrySize = size
+	}
+
+	maxSize := int64(64 * units.MiB)
+	if conf.MaxSize != "" {
+		size, err := units.RAMInBytes(conf.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("max_size: %w", err)
+		}
+		maxSize = size
+	}
+
+	if maxEntrySize > maxSize {
+		maxEntrySize = maxSize
+	}
+
+	return &ResponseCache{
+		entries:      list.New(),
+		index:        make(map[string]*list.Element),
+		keyExpr:      conf.CacheKey,
+		maxEntrySize: maxEntrySize,
+		maxSize:      maxSize,
+		prefix:       responseCachePrefix + name + "|",
+		store:        cache.Local(store),
+	}, nil
+}
+
+// NewResponseCacheContext passes the given response cache to the backend of the given
+// round-tripper, overriding the response cache of the backend itself.
+func NewResponseCacheContext(rc *ResponseCache, rt http.RoundTripper) http.RoundTripper {
+	return &responseCacheContext{rc: rc, rt: rt}
+}
+
+type responseCacheContext struct {
+	rc *ResponseCache
+	rt http.RoundTripper
+}
+
+func (r *responseCacheContext) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.rt.RoundTrip(req.WithContext(context.WithValue(req.Context(), request.ResponseCache, r.rc)))
+}
+
+// Serve answers the given request with a stored response if possible, otherwise
+// the request gets passed to next and a cacheable response is stored.
+func (rc *ResponseCache) Serve(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	reqCC := parseCacheControl(req.Header)
+	_, noStore := reqCC["no-store"]
+	if noStore || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		beresp, err := next(req)
+		withCacheStatus(beresp, CacheStatusMiss)
+		return beresp, err
+	}
+
+	key, err := rc.key(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored *cachedResponse
+	var variantKey string
+	if !isConditional(req.Header) {
+		stored, variantKey = rc.lookup(key, req)
+	}
+
+	now := time.Now()
+	if _, noCache := reqCC["no-cache"]; stored != nil && !noCache && stored.isFresh(now) {
+		rc.touch(variantKey)
+		beresp := stored.response(req, now)
+		withCacheStatus(beresp, CacheStatusHit)
+		return beresp, nil
+	}
+
+	outreq := req
+	if stored != nil && stored.hasValidators() {
+		outreq = req.Clone(req.Context())
+		if etag := stored.header.Get("ETag"); etag != "" {
+			outreq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := stored.header.Get("Last-Modified"); lastModified != "" {
+			outreq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	beresp, err := next(outreq)
+	if err != nil {
+		if stored != nil && stored.mayServeStale() {
+			rc.touch(variantKey)
+			res := stored.response(req, time.Now())
+			withCacheStatus(res, CacheStatusStale)
+			return res, nil
+		}
+		return beresp, err
+	}
+
+	if outreq != req && beresp.StatusCode == http.StatusNotModified {
+		_ = beresp.Body.Close()
+
+		now = time.Now()
+		updated := stored.revalidated(beresp.Header, now)
+		if ttl := updated.ttl(); ttl > 0 {
+			rc.store.Set(variantKey, updated, ttl)
+			rc.track(variantKey, int64(len(updated.body)), ttl)
+		}
+		res := updated.response(beresp.Request, now)
+		withCacheStatus(res, CacheStatusRevalidated)
+		return res, nil
+	}
+
+	if err = rc.storeResponse(key, req, beresp); err != nil {
+		return nil, err
+	}
+
+	withCacheStatus(beresp, CacheStatusMiss)
+	return beresp, nil
+}
+
+// key returns the primary cache key built from the request method, the backend
+// request URL and the optional cache_key expression result.
+func (rc *ResponseCache) key(req *http.Request) (string, error) {
+	key := rc.prefix + req.Method + " " + req.URL.String()
+
+	if rc.keyExpr != nil {
+		v, err := eval.Value(eval.ContextFromRequest(req).HCLContextSync(), rc.keyExpr)
+		if err != nil {
+			return "", errors.Evaluation.With(err)
+		}
+		key += "|" + seetie.ValueToString(v)
+	}
+
+	return key, nil
+}
+
+// lookup returns the stored response variant matching the request header fields
+// nominated by the Vary response header field.
+func (rc *ResponseCache) lookup(key string, req *http.Request) (*cachedResponse, string) {
+	names, ok := rc.store.Get(key).([]string)
+	if !ok {
+		return nil, ""
+	}
+
+	variantKey := newVariantKey(key, names, req.Header)
+	stored, _ := rc.store.Get(variantKey).(*cachedResponse)
+	return stored, variantKey
+}
+
+// storeResponse reads the body of a cacheable response and stores the response. The body
+// of the given response gets replaced to remain readable.
+func (rc *ResponseCache) storeResponse(key string, req *http.Request, beresp *http.Response) error {
+	if _, ok := cacheableStatus[beresp.StatusCode]; !ok || beresp.Body == nil {
+		return nil
+	}
+
+	resCC := parseCacheControl(beresp.Header)
+	if _, noStore := resCC["no-store"]; noStore {
+		return nil
+	}
+	if _, private := resCC["private"]; private {
+		return nil
+	}
+
+	if len(beresp.Header.Values("Set-Cookie")) > 0 {
+		return nil
+	}
+
+	if req.Header.Get("Authorization") != "" {
+		_, public := resCC["public"]
+		_, sMaxAge := resCC["s-maxage"]
+		_, mustRevalidate := resCC["must-revalidate"]
+		if !public && !sMaxAge && !mustRevalidate {
+			return nil
+		}
+	}
+
+	names := varyNames(beresp.Header)
+	for _, name := range names {
+		if name == "*" {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	stored := &cachedResponse{
+		header:   beresp.Header.Clone(),
+		status:   beresp.StatusCode,
+		storedAt: now,
+	}
+	stored.age, stored.lifetime = freshness(beresp.Header, now)
+
+	ttl := stored.ttl()
+	if ttl <= 0 || beresp.ContentLength > rc.maxEntrySize {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(beresp.Body, rc.maxEntrySize+1))
+	if err != nil {
+		return errors.Backend.With(err).Message("response_cache: reading body")
+	}
+
+	if int64(len(body)) > rc.maxEntrySize {
+		beresp.Body = eval.NewReadCloser(io.MultiReader(bytes.NewReader(body), beresp.Body), beresp.Body)
+		return nil
+	}
+
+	_ = beresp.Body.Close()
+	beresp.Body = io.NopCloser(bytes.NewReader(body))
+	stored.body = body
+	stored.contentLength = int64(len(body))
+	if req.Method == http.MethodHead {
+		stored.contentLength = beresp.ContentLength
+	}
+
+	variantKey := newVariantKey(key, names, req.Header)
+	rc.store.Set(key, names, ttl)
+	rc.store.Set(variantKey, stored, ttl)
+	rc.track(variantKey, int64(len(body)), ttl)
+
+	return nil
+}
+
+// track registers a stored response as most recently used and evicts
+// expired and least recently used responses exceeding the total size limit.
+func (rc *ResponseCache) track(variantKey string, size int64, ttl int64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if elem, ok := rc.index[variantKey]; ok {
+		rc.remove(elem)
+	}
+
+	now := time.Now()
+	rc.index[variantKey] = rc.entries.PushFront(&cacheEntry{
+		expiresAt: now.Add(time.Duration(ttl) * time.Second),
+		key:       variantKey,
+		size:      size,
+	})
+	rc.size += size
+
+	if rc.size <= rc.maxSize {
+		return
+	}
+
+	for elem := rc.entries.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*cacheEntry).expiresAt.Before(now) {
+			rc.remove(elem)
+		}
+		elem = prev
+	}
+
+	for rc.size > rc.maxSize && rc.entries.Len() > 0 {
+		elem := rc.entries.Back()
+		rc.store.Del(elem.Value.(*cacheEntry).key)
+		rc.remove(elem)
+	}
+}
+
+// touch marks a stored response as most recently used.
+func (rc *ResponseCache) touch(variantKey string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if elem, ok := rc.index[variantKey]; ok {
+		rc.entries.MoveToFront(elem)
+	}
+}
+
+func (rc *ResponseCache) remove(elem *list.Element) {
+	entry := rc.entries.Remove(elem).(*cacheEntry)
+	delete(rc.index, entry.key)
+	rc.size -= entry.size
+}
+
+func (c *cachedResponse) currentAge(now time.Time) time.Duration {
+	return c.age + now.Sub(c.storedAt)
+}
+
+func (c *cachedResponse) isFresh(now time.Time) bool {
+	return c.currentAge(now) < c.lifetime
+}
+
+// mayServeStale reports whether the stale response may be served if the origin
+// is unreachable, see RFC 9111, section 4.2.4.
+func (c *cachedResponse) mayServeStale() bool {
+	cc := parseCacheControl(c.header)
+	for _, directive := range []string{"must-revalidate", "no-cache", "proxy-revalidate", "s-maxage"} {
+		if _, ok := cc[directive]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cachedResponse) hasValidators() bool {
+	return c.header.Get("ETag") != "" || c.header.Get("Last-Modified") != ""
+}
+
+// ttl returns the seconds the response should be kept in the memory store.
+func (c *cachedResponse) ttl() int64 {
+	remaining := c.lifetime - c.age
+	if c.hasValidators() {
+		remaining += staleRetention
+	}
+
+	if remaining <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(remaining.Seconds()))
+}
+
+// revalidated returns a copy with the header fields of a 304 (Not Modified) response applied.
+func (c *cachedResponse) revalidated(header http.Header, now time.Time) *cachedResponse {
+	updated := &cachedResponse{
+		body:          c.body,
+		contentLength: c.contentLength,
+		header:        c.header.Clone(),
+		status:        c.status,
+		storedAt:      now,
+	}
+
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		updated.header[name] = values
+	}
+
+	updated.age, updated.lifetime = freshness(updated.header, now)
+	return updated
+}
+
+func (c *cachedResponse) response(req *http.Request, now time.Time) *http.Response {
+	header := c.header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(c.currentAge(now).Seconds()), 10))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.status, http.StatusText(c.status)),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: c.contentLength,
+		Request:       req,
+	}
+}
+
+// withCacheStatus provides the cache status to the backend response variables.
+func withCacheStatus(beresp *http.Response, status string) {
+	if beresp != nil && beresp.Request != nil {
+		beresp.Request = beresp.Request.WithContext(
+			context.WithValue(beresp.Request.Context(), request.ResponseCacheStatus, status))
+	}
+}
+
+// freshness returns the initial age and the freshness lifetime of a response, see RFC 9111, section 4.2.
+func freshness(header http.Header, now time.Time) (age time.Duration, lifetime time.Duration) {
+	if a, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && a > 0 {
+		age = time.Duration(a) * time.Second
+	}
+
+	cc := parseCacheControl(header)
+	if _, noCache := cc["no-cache"]; noCache {
+		return age, 0
+	}
+
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if v, ok := cc[directive]; ok {
+			seconds, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || seconds < 0 {
+				return age, 0
+			}
+			return age, time.Duration(seconds) * time.Second
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return age, 0
+		}
+
+		date := now
+		if d, derr := http.ParseTime(header.Get("Date")); derr == nil {
+			date = d
+		}
+		return age, expiresAt.Sub(date)
+	}
+
+	return age, 0
+}
+
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, arg, _ := strings.Cut(directive, "=")
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return directives
+}
+
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newVariantKey returns the key of a stored response. The primary key itself holds the Vary header field names.
+func newVariantKey(key string, names []string, header http.Header) string {
+	variantKey := key + "|variant"
+	for _, name := range names {
+		variantKey += "|" + name + "=" + strings.Join(header.Values(name), ",")
+	}
+	return variantKey
+}
+
+func isConditional(header http.Header) bool {
+	for _, name := range conditionalHeaders {
+		if header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
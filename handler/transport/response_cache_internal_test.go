@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+)
+
+func TestResponseCache_freshness(t *testing.T) {
+	now := time.Now()
+
+	for _, tc := range []struct {
+		name        string
+		header      http.Header
+		expAge      time.Duration
+		expLifetime time.Duration
+	}{
+		{"no cache headers", http.Header{}, 0, 0},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=60"}}, 0, time.Minute},
+		{"s-maxage precedence", http.Header{"Cache-Control": {"max-age=60, s-maxage=10"}}, 0, 10 * time.Second},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache, max-age=60"}}, 0, 0},
+		{"invalid max-age", http.Header{"Cache-Control": {"max-age=foo"}}, 0, 0},
+		{"age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"20"}}, 20 * time.Second, time.Minute},
+		{"expires", http.Header{
+			"Date":    {now.UTC().Format(http.TimeFormat)},
+			"Expires": {now.Add(time.Hour).UTC().Format(http.TimeFormat)},
+		}, 0, time.Hour},
+		{"invalid expires", http.Header{"Expires": {"0"}}, 0, 0},
+	} {
+		age, lifetime := freshness(tc.header, now)
+		if age != tc.expAge {
+			t.Errorf("%s: want age %s, got %s", tc.name, tc.expAge, age)
+		}
+		if lifetime != tc.expLifetime {
+			t.Errorf("%s: want lifetime %s, got %s", tc.name, tc.expLifetime, lifetime)
+		}
+	}
+}
+
+func TestResponseCache_variantKey(t *testing.T) {
+	names := varyNames(http.Header{"Vary": {"accept-language, Accept"}})
+	if len(names) != 2 || names[0] != "Accept" || names[1] != "Accept-Language" {
+		t.Fatalf("unexpected vary names: %v", names)
+	}
+
+	de := newVariantKey("key", names, http.Header{"Accept": {"text/html"}, "Accept-Language": {"de"}})
+	en := newVariantKey("key", names, http.Header{"Accept": {"text/html"}, "Accept-Language": {"en"}})
+	if de == en {
+		t.Errorf("expected different variant keys, got %q", de)
+	}
+
+	if newVariantKey("key", nil, http.Header{}) == "key" {
+		t.Error("expected variant key to differ from primary key")
+	}
+}
+
+func TestResponseCache_maxSize(t *testing.T) {
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	store := cache.New(logrus.NewEntry(logrus.New()), quitCh)
+	rc, err := NewResponseCache(&config.ResponseCache{MaxSize: "2KiB"}, "test", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rc.maxEntrySize != 2048 {
+		t.Errorf("expected max_entry_size to be limited by max_size, got %d", rc.maxEntrySize)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		store.Set(key, &cachedResponse{}, 60)
+		rc.track(key, 1024, 60)
+	}
+
+	rc.touch("a")
+	store.Set("c", &cachedResponse{}, 60)
+	rc.track("c", 1024, 60)
+
+	if store.Get("b") != nil {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if store.Get("a") == nil || store.Get("c") == nil {
+		t.Error("expected recently used entries to be kept")
+	}
+	if rc.size != 2048 || rc.entries.Len() != 2 {
+		t.Errorf("unexpected total size %d with %d entries", rc.size, rc.entries.Len())
+	}
+
+	rc.track("c", 512, 60)
+	if rc.size != 1536 || rc.entries.Len() != 2 {
+		t.Errorf("expected replaced entry to be accounted once, got total size %d with %d entries", rc.size, rc.entries.Len())
+	}
+}
+
+func TestResponseCache_sharedStore(t *testing.T) {
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	store, err := cache.NewFileStore(t.TempDir(), logrus.NewEntry(logrus.New()), quitCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := NewResponseCache(&config.ResponseCache{}, "test", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := rc.store.(*cache.MemoryStore); !ok {
+		t.Errorf("expected memory store for cached responses, got %T", rc.store)
+	}
+}
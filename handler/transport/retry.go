@@ -0,0 +1,209 @@
+package transport
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+const retryMaxDrain = 64 << 10
+
+var (
+	defaultRetryMethods  = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+	defaultRetryOnErrors = []string{"backend", "backend_unhealthy"}
+	defaultRetryOnStatus = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+	backendErrorTypes = map[string]struct{}{
+		"backend":           {},
+		"backend_timeout":   {},
+		"backend_unhealthy": {},
+	}
+)
+
+// Retry represents the retry policy of a backend.
+type Retry struct {
+	attempts   uint
+	backoff    time.Duration
+	maxBackoff time.Duration
+	methods    map[string]struct{}
+	onErrors   map[string]struct{}
+	onStatus   map[int]struct{}
+}
+
+func NewRetry(conf *config.Retry) (*Retry, error) {
+	r := &Retry{
+		attempts:   3,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 2 * time.Second,
+		methods:    make(map[string]struct{}),
+		onErrors:   make(map[string]struct{}),
+		onStatus:   make(map[int]struct{}),
+	}
+
+	if conf.Attempts != nil {
+		if *conf.Attempts == 0 {
+			return nil, fmt.Errorf("attempts must not be 0 (zero)")
+		}
+		r.attempts = *conf.Attempts
+	}
+
+	var err error
+	if conf.Backoff != "" {
+		if r.backoff, err = time.ParseDuration(conf.Backoff); err != nil {
+			return nil, fmt.Errorf("backoff: %w", err)
+		}
+	}
+
+	if conf.MaxBackoff != "" {
+		if r.maxBackoff, err = time.ParseDuration(conf.MaxBackoff); err != nil {
+			return nil, fmt.Errorf("max_backoff: %w", err)
+		}
+	}
+
+	if r.backoff < 0 || r.maxBackoff < r.backoff {
+		return nil, fmt.Errorf("backoff must be between 0 and max_backoff")
+	}
+
+	methods := defaultRetryMethods
+	if conf.Methods != nil {
+		methods = conf.Methods
+	}
+	for _, method := range methods {
+		r.methods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	onErrors := defaultRetryOnErrors
+	if conf.OnErrors != nil {
+		onErrors = conf.OnErrors
+	}
+	for _, errType := range onErrors {
+		if _, ok := backendErrorTypes[errType]; !ok {
+			return nil, fmt.Errorf("on_errors: unsupported error type %q", errType)
+		}
+		r.onErrors[errType] = struct{}{}
+	}
+
+	onStatus := defaultRetryOnStatus
+	if conf.OnStatus != nil {
+		onStatus = conf.OnStatus
+	}
+	for _, status := range onStatus {
+		r.onStatus[status] = struct{}{}
+	}
+
+	return r, nil
+}
+
+// Do calls the given round-trip function until it results in a non-retryable
+// response or error, or the configured attempts are exhausted. Request bodies
+// are replayed via GetBody; requests with a non-replayable body are not retried.
+func (r *Retry) Do(req *http.Request, roundTrip func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	attempts, _ := req.Context().Value(request.BackendAttempts).(*uint)
+	if attempts == nil {
+		attempts = new(uint)
+	}
+
+	if !r.retryable(req) {
+		*attempts = 1
+		return roundTrip(req)
+	}
+
+	var (
+		beresp *http.Response
+		err    error
+	)
+
+	origReq := req.Clone(req.Context())
+
+	for attempt := uint(1); ; attempt++ {
+		outreq := origReq.Clone(origReq.Context())
+		if req.GetBody != nil {
+			if outreq.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		beresp, err = roundTrip(outreq)
+		*attempts = attempt
+
+		// provide the latest upstream target for logging purposes
+		*req.URL = *outreq.URL
+		req.Host = outreq.Host
+		req.Header = outreq.Header
+
+		if attempt >= r.attempts || !r.shouldRetry(beresp, err) {
+			return beresp, err
+		}
+
+		if beresp != nil && beresp.Body != nil {
+			// drain a small body to allow connection reuse
+			_, _ = io.Copy(io.Discard, io.LimitReader(beresp.Body, retryMaxDrain))
+			_ = beresp.Body.Close()
+		}
+
+		timer := time.NewTimer(r.delay(attempt))
+		select {
+		case <-req.Context().Done():
+			// the body of the previous response has already been closed
+			timer.Stop()
+			return nil, errors.Backend.Message("retry cancelled").With(req.Context().Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *Retry) retryable(req *http.Request) bool {
+	if _, ok := r.methods[req.Method]; !ok {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return true
+}
+
+func (r *Retry) shouldRetry(beresp *http.Response, err error) bool {
+	if err != nil {
+		if gerr, ok := err.(*errors.Error); ok {
+			if kinds := gerr.Kinds(); len(kinds) > 0 {
+				_, retry := r.onErrors[kinds[0]]
+				return retry
+			}
+		}
+		return false
+	}
+
+	if beresp == nil {
+		return false
+	}
+
+	_, retry := r.onStatus[beresp.StatusCode]
+	return retry
+}
+
+// delay returns the exponential backoff for the given attempt with a jitter of up to 50%.
+func (r *Retry) delay(attempt uint) time.Duration {
+	d := r.backoff
+	for i := uint(1); i < attempt && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > r.maxBackoff {
+		d = r.maxBackoff
+	}
+
+	if half := int64(d / 2); half > 0 {
+		d = time.Duration(half + rand.Int63n(half+1))
+	}
+
+	return d
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"context"
+	goerrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestRetry_Config(t *testing.T) {
+	zero := uint(0)
+
+	for _, tc := range []struct {
+		conf   *config.Retry
+		expErr string
+	}{
+		{&config.Retry{Attempts: &zero}, "attempts must not be 0 (zero)"},
+		{&config.Retry{Backoff: "1x"}, `backoff: time: unknown unit "x" in duration "1x"`},
+		{&config.Retry{Backoff: "3s"}, "backoff must be between 0 and max_backoff"},
+		{&config.Retry{OnErrors: []string{"backend_openapi_validation"}}, `on_errors: unsupported error type "backend_openapi_validation"`},
+	} {
+		_, err := NewRetry(tc.conf)
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("want error %q, got: %v", tc.expErr, err)
+		}
+	}
+}
+
+func TestRetry_delay(t *testing.T) {
+	r, err := NewRetry(&config.Retry{Backoff: "100ms", MaxBackoff: "300ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt, exp := range map[uint]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 300 * time.Millisecond,
+		9: 300 * time.Millisecond,
+	} {
+		for i := 0; i < 10; i++ {
+			if d := r.delay(attempt); d < exp/2 || d > exp {
+				t.Errorf("attempt %d: want delay between %s and %s, got %s", attempt, exp/2, exp, d)
+			}
+		}
+	}
+}
+
+func TestRetry_shouldRetry(t *testing.T) {
+	r, err := NewRetry(&config.Retry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		beresp *http.Response
+		err    error
+		exp    bool
+	}{
+		{"ok", &http.Response{StatusCode: http.StatusOK}, nil, false},
+		{"503", &http.Response{StatusCode: http.StatusServiceUnavailable}, nil, true},
+		{"connection error", nil, errors.Backend.Message("connecting failed"), true},
+		{"unhealthy", &http.Response{}, errors.BackendUnhealthy, true},
+		{"timeout", nil, errors.BackendTimeout, false},
+		{"rate limit", nil, errors.BetaBackendRateLimitExceeded, false},
+	} {
+		if retry := r.shouldRetry(tc.beresp, tc.err); retry != tc.exp {
+			t.Errorf("%s: want %v, got %v", tc.name, tc.exp, retry)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	if r.retryable(req) {
+		t.Error("expected POST request not to be retryable by default")
+	}
+}
+
+func TestRetry_DoCancelled(t *testing.T) {
+	r, err := NewRetry(&config.Retry{Backoff: "1s", MaxBackoff: "1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	beresp, err := r.Do(req, func(*http.Request) (*http.Response, error) {
+		cancel()
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("busy"))}, nil
+	})
+	if beresp != nil {
+		t.Errorf("expected no response with a closed body, got status %d", beresp.StatusCode)
+	}
+	if !errors.Equals(err, errors.Backend) || !goerrors.Is(err, context.Canceled) {
+		t.Errorf("expected backend error caused by the cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/logging"
+)
+
+const (
+	EventStreamContentType = "text/event-stream"
+
+	maxEventIDLength = 1024
+)
+
+// eventStream replaces the total backend timeout with an idle timeout for
+// Server-Sent Events responses.
+type eventStream struct {
+	cancel      context.CancelFunc
+	idleTimeout time.Duration
+	log         *logging.EventStream
+	started     chan struct{}
+}
+
+// newEventStream returns nil if the sse mode is not enabled for the given request.
+// Otherwise, the request context gets replaced with a cancelable one.
+func newEventStream(req *http.Request) *eventStream {
+	idleTimeout, ok := req.Context().Value(request.SSEIdleTimeout).(time.Duration)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	*req = *req.WithContext(ctx)
+
+	stream := &eventStream{
+		cancel:      cancel,
+		idleTimeout: idleTimeout,
+		started:     make(chan struct{}),
+	}
+	stream.log, _ = ctx.Value(request.BackendEventStream).(*logging.EventStream)
+	return stream
+}
+
+// Started returns a channel which is closed after an event stream response has been received.
+func (s *eventStream) Started() <-chan struct{} {
+	if s == nil {
+		return nil
+	}
+	return s.started
+}
+
+// start wraps the body of an event stream response with the idle timeout.
+func (s *eventStream) start(res *http.Response) {
+	if res == nil || res.Body == nil || !IsEventStream(res) {
+		return
+	}
+
+	close(s.started)
+
+	if s.log != nil {
+		s.log.Start()
+	}
+
+	res.Body = newEventStreamBody(res.Body, s.idleTimeout, s.cancel, s.log)
+}
+
+// IsEventStream determines if the given response is a Server-Sent Events one.
+func IsEventStream(res *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	return mediaType == EventStreamContentType
+}
+
+// eventStreamBody closes the event stream if no data has been received within
+// the idle timeout. The client gets a regular end of the stream to reconnect.
+type eventStreamBody struct {
+	io.ReadCloser
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	idle      *time.Timer
+	timedOut  atomic.Bool
+	timeout   time.Duration
+
+	log    *logging.EventStream
+	parser eventParser
+}
+
+func newEventStreamBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc, log *logging.EventStream) *eventStreamBody {
+	b := &eventStreamBody{
+		ReadCloser: body,
+		cancel:     cancel,
+		log:        log,
+		timeout:    timeout,
+	}
+
+	if timeout > 0 {
+		b.idle = time.AfterFunc(timeout, func() {
+			b.timedOut.Store(true)
+			cancel()
+		})
+	}
+	return b
+}
+
+func (b *eventStreamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if b.idle != nil {
+			b.idle.Reset(b.timeout)
+		}
+		b.parser.parse(p[:n])
+	}
+
+	if err != nil && b.timedOut.Load() {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (b *eventStreamBody) Close() error {
+	b.closeOnce.Do(func() {
+		if b.idle != nil {
+			b.idle.Stop()
+		}
+		if b.log != nil {
+			b.log.Add(b.parser.events, b.parser.lastEventID)
+		}
+		b.cancel()
+	})
+	return b.ReadCloser.Close()
+}
+
+// eventParser counts the dispatched events and keeps the last event id,
+// see https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+type eventParser struct {
+	events      int64
+	lastEventID string
+
+	fields int    // non-comment lines of the current event
+	line   []byte // current line up to maxEventIDLength
+	length int    // length of the current line
+	cr     bool   // previous byte was a carriage return
+}
+
+func (e *eventParser) parse(p []byte) {
+	for _, c := range p {
+		if c == '\n' && e.cr {
+			e.cr = false
+			continue
+		}
+		e.cr = c == '\r'
+
+		if c != '\n' && c != '\r' {
+			if len(e.line) < maxEventIDLength {
+				e.line = append(e.line, c)
+			}
+			e.length++
+			continue
+		}
+
+		e.endOfLine()
+	}
+}
+
+func (e *eventParser) endOfLine() {
+	defer func() {
+		e.line = e.line[:0]
+		e.length = 0
+	}()
+
+	if e.length == 0 { // blank line dispatches the event
+		if e.fields > 0 {
+			e.events++
+		}
+		e.fields = 0
+		return
+	}
+
+	if e.line[0] == ':' { // comment
+		return
+	}
+
+	e.fields++
+
+	const idField = "id:"
+	if len(e.line) >= len(idField) && string(e.line[:len(idField)]) == idField && e.length <= maxEventIDLength {
+		id := e.line[len(idField):]
+		if len(id) > 0 && id[0] == ' ' {
+			id = id[1:]
+		}
+		e.lastEventID = string(id)
+	}
+}
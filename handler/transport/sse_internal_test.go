@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventParser(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		chunks   []string
+		expCount int64
+		expID    string
+	}{
+		{"single event", []string{"data: a\n\n"}, 1, ""},
+		{"comments only", []string{": ping\n\n: ping\n\n"}, 0, ""},
+		{"split chunks", []string{"id: 1\nda", "ta: a\n", "\nid: 2\ndata: b\n\n"}, 2, "2"},
+		{"crlf", []string{"id:3\r\ndata: c\r\n\r\n"}, 1, "3"},
+		{"cr split", []string{"data: d\r", "\n\r", "\n"}, 1, ""},
+		{"incomplete", []string{"id: 4\ndata: e\n"}, 0, "4"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			p := &eventParser{}
+			for _, c := range tc.chunks {
+				p.parse([]byte(c))
+			}
+
+			if p.events != tc.expCount {
+				st.Errorf("want %d events, got %d", tc.expCount, p.events)
+			}
+			if p.lastEventID != tc.expID {
+				st.Errorf("want last event id %q, got %q", tc.expID, p.lastEventID)
+			}
+		})
+	}
+}
+
+func TestEventStreamBody_IdleTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pr, pw := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		_ = pw.CloseWithError(ctx.Err())
+	}()
+
+	body := newEventStreamBody(pr, time.Millisecond*50, cancel, nil)
+	go func() {
+		_, _ = pw.Write([]byte("data: a\n\n"))
+	}()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("expected a regular end of the stream, got: %v", err)
+	}
+
+	if !strings.HasSuffix(string(b), "data: a\n\n") {
+		t.Errorf("unexpected body: %q", string(b))
+	}
+
+	if body.parser.events != 1 {
+		t.Errorf("want 1 event, got %d", body.parser.events)
+	}
+}
@@ -2,10 +2,14 @@ package transport
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"sync"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/coupergateway/couper/cache"
@@ -24,18 +28,18 @@ var (
 type TokenRequest struct {
 	config      *config.TokenRequest
 	mu          sync.Mutex
-	memStore    *cache.MemoryStore
+	memStore    cache.Store
 	reqProducer producer.Roundtrip
 	storageKey  string
 }
 
-func NewTokenRequest(conf *config.TokenRequest, memStore *cache.MemoryStore, reqProducer producer.Roundtrip) (RequestAuthorizer, error) {
+func NewTokenRequest(conf *config.TokenRequest, memStore cache.Store, reqProducer producer.Roundtrip) (RequestAuthorizer, error) {
 	tr := &TokenRequest{
 		config:      conf,
 		memStore:    memStore,
 		reqProducer: reqProducer,
 	}
-	tr.storageKey = fmt.Sprintf("TokenRequest-%p", tr)
+	tr.storageKey = newStorageKey("TokenRequest-", conf.Remain, conf.Name, conf.URL)
 	return tr, nil
 }
 
@@ -130,3 +134,16 @@ func (t *TokenRequest) value() (string, string) {
 	token := t.readToken()
 	return t.config.Name, token
 }
+
+// newStorageKey returns a storage key which is the same for instances
+// using the same configuration, so tokens can be shared via the cache store.
+func newStorageKey(prefix string, body hcl.Body, parts ...string) string {
+	h := sha256.New()
+	if b, ok := body.(*hclsyntax.Body); ok {
+		_, _ = h.Write([]byte(b.SrcRange.String()))
+	}
+	for _, part := range parts {
+		_, _ = h.Write([]byte("|" + part))
+	}
+	return prefix + hex.EncodeToString(h.Sum(nil))
+}
@@ -3,11 +3,14 @@ package transport
 import (
 	"context"
 	"crypto/tls"
+	goerrors "errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,6 +23,7 @@ import (
 	coupertls "github.com/coupergateway/couper/internal/tls"
 	"github.com/coupergateway/couper/telemetry"
 	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/http2"
 )
 
 // Config represents the transport <Config> object.
@@ -52,8 +56,9 @@ type Config struct {
 	Scheme   string
 }
 
-// NewTransport creates a new <*http.Transport> object by the given <*Config>.
-func NewTransport(conf *Config, log *logrus.Entry) *http.Transport {
+// NewTransport creates a new <http.RoundTripper> object by the given <*Config>. HTTP/2 origins
+// without TLS are connected via h2c (prior knowledge) with a related <*http2.Transport>.
+func NewTransport(conf *Config, log *logrus.Entry) http.RoundTripper {
 	tlsConf := coupertls.DefaultTLSConfig()
 	if len(conf.Certificate) > 0 {
 		tlsConf.RootCAs.AppendCertsFromPEM(conf.Certificate)
@@ -104,36 +109,58 @@ func NewTransport(conf *Config, log *logrus.Entry) *http.Transport {
 
 	logEntry := log.WithField("type", "couper_connection")
 
-	transport := &http.Transport{
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			address := addr
-			if proxyFunc == nil {
-				address = conf.Origin
-			} // Otherwise, proxy connect will use this dial method and addr could be a proxy one.
-
-			stx, span := telemetry.NewSpanFromContext(ctx, "connect", trace.WithAttributes(attribute.String("couper.address", addr)))
-			defer span.End()
-
-			connectTimeout, _ := ctx.Value(request.ConnectTimeout).(time.Duration)
-			if connectTimeout > 0 {
-				dtx, cancel := context.WithDeadline(stx, time.Now().Add(connectTimeout))
-				stx = dtx
-				defer cancel()
-			}
+	dialContext := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		address := addr
+		if proxyFunc == nil {
+			address = conf.Origin
+		} // Otherwise, proxy connect will use this dial method and addr could be a proxy one.
+
+		stx, span := telemetry.NewSpanFromContext(ctx, "connect", trace.WithAttributes(attribute.String("couper.address", addr)))
+		defer span.End()
+
+		connectTimeout, _ := ctx.Value(request.ConnectTimeout).(time.Duration)
+		if connectTimeout > 0 {
+			dtx, cancel := context.WithDeadline(stx, time.Now().Add(connectTimeout))
+			stx = dtx
+			defer cancel()
+		}
 
-			conn, cerr := d.DialContext(stx, network, address)
-			if cerr != nil {
-				host, port, _ := net.SplitHostPort(conf.Origin)
-				if port != "80" && port != "443" {
-					host = conf.Origin
-				}
-				if os.IsTimeout(cerr) || cerr == context.DeadlineExceeded {
-					return nil, fmt.Errorf("connecting to %s '%s' failed: i/o timeout", conf.BackendName, host)
-				}
-				return nil, fmt.Errorf("connecting to %s '%s' failed: %w", conf.BackendName, conf.Origin, cerr)
+		conn, cerr := d.DialContext(stx, network, address)
+		if cerr != nil {
+			host, port, _ := net.SplitHostPort(conf.Origin)
+			if port != "80" && port != "443" {
+				host = conf.Origin
 			}
-			return NewOriginConn(stx, conn, conf, logEntry), nil
-		},
+			if os.IsTimeout(cerr) || cerr == context.DeadlineExceeded {
+				return nil, fmt.Errorf("connecting to %s '%s' failed: i/o timeout", conf.BackendName, host)
+			}
+			return nil, fmt.Errorf("connecting to %s '%s' failed: %w", conf.BackendName, conf.Origin, cerr)
+		}
+		return NewOriginConn(stx, conn, conf, logEntry), nil
+	}
+
+	if conf.HTTP2 && conf.Scheme == "http" {
+		h2cTransport := &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialContext(ctx, network, addr)
+			},
+			DisableCompression: true,
+		}
+
+		if conf.DisableConnectionReuse {
+			return &h2cSingleUseTransport{dial: dialContext, transport: h2cTransport}
+		}
+		if conf.MaxConnections > 0 {
+			// Requests wait for a free stream of a pooled connection instead of dialing another one.
+			h2cTransport.StrictMaxConcurrentStreams = true
+			return newH2CPoolTransport(dialContext, conf.MaxConnections, h2cTransport)
+		}
+		return h2cTransport
+	}
+
+	transport := &http.Transport{
+		DialContext:        dialContext,
 		DisableCompression: true,
 		DisableKeepAlives:  conf.DisableConnectionReuse,
 		ForceAttemptHTTP2:  conf.HTTP2,
@@ -146,6 +173,167 @@ func NewTransport(conf *Config, log *logrus.Entry) *http.Transport {
 	return transport
 }
 
+// h2cSingleUseTransport dials a new h2c connection per request which
+// gets closed with the response body.
+type h2cSingleUseTransport struct {
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	transport *http2.Transport
+}
+
+func (t *h2cSingleUseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cc, err := dialH2C(req, t.dial, t.transport)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cc.RoundTrip(req)
+	if err != nil {
+		_ = cc.Close()
+		return nil, err
+	}
+
+	res.Body = &clientConnBody{ReadCloser: res.Body, cc: cc}
+	return res, nil
+}
+
+type clientConnBody struct {
+	io.ReadCloser
+	cc *http2.ClientConn
+}
+
+func (b *clientConnBody) Close() error {
+	err := b.ReadCloser.Close()
+	_ = b.cc.Close()
+	return err
+}
+
+// h2cPoolTransport multiplexes requests over at most max h2c connections. Another
+// connection is dialed only if all streams of the established ones are in use.
+type h2cPoolTransport struct {
+	conns     []*http2.ClientConn
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+	dialed    *sync.Cond
+	dialing   int
+	max       int
+	mu        sync.Mutex
+	transport *http2.Transport
+}
+
+func newH2CPoolTransport(dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	max int, transport *http2.Transport) *h2cPoolTransport {
+	t := &h2cPoolTransport{dial: dial, max: max, transport: transport}
+	t.dialed = sync.NewCond(&t.mu)
+	return t
+}
+
+func (t *h2cPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		cc, err := t.clientConn(req)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := cc.RoundTrip(req)
+		// Streams exceeding the limit of the origin are refused before its settings are known.
+		// Like the <http2.Transport> the request is sent again since it has not been processed.
+		var streamErr http2.StreamError
+		if err == nil || attempt >= 6 || !goerrors.As(err, &streamErr) || streamErr.Code != http2.ErrCodeRefusedStream {
+			return res, err
+		}
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return res, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, berr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// clientConn returns a pooled connection with a free stream, a new connection if the
+// limit allows it, or the least busy connection whose round trip waits for a free stream.
+func (t *h2cPoolTransport) clientConn(req *http.Request) (*http2.ClientConn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for {
+		var free, leastBusy *http2.ClientConn
+		minStreams := -1
+		usable := t.conns[:0]
+		for _, cc := range t.conns {
+			if !cc.CanTakeNewRequest() {
+				continue // closed or going away
+			}
+			usable = append(usable, cc)
+			if free != nil {
+				continue
+			}
+
+			state := cc.State()
+			streams := state.StreamsActive + state.StreamsReserved + state.StreamsPending
+			// without the settings of the origin only an idle connection is considered free
+			if streams == 0 || uint32(streams) < state.MaxConcurrentStreams {
+				free = cc
+			} else if minStreams < 0 || streams < minStreams {
+				minStreams = streams
+				leastBusy = cc
+			}
+		}
+		t.conns = usable
+
+		if free != nil {
+			return free, nil
+		}
+		if len(t.conns)+t.dialing < t.max {
+			break
+		}
+		if leastBusy != nil {
+			return leastBusy, nil
+		}
+		t.dialed.Wait()
+	}
+
+	t.dialing++
+	t.mu.Unlock()
+
+	cc, err := dialH2C(req, t.dial, t.transport)
+
+	t.mu.Lock()
+	t.dialing--
+	if err == nil {
+		t.conns = append(t.conns, cc)
+	}
+	t.dialed.Broadcast()
+
+	return cc, err
+}
+
+// dialH2C dials a new h2c connection to the origin of the given request.
+func dialH2C(req *http.Request, dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	transport *http2.Transport) (*http2.ClientConn, error) {
+	port := req.URL.Port()
+	if port == "" {
+		port = "80"
+	}
+
+	conn, err := dial(req.Context(), "tcp", net.JoinHostPort(req.URL.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := transport.NewClientConn(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return cc, nil
+}
+
 func (c *Config) WithTarget(scheme, origin, hostname, proxyURL string) *Config {
 	const defaultScheme = "http"
 	conf := *c
@@ -2,10 +2,19 @@ package transport
 
 import (
 	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func Test_parseDuration(t *testing.T) {
@@ -18,3 +27,88 @@ func Test_parseDuration(t *testing.T) {
 		t.Errorf("Unexpected duration given: %#v", target)
 	}
 }
+
+func TestTransport_H2CConnections(t *testing.T) {
+	var conns int32
+	origin := httptest.NewUnstartedServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = rw.Write([]byte(r.Proto))
+	}), &http2.Server{MaxConcurrentStreams: 1}))
+	origin.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	origin.Start()
+	defer origin.Close()
+
+	host := strings.TrimPrefix(origin.URL, "http://")
+
+	for _, tc := range []struct {
+		name     string
+		conf     *Config
+		expConns int32
+	}{
+		{"disable_connection_reuse", &Config{DisableConnectionReuse: true}, 6},
+		{"max_connections", &Config{MaxConnections: 1}, 1},
+		{"max_connections: 2", &Config{MaxConnections: 2}, 2},
+	} {
+		atomic.StoreInt32(&conns, 0)
+
+		tc.conf.BackendName = "h2c"
+		tc.conf.HTTP2 = true
+		rt := NewTransport(tc.conf.WithTarget("http", host, host, ""), logrus.NewEntry(logrus.New()))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				req, _ := http.NewRequest(http.MethodGet, origin.URL, nil)
+				res, err := rt.RoundTrip(req)
+				if err != nil {
+					t.Errorf("%s: %v", tc.name, err)
+					return
+				}
+
+				b, _ := io.ReadAll(res.Body)
+				_ = res.Body.Close()
+				if string(b) != "HTTP/2.0" {
+					t.Errorf("%s: want HTTP/2.0, got %q", tc.name, string(b))
+				}
+			}()
+		}
+		wg.Wait()
+
+		if n := atomic.LoadInt32(&conns); n != tc.expConns {
+			t.Errorf("%s: want %d connection(s), got %d", tc.name, tc.expConns, n)
+		}
+	}
+}
+
+func Test_dialH2C(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		expAddr string
+	}{
+		{"http://example.com/", "example.com:80"},
+		{"http://example.com:8080/", "example.com:8080"},
+		{"http://[::1]/", "[::1]:80"},
+		{"http://[::1]:8080/", "[::1]:8080"},
+	} {
+		var addr string
+		dial := func(_ context.Context, _, a string) (net.Conn, error) {
+			addr = a
+			return nil, context.Canceled
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+		if _, err := dialH2C(req, dial, &http2.Transport{}); err == nil {
+			t.Errorf("%s: expected dial error", tc.url)
+		}
+		if addr != tc.expAddr {
+			t.Errorf("%s: want address %q, got %q", tc.url, tc.expAddr, addr)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package grpc contains helpers to detect and translate gRPC and gRPC-Web messages.
+package grpc
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentType        = "application/grpc"
+	ContentTypeWeb     = "application/grpc-web"
+	ContentTypeWebText = "application/grpc-web-text"
+
+	MessageHeader = "Grpc-Message"
+	StatusHeader  = "Grpc-Status"
+)
+
+// Status codes, see https://grpc.github.io/grpc/core/md_doc_statuscodes.html.
+const (
+	StatusOK                = 0
+	StatusUnknown           = 2
+	StatusInvalidArgument   = 3
+	StatusDeadlineExceeded  = 4
+	StatusPermissionDenied  = 7
+	StatusResourceExhausted = 8
+	StatusUnimplemented     = 12
+	StatusInternal          = 13
+	StatusUnavailable       = 14
+	StatusUnauthenticated   = 16
+)
+
+// IsGRPC determines if the given content-type is a native gRPC one,
+// e.g. "application/grpc" or "application/grpc+proto".
+func IsGRPC(contentType string) bool {
+	return hasType(contentType, ContentType)
+}
+
+// IsGRPCWeb determines if the given content-type is a gRPC-Web one
+// including the base64 encoded "application/grpc-web-text" variant.
+func IsGRPCWeb(contentType string) bool {
+	return hasType(contentType, ContentTypeWeb) || IsGRPCWebText(contentType)
+}
+
+// IsGRPCWebText determines if the given content-type is a base64 encoded gRPC-Web one.
+func IsGRPCWebText(contentType string) bool {
+	return hasType(contentType, ContentTypeWebText)
+}
+
+// Status parses the given grpc-status value.
+func Status(value string) (int, bool) {
+	code, err := strconv.Atoi(value)
+	return code, err == nil
+}
+
+// WebToGRPC returns the native gRPC content-type for the given gRPC-Web one.
+func WebToGRPC(contentType string) string {
+	return ContentType + formatSuffix(contentType)
+}
+
+// GRPCToWeb returns the gRPC-Web content-type for the given native gRPC one.
+func GRPCToWeb(contentType string, text bool) string {
+	if text {
+		return ContentTypeWebText + formatSuffix(contentType)
+	}
+	return ContentTypeWeb + formatSuffix(contentType)
+}
+
+// formatSuffix returns the optional message format suffix, e.g. "+proto".
+func formatSuffix(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if idx := strings.Index(mediaType, "+"); idx > 0 {
+		return mediaType[idx:]
+	}
+	return ""
+}
+
+func hasType(contentType, baseType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	// optional message format suffix, e.g. +proto or +json
+	return mediaType == baseType || strings.HasPrefix(mediaType, baseType+"+")
+}
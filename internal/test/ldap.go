@@ -0,0 +1,227 @@
+package test
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPEntry represents a directory entry of the LDAPServer.
+type LDAPEntry struct {
+	Attributes map[string][]string
+	DN         string
+	Password   string
+}
+
+// LDAPServer is a minimal in-process LDAP server supporting simple binds,
+// searches with equality, presence and "and" filters, StartTLS and LDAPS.
+type LDAPServer struct {
+	binds    int32
+	entries  []LDAPEntry
+	listener net.Listener
+	tls      *tls.Config
+	scheme   string
+	wg       sync.WaitGroup
+}
+
+// NewLDAPServer starts an "ldap://" server. StartTLS is supported if a
+// TLS configuration is given.
+func NewLDAPServer(tlsConf *tls.Config, entries ...LDAPEntry) *LDAPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	return newLDAPServer("ldap", ln, tlsConf, entries)
+}
+
+// NewLDAPSServer starts an "ldaps://" server.
+func NewLDAPSServer(tlsConf *tls.Config, entries ...LDAPEntry) *LDAPServer {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsConf)
+	if err != nil {
+		panic(err)
+	}
+	return newLDAPServer("ldaps", ln, nil, entries)
+}
+
+func newLDAPServer(scheme string, ln net.Listener, tlsConf *tls.Config, entries []LDAPEntry) *LDAPServer {
+	s := &LDAPServer{
+		entries:  entries,
+		listener: ln,
+		scheme:   scheme,
+		tls:      tlsConf,
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+// URL returns the LDAP URL of the server.
+func (s *LDAPServer) URL() string {
+	return s.scheme + "://" + s.listener.Addr().String()
+}
+
+// Binds returns the number of bind requests received so far.
+func (s *LDAPServer) Binds() int {
+	return int(atomic.LoadInt32(&s.binds))
+}
+
+func (s *LDAPServer) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *LDAPServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var boundDN string
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			atomic.AddInt32(&s.binds, 1)
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+
+			resultCode := uint16(ldap.LDAPResultInvalidCredentials)
+			if entry := s.entry(dn); entry != nil && password != "" && entry.Password == password {
+				resultCode = ldap.LDAPResultSuccess
+				boundDN = dn
+			} else {
+				boundDN = ""
+			}
+			s.respond(conn, messageID, ldap.ApplicationBindResponse, resultCode)
+		case ldap.ApplicationSearchRequest:
+			if boundDN == "" {
+				s.respond(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights)
+				continue
+			}
+
+			baseDN := strings.ToLower(op.Children[0].Value.(string))
+			scope := op.Children[1].Value.(int64)
+			filter := op.Children[6]
+			var attributes []string
+			for _, a := range op.Children[7].Children {
+				attributes = append(attributes, a.Value.(string))
+			}
+
+			for _, entry := range s.entries {
+				dn := strings.ToLower(entry.DN)
+				if scope == ldap.ScopeBaseObject && dn != baseDN ||
+					scope != ldap.ScopeBaseObject && !strings.HasSuffix(dn, baseDN) ||
+					!matchLDAPFilter(filter, entry) {
+					continue
+				}
+				s.write(conn, messageID, searchResultEntry(entry, attributes))
+			}
+			s.respond(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)
+		case ldap.ApplicationExtendedRequest:
+			if s.tls == nil || op.Children[0].Data.String() != "1.3.6.1.4.1.1466.20037" {
+				s.respond(conn, messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError)
+				continue
+			}
+			s.respond(conn, messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess)
+
+			tlsConn := tls.Server(conn, s.tls)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		default: // unbind or unsupported
+			return
+		}
+	}
+}
+
+func (s *LDAPServer) entry(dn string) *LDAPEntry {
+	for i, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+func (s *LDAPServer) respond(conn net.Conn, messageID int64, tag ber.Tag, resultCode uint16) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "resultCode"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, ldap.LDAPResultCodeMap[resultCode], "diagnosticMessage"))
+	s.write(conn, messageID, response)
+}
+
+func (s *LDAPServer) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	envelope.AppendChild(op)
+	_, _ = conn.Write(envelope.Bytes())
+}
+
+func searchResultEntry(entry LDAPEntry, attributes []string) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "objectName"))
+
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for _, name := range attributes {
+		values, exist := entry.Attributes[name]
+		if !exist {
+			continue
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	result.AppendChild(attrs)
+
+	return result
+}
+
+func matchLDAPFilter(filter *ber.Packet, entry LDAPEntry) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, f := range filter.Children {
+			if !matchLDAPFilter(f, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterPresent:
+		attr := filter.Data.String()
+		_, exist := entry.Attributes[attr]
+		return exist || strings.EqualFold(attr, "objectClass")
+	case ldap.FilterEqualityMatch:
+		attr, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for _, v := range entry.Attributes[attr] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
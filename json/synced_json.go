@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/coupergateway/couper/config/reader"
@@ -12,6 +14,9 @@ import (
 	"github.com/coupergateway/couper/eval/buffer"
 )
 
+// FileCheckInterval is the minimum duration between two checks of a file for changes.
+var FileCheckInterval = time.Second
+
 type SyncedJSONUnmarshaller interface {
 	Unmarshal(rawJSON []byte) (interface{}, error)
 }
@@ -32,6 +37,14 @@ type SyncedJSON struct {
 	data        interface{}
 	dataRequest chan chan *dataRequest
 	fileMode    bool
+	// file mode
+	file        string
+	fileContext string
+	fileErr     error
+	lastCheck   time.Time
+	modTime     time.Time
+	mu          sync.RWMutex
+	size        int64
 }
 
 func NewSyncedJSON(
@@ -48,7 +61,8 @@ func NewSyncedJSON(
 	}
 
 	if file != "" {
-		if err := sj.readFile(fileContext, file); err != nil {
+		sj.file, sj.fileContext = file, fileContext
+		if err := sj.readFile(); err != nil {
 			return nil, err
 		}
 		sj.fileMode = true
@@ -109,7 +123,7 @@ func (s *SyncedJSON) sync(ctx context.Context) {
 
 func (s *SyncedJSON) Data() (interface{}, error) {
 	if s.fileMode {
-		return s.data, nil
+		return s.fileData()
 	}
 
 	rCh := make(chan *dataRequest)
@@ -148,11 +162,60 @@ func (s *SyncedJSON) fetch(ctx context.Context) error {
 	return err
 }
 
-func (s *SyncedJSON) readFile(context, path string) error {
-	raw, err := reader.ReadFromFile(context, path)
+// fileData returns the current file data and reloads the file if its
+// modification time or size has changed.
+func (s *SyncedJSON) fileData() (interface{}, error) {
+	s.mu.RLock()
+	if time.Since(s.lastCheck) < FileCheckInterval {
+		defer s.mu.RUnlock()
+		return s.data, s.fileErr
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastCheck) < FileCheckInterval { // concurrent reload
+		return s.data, s.fileErr
+	}
+
+	info, err := os.Stat(s.file)
+	if err != nil {
+		s.data, s.fileErr, s.lastCheck = nil, err, time.Now()
+		return nil, err
+	}
+
+	if s.fileErr == nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		s.lastCheck = time.Now()
+		return s.data, nil
+	}
+
+	if err = s.readFile(); err != nil {
+		// do not keep former data with a broken file
+		s.data, s.fileErr = nil, err
+	}
+	return s.data, s.fileErr
+}
+
+func (s *SyncedJSON) readFile() error {
+	s.lastCheck = time.Now()
+
+	raw, err := reader.ReadFromFile(s.fileContext, s.file)
 	if err != nil {
 		return err
 	}
-	s.data, err = s.unmarshaller.Unmarshal(raw)
-	return err
+
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.unmarshaller.Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+
+	s.data, s.fileErr = data, nil
+	s.modTime, s.size = info.ModTime(), info.Size()
+	return nil
 }
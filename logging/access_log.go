@@ -12,6 +12,7 @@ import (
 
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/utils"
 )
 
 type RoundtripHandlerFunc http.HandlerFunc
@@ -90,10 +91,15 @@ func (log *AccessLog) Do(writer http.ResponseWriter, req *http.Request) {
 		fields["type"] = log.conf.TypeFieldKey
 	}
 
+	rawQuery := req.URL.RawQuery
+	if params, ok := req.Context().Value(request.CredentialQueryParams).([]string); ok {
+		rawQuery = utils.MaskQueryParams(rawQuery, params)
+	}
+
 	path := &url.URL{
 		Path:       req.URL.Path,
 		RawPath:    req.URL.RawPath,
-		RawQuery:   req.URL.RawQuery,
+		RawQuery:   rawQuery,
 		ForceQuery: req.URL.ForceQuery,
 		Fragment:   req.URL.Fragment,
 	}
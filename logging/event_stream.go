@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"io"
+	"sync"
+)
+
+// EventStream holds the log values of a Server-Sent Events response
+// which are known after the stream has been closed.
+type EventStream struct {
+	mu          sync.Mutex
+	events      int64
+	lastEventID string
+	started     bool
+}
+
+// Start marks the backend response as event stream.
+func (e *EventStream) Start() {
+	e.mu.Lock()
+	e.started = true
+	e.mu.Unlock()
+}
+
+// Add counts the given dispatched events and keeps the last event id.
+func (e *EventStream) Add(events int64, lastEventID string) {
+	e.mu.Lock()
+	e.events += events
+	if lastEventID != "" {
+		e.lastEventID = lastEventID
+	}
+	e.mu.Unlock()
+}
+
+func (e *EventStream) isStarted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.started
+}
+
+// Fields returns the related log fields if the response is an event stream.
+func (e *EventStream) Fields() (Fields, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.started {
+		return nil, false
+	}
+
+	fields := Fields{"events": e.events}
+	if e.lastEventID != "" {
+		fields["last_event_id"] = e.lastEventID
+	}
+	return fields, true
+}
+
+// eventStreamLogBody fires the related log entry after the stream has been closed.
+type eventStreamLogBody struct {
+	io.ReadCloser
+	fire func(args ...interface{})
+	once sync.Once
+}
+
+func (e *eventStreamLogBody) Close() error {
+	err := e.ReadCloser.Close()
+	e.once.Do(func() {
+		e.fire()
+	})
+	return err
+}
@@ -32,6 +32,12 @@ func (c *Context) Fire(entry *logrus.Entry) error {
 			if r && b > 0 {
 				response["bytes"] = b
 			}
+
+			if stream, s := entry.Context.Value(request.BackendEventStream).(*logging.EventStream); r && s {
+				if sseFields, started := stream.Fields(); started {
+					response["sse"] = sseFields
+				}
+			}
 		}
 	}
 
@@ -90,10 +90,15 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 	var logError error
 	berespBytes := int64(0)
 	tokenRetries := uint8(0)
+	attempts := uint(0)
 	outctx := context.WithValue(req.Context(), request.LogCustomUpstreamValue, &logValue)
 	outctx = context.WithValue(outctx, request.LogCustomUpstreamError, &logError)
 	outctx = context.WithValue(outctx, request.BackendBytes, &berespBytes)
 	outctx = context.WithValue(outctx, request.TokenRequestRetries, &tokenRetries)
+	outctx = context.WithValue(outctx, request.BackendAttempts, &attempts)
+	if _, sse := req.Context().Value(request.SSEIdleTimeout).(time.Duration); sse {
+		outctx = context.WithValue(outctx, request.BackendEventStream, &EventStream{})
+	}
 	oCtx, openAPIContext := validation.NewWithContext(outctx)
 	outreq := req.WithContext(httptrace.WithClientTrace(oCtx, clientTrace))
 
@@ -138,6 +143,14 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 		}
 	}
 
+	if attempts > 0 {
+		fields["attempts"] = attempts
+	}
+
+	if fallbackFor, ok := outreq.Context().Value(request.BackendFallbackFor).(string); ok {
+		fields["fallback_for"] = fallbackFor
+	}
+
 	fields["status"] = 0
 	if beresp != nil {
 		fields["status"] = beresp.StatusCode
@@ -150,6 +163,11 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 			"headers": filterHeader(u.config.ResponseHeaders, beresp.Header),
 			"status":  beresp.StatusCode,
 		}
+		if beresp.Request != nil {
+			if cacheStatus, ok := beresp.Request.Context().Value(request.ResponseCacheStatus).(string); ok {
+				responseFields["cache_status"] = cacheStatus
+			}
+		}
 		fields["response"] = responseFields
 	}
 
@@ -211,6 +229,9 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 	} else {
 		if stacked {
 			stack.Push(entry).Level(logrus.InfoLevel)
+		} else if stream, ok := outreq.Context().Value(request.BackendEventStream).(*EventStream); ok && stream.isStarted() && beresp.Body != nil {
+			// the event counts are known after the stream has been closed
+			beresp.Body = &eventStreamLogBody{ReadCloser: beresp.Body, fire: entry.Info}
 		} else {
 			entry.Info()
 		}
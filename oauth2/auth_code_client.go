@@ -1,6 +1,7 @@
 package oauth2
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -21,6 +22,8 @@ import (
 type AuthCodeFlowClient interface {
 	// ExchangeCodeAndGetTokenResponse exchanges the authorization code and retrieves the response from the token endpoint.
 	ExchangeCodeAndGetTokenResponse(req *http.Request, callbackURL *url.URL) (map[string]interface{}, error)
+	// RefreshTokenResponse requests a new access token with the given refresh token.
+	RefreshTokenResponse(ctx context.Context, refreshToken string) (map[string]interface{}, error)
 }
 
 var (
@@ -184,7 +184,15 @@ func (c *Client) newTokenRequest(ctx context.Context, formParams url.Values) (*h
 		return nil, err
 	}
 
-	outreq, err := http.NewRequest(http.MethodPost, tokenURL, nil)
+	formParams.Set("grant_type", c.grantType)
+
+	return c.newAuthenticatedRequest(ctx, tokenURL, "oauth2", formParams)
+}
+
+// newAuthenticatedRequest creates a form POST request to the given authorization server endpoint
+// including the client authentication.
+func (c *Client) newAuthenticatedRequest(ctx context.Context, endpoint, name string, formParams url.Values) (*http.Request, error) {
+	outreq, err := http.NewRequest(http.MethodPost, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -192,14 +200,12 @@ func (c *Client) newTokenRequest(ctx context.Context, formParams url.Values) (*h
 	outreq.Header.Set("Accept", "application/json")
 	outreq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	formParams.Set("grant_type", c.grantType)
-
 	err = c.authenticateClient(&formParams, outreq)
 	if err != nil {
 		return nil, err
 	}
 
-	outCtx := context.WithValue(ctx, request.TokenRequest, "oauth2")
+	outCtx := context.WithValue(ctx, request.TokenRequest, name)
 
 	eval.SetBody(outreq, []byte(formParams.Encode()))
 
@@ -256,6 +262,38 @@ func (c *Client) GetTokenResponse(ctx context.Context, formParams url.Values) (m
 		return nil, "", err
 	}
 
+	return c.getTokenResponse(tokenReq)
+}
+
+// RefreshTokenResponse requests a new access token with the given refresh token, see RFC 6749, section 6.
+func (c *Client) RefreshTokenResponse(ctx context.Context, refreshToken string) (map[string]interface{}, error) {
+	tokenURL, err := c.asConfig.GetTokenEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	formParams := url.Values{}
+	formParams.Set("grant_type", "refresh_token")
+	formParams.Set("refresh_token", refreshToken)
+
+	tokenReq, err := c.newAuthenticatedRequest(ctx, tokenURL, "oauth2", formParams)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResponseData, accessToken, err := c.getTokenResponse(tokenReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessToken == "" {
+		return nil, fmt.Errorf("missing access token")
+	}
+
+	return tokenResponseData, nil
+}
+
+func (c *Client) getTokenResponse(tokenReq *http.Request) (map[string]interface{}, string, error) {
 	tokenResponse, statusCode, err := c.requestToken(tokenReq)
 	if err != nil {
 		return nil, "", err
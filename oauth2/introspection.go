@@ -0,0 +1,54 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/coupergateway/couper/config"
+)
+
+// IntrospectionClient represents an OAuth2 client for token introspection, see RFC 7662.
+type IntrospectionClient struct {
+	*Client
+	endpoint string
+}
+
+// NewIntrospectionClient creates a new OAuth2 client for token introspection.
+func NewIntrospectionClient(evalCtx *hcl.EvalContext, conf *config.Introspection, backend http.RoundTripper) (*IntrospectionClient, error) {
+	client, err := NewClient(evalCtx, "", conf, conf, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IntrospectionClient{
+		Client:   client,
+		endpoint: conf.IntrospectionEndpoint,
+	}, nil
+}
+
+// Introspect requests the introspection endpoint for the given token and returns the response body.
+func (c *IntrospectionClient) Introspect(ctx context.Context, token string) ([]byte, error) {
+	formParams := url.Values{}
+	formParams.Set("token", token)
+	formParams.Set("token_type_hint", "access_token")
+
+	req, err := c.newAuthenticatedRequest(ctx, c.endpoint, "introspection", formParams)
+	if err != nil {
+		return nil, err
+	}
+
+	body, statusCode, err := c.requestToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", statusCode)
+	}
+
+	return body, nil
+}
@@ -21,6 +21,7 @@ import (
 type OpenidConfiguration struct {
 	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	EndSessionEndpoint            string   `json:"end_session_endpoint"`
 	Issuer                        string   `json:"issuer"`
 	JwksURI                       string   `json:"jwks_uri"`
 	TokenEndpoint                 string   `json:"token_endpoint"`
@@ -33,6 +34,7 @@ var (
 	_ config.OAuth2AS            = &Config{}
 	_ config.OAuth2Authorization = &Config{}
 	_ config.OAuth2AcClient      = &Config{}
+	_ config.OIDCLogout          = &Config{}
 
 	defaultTTL = time.Hour
 )
@@ -113,6 +115,15 @@ func (c *Config) GetAuthorizationEndpoint() (string, error) {
 	return openidConfigurationData.AuthorizationEndpoint, nil
 }
 
+func (c *Config) GetEndSessionEndpoint() (string, error) {
+	openidConfigurationData, err := c.Data()
+	if err != nil {
+		return "", err
+	}
+
+	return openidConfigurationData.EndSessionEndpoint, nil
+}
+
 func (c *Config) GetIssuer() (string, error) {
 	openidConfigurationData, err := c.Data()
 	if err != nil {
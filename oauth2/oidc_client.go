@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,8 +18,17 @@ import (
 	"github.com/coupergateway/couper/oauth2/oidc"
 )
 
+// BackChannelLogoutEvent is the required member of the events claim of logout tokens.
+const BackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// LogoutTokenValidator validates logout tokens of the OpenID Connect back-channel logout.
+type LogoutTokenValidator interface {
+	ValidateLogoutToken(logoutToken string) (map[string]interface{}, error)
+}
+
 var (
-	_ AuthCodeFlowClient = &OidcClient{}
+	_ AuthCodeFlowClient   = &OidcClient{}
+	_ LogoutTokenValidator = &OidcClient{}
 )
 
 // OidcClient represents an OpenID Connect client using the authorization code flow.
@@ -38,7 +48,7 @@ func NewOidcClient(evalCtx *hcl.EvalContext, oidcConfig *oidc.Config) (*OidcClie
 	}
 
 	var algorithms []string
-	for _, a := range append(acjwt.RSAAlgorithms, acjwt.ECDSAlgorithms...) {
+	for _, a := range acjwt.PublicKeyAlgorithms {
 		algorithms = append(algorithms, a.String())
 	}
 	issuer, err := oidcConfig.GetIssuer()
@@ -104,6 +114,54 @@ func (o *OidcClient) validateTokenResponseData(ctx context.Context, tokenRespons
 	return nil
 }
 
+// ValidateLogoutToken validates the logout token and returns its claims, see
+// OpenID Connect Back-Channel Logout 1.0, section 2.6.
+func (o *OidcClient) ValidateLogoutToken(logoutToken string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	token, err := o.jwtParser.ParseWithClaims(logoutToken, claims, o.keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// explicitly typed logout tokens must not be confused with other JWT types
+	if typ, exists := token.Header["typ"]; exists {
+		t, _ := typ.(string)
+		t = strings.TrimPrefix(strings.ToLower(t), "application/")
+		if t != "logout+jwt" && t != "jwt" {
+			return nil, fmt.Errorf("invalid typ header in logout token: %v", typ)
+		}
+	}
+
+	if _, ok := claims["iat"].(float64); !ok {
+		return nil, fmt.Errorf("missing iat claim in logout token")
+	}
+
+	if _, ok := claims["exp"].(float64); !ok {
+		return nil, fmt.Errorf("missing exp claim in logout token")
+	}
+
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return nil, fmt.Errorf("missing jti claim in logout token")
+	}
+
+	sub, _ := claims["sub"].(string)
+	sid, _ := claims["sid"].(string)
+	if sub == "" && sid == "" {
+		return nil, fmt.Errorf("missing sub and sid claims in logout token")
+	}
+
+	events, _ := claims["events"].(map[string]interface{})
+	if _, ok := events[BackChannelLogoutEvent].(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("missing back-channel logout event in logout token")
+	}
+
+	if _, exists := claims["nonce"]; exists {
+		return nil, fmt.Errorf("logout token must not contain a nonce claim")
+	}
+
+	return claims, nil
+}
+
 func (o *OidcClient) keyfunc(token *jwt.Token) (interface{}, error) {
 	return o.config.JWKS().
 		GetSigKeyForToken(token)
@@ -0,0 +1,156 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/logging"
+)
+
+func TestIntegration_APIKey(t *testing.T) {
+	client := newClient()
+
+	helper := test.New(t)
+	shutdown, hook := newCouper("testdata/integration/api_key/01_couper.hcl", helper)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name      string
+		path      string
+		key       string
+		expStatus int
+		expBody   string
+		expErr    string
+	}{
+		{"inline key", "/read", "inline-key", http.StatusOK, `{"client":"env-client","permissions":["read"]}`, ""},
+		{"hashed key", "/read", "file-key", http.StatusOK, `{"client":"file-client","permissions":"read write"}`, ""},
+		{"mapped permission", "/delete", "file-key", http.StatusOK, `{"client":"file-client","permissions":"read write"}`, ""},
+		{"insufficient permissions", "/delete", "inline-key", http.StatusForbidden, "", "insufficient_permissions"},
+		{"invalid key", "/read", "unknown", http.StatusUnauthorized, "", "api_key"},
+		{"missing key", "/read", "", http.StatusUnauthorized, "", "api_key_missing"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				st.Errorf("\nwant:\t%s\ngot:\t%s", tc.expBody, string(b))
+			}
+
+			if tc.expErr != "" {
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIntegration_APIKeyNotForwarded(t *testing.T) {
+	client := newClient()
+
+	helper := test.New(t)
+	shutdown, hook := newCouper("testdata/integration/api_key/01_couper.hcl", helper)
+	defer shutdown()
+
+	type anything struct {
+		Headers  http.Header
+		RawQuery string
+	}
+
+	for _, tc := range []struct {
+		name      string
+		path      string
+		header    string
+		cookie    string
+		expQuery  string
+		expPath   string
+		expCookie string
+	}{
+		{"header", "/proxy?a=b", "inline-key", "", "a=b", "/proxy?a=b", ""},
+		{"query param", "/query?a=b&key=inline-key&c=d", "", "", "a=b&c=d", "/query?a=b&key=***&c=d", ""},
+		{"cookie", "/cookie", "", "a=1; key=inline-key; b=2", "", "/cookie", "a=1; b=2"},
+		{"request block", "/request", "inline-key", "", "", "/request", ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			if tc.header != "" {
+				req.Header.Set("X-API-Key", tc.header)
+			}
+			if tc.cookie != "" {
+				req.Header.Set("Cookie", tc.cookie)
+			}
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != http.StatusOK {
+				st.Fatalf("want status 200, got %d", res.StatusCode)
+			}
+
+			var result anything
+			h.Must(json.Unmarshal(b, &result))
+
+			if key := result.Headers.Get("X-API-Key"); key != "" {
+				st.Errorf("expected no forwarded api key header, got %q", key)
+			}
+
+			if cookie := result.Headers.Get("Cookie"); cookie != tc.expCookie {
+				st.Errorf("want forwarded cookie %q, got %q", tc.expCookie, cookie)
+			}
+
+			if result.RawQuery != tc.expQuery {
+				st.Errorf("want forwarded query %q, got %q", tc.expQuery, result.RawQuery)
+			}
+
+			for _, e := range hook.AllEntries() {
+				switch e.Data["type"] {
+				case "couper_access":
+					if path := e.Data["request"].(logging.Fields)["path"]; path != tc.expPath {
+						st.Errorf("want logged path %q, got %q", tc.expPath, path)
+					}
+				case "couper_backend":
+					if url, _ := e.Data["url"].(string); strings.Contains(url, "inline-key") {
+						st.Errorf("expected no api key in backend log url: %q", url)
+					}
+				}
+			}
+		})
+	}
+}
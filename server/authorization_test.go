@@ -0,0 +1,83 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestIntegration_Authorization(t *testing.T) {
+	client := newClient()
+
+	helper := test.New(t)
+	shutdown, hook := newCouper("testdata/integration/authorization/01_couper.hcl", helper)
+	defer shutdown()
+
+	newToken := func(sub string, roles ...string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   sub,
+			"roles": append([]string{}, roles...),
+		}).SignedString([]byte("s3cr3t"))
+		helper.Must(err)
+		return token
+	}
+
+	for _, tc := range []struct {
+		name      string
+		method    string
+		path      string
+		token     string
+		expStatus int
+		expBody   string
+		expErr    string
+	}{
+		{"owner", http.MethodGet, "/users/alice", newToken("alice"), http.StatusOK, `{"decision":"allow","rule":"owner"}`, ""},
+		{"other user", http.MethodGet, "/users/bob", newToken("alice"), http.StatusNotFound, `{"decision":"deny"}`, "authorization"},
+		{"admin for other user", http.MethodGet, "/users/bob", newToken("alice", "admin"), http.StatusOK, `{"decision":"allow","rule":"admin"}`, ""},
+		{"admin read", http.MethodGet, "/admin", newToken("alice", "admin"), http.StatusOK, `{"decision":"allow","rule":"admin"}`, ""},
+		{"admin write", http.MethodPost, "/admin", newToken("alice", "admin"), http.StatusForbidden, "", "authorization"},
+		{"non-admin read", http.MethodGet, "/admin", newToken("alice"), http.StatusForbidden, "", "authorization"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(tc.method, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Fatalf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				st.Errorf("\nwant:\t%s\ngot:\t%s", tc.expBody, string(b))
+			}
+
+			var decisionLogged bool
+			for _, e := range hook.AllEntries() {
+				if e.Data["type"] == "couper_access" && tc.expErr != "" && e.Data["error_type"] != tc.expErr {
+					st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+				}
+				if e.Message == "policy decision" {
+					decisionLogged = true
+				}
+			}
+
+			if expLogged := tc.path != "/admin"; decisionLogged != expLogged {
+				st.Errorf("want decision logged: %t", expLogged)
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/env"
@@ -143,6 +145,8 @@ func New(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *conf
 			return nil, err
 		}
 		srv.TLSConfig = tlsConfig
+	} else if settings.H2C {
+		srv.Handler = h2c.NewHandler(srv.Handler, &http2.Server{IdleTimeout: timings.IdleTimeout})
 	}
 
 	httpSrv.srv = srv
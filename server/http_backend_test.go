@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/coupergateway/couper/internal/test"
 	"github.com/coupergateway/couper/logging"
@@ -492,3 +500,725 @@ func TestBackend_BackendVar(t *testing.T) {
 		}
 	}
 }
+
+func TestBackend_ResponseCache(t *testing.T) {
+	helper := test.New(t)
+
+	var mu sync.Mutex
+	counter := make(map[string]int)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counter[r.URL.Path]++
+		count := counter[r.URL.Path]
+		mu.Unlock()
+
+		switch path.Base(r.URL.Path) {
+		case "max-age":
+			rw.Header().Set("Cache-Control", "max-age=60")
+		case "etag":
+			rw.Header().Set("Cache-Control", "no-cache")
+			rw.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "stale":
+			if count > 1 {
+				conn, _, _ := rw.(http.Hijacker).Hijack()
+				_ = conn.Close()
+				return
+			}
+			rw.Header().Set("Cache-Control", "max-age=0")
+			rw.Header().Set("ETag", `"v1"`)
+		case "no-store":
+			rw.Header().Set("Cache-Control", "no-store")
+		case "large":
+			rw.Header().Set("Cache-Control", "max-age=60")
+			_, _ = rw.Write(bytes.Repeat([]byte("x"), 2048))
+		}
+
+		_, _ = rw.Write([]byte(strconv.Itoa(count)))
+	}))
+	defer origin.Close()
+
+	shutdown, hook, cerr := newCouperWithTemplate("testdata/integration/backends/09_couper.hcl", helper,
+		map[string]interface{}{
+			"origin": origin.URL,
+		})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	for _, tc := range []struct {
+		path      string
+		tenant    string
+		expStatus string
+		expBody   string
+	}{
+		{"/max-age", "", "MISS", "1"},
+		{"/max-age", "", "HIT", "1"},
+		{"/etag", "", "MISS", "1"},
+		{"/etag", "", "REVALIDATED", "1"},
+		{"/stale", "", "MISS", "1"},
+		{"/stale", "", "STALE", "1"},
+		{"/no-store", "", "MISS", "1"},
+		{"/no-store", "", "MISS", "2"},
+		{"/large", "", "MISS", ""},
+		{"/large", "", "MISS", ""},
+		{"/tenant/max-age", "a", "MISS", "1"},
+		{"/tenant/max-age", "b", "MISS", "2"},
+		{"/tenant/max-age", "a", "HIT", "1"},
+	} {
+		hook.Reset()
+
+		req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080"+tc.path, nil)
+		if tc.tenant != "" {
+			req.Header.Set("X-Tenant", tc.tenant)
+		}
+
+		res, err := client.Do(req)
+		helper.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		helper.Must(err)
+		helper.Must(res.Body.Close())
+
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("%s: want: 200, got %d", tc.path, res.StatusCode)
+		}
+
+		if status := res.Header.Get("X-Cache-Status"); status != tc.expStatus {
+			t.Errorf("%s: want cache status %q, got %q", tc.path, tc.expStatus, status)
+		}
+
+		if tc.expBody != "" && string(b) != tc.expBody {
+			t.Errorf("%s: want body %q, got %q", tc.path, tc.expBody, string(b))
+		} else if tc.expBody == "" && len(b) != 2049 {
+			t.Errorf("%s: want body length 2049, got %d", tc.path, len(b))
+		}
+
+		if tc.expStatus == "HIT" && res.Header.Get("Age") == "" {
+			t.Errorf("%s: expected Age header", tc.path)
+		}
+
+		for _, e := range hook.AllEntries() {
+			if e.Data["type"] != "couper_backend" {
+				continue
+			}
+
+			if status := e.Data["response"].(logging.Fields)["cache_status"]; status != tc.expStatus {
+				t.Errorf("%s: want logged cache status %q, got %v", tc.path, tc.expStatus, status)
+			}
+		}
+	}
+}
+
+func TestBackend_Retry(t *testing.T) {
+	helper := test.New(t)
+
+	var mu sync.Mutex
+	counter := make(map[string]int)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counter[r.URL.Path]++
+		count := counter[r.URL.Path]
+		mu.Unlock()
+
+		failures, _ := strconv.Atoi(path.Base(r.URL.Path))
+		if count <= failures {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		_, _ = rw.Write(b)
+	}))
+	defer origin.Close()
+
+	shutdown, hook, cerr := newCouperWithTemplate("testdata/integration/backends/10_couper.hcl", helper,
+		map[string]interface{}{
+			"origin": origin.URL,
+		})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	for _, tc := range []struct {
+		method      string
+		path        string
+		body        string
+		expStatus   int
+		expAttempts int
+	}{
+		{http.MethodGet, "/ok/0", "", http.StatusOK, 1},
+		{http.MethodGet, "/flaky/2", "", http.StatusOK, 3},
+		{http.MethodGet, "/down/5", "", http.StatusServiceUnavailable, 3},
+		{http.MethodPost, "/not-idempotent/1", "data", http.StatusServiceUnavailable, 1},
+		{http.MethodPost, "/post/flaky/1", "data", http.StatusOK, 2},
+	} {
+		hook.Reset()
+
+		req, _ := http.NewRequest(tc.method, "http://couper.dev:8080"+tc.path, strings.NewReader(tc.body))
+
+		res, err := client.Do(req)
+		helper.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		helper.Must(err)
+		helper.Must(res.Body.Close())
+
+		if res.StatusCode != tc.expStatus {
+			t.Errorf("%s: want: %d, got %d", tc.path, tc.expStatus, res.StatusCode)
+		}
+
+		if tc.expStatus == http.StatusOK && string(b) != tc.body {
+			t.Errorf("%s: want body %q, got %q", tc.path, tc.body, string(b))
+		}
+
+		mu.Lock()
+		count := counter[tc.path]
+		mu.Unlock()
+
+		if count != tc.expAttempts {
+			t.Errorf("%s: want %d origin requests, got %d", tc.path, tc.expAttempts, count)
+		}
+
+		for _, e := range hook.AllEntries() {
+			if e.Data["type"] != "couper_backend" {
+				continue
+			}
+
+			if attempts := e.Data["attempts"]; attempts != uint(tc.expAttempts) {
+				t.Errorf("%s: want logged attempts %d, got %v", tc.path, tc.expAttempts, attempts)
+			}
+		}
+	}
+}
+
+func TestBackend_CircuitBreaker(t *testing.T) {
+	helper := test.New(t)
+
+	var (
+		mu       sync.Mutex
+		failing  = true
+		requests int
+	)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		if failing {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer origin.Close()
+
+	shutdown, hook, cerr := newCouperWithTemplate("testdata/integration/backends/11_couper.hcl", helper,
+		map[string]interface{}{
+			"origin": origin.URL,
+		})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	send := func(path string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080"+path, nil)
+		res, err := client.Do(req)
+		helper.Must(err)
+		return res
+	}
+
+	circuitState := func() string {
+		res := send("/state")
+		health := make(map[string]interface{})
+		helper.Must(json.NewDecoder(res.Body).Decode(&health))
+		helper.Must(res.Body.Close())
+		state, _ := health["circuit_breaker"].(string)
+		return state
+	}
+
+	for i, tc := range []struct {
+		failing   bool
+		expStatus int
+		expState  string
+		expCount  int
+	}{
+		{true, http.StatusServiceUnavailable, "closed", 1},
+		{true, http.StatusServiceUnavailable, "open", 2},
+		{true, http.StatusBadGateway, "open", 2}, // fails fast
+		{false, http.StatusBadGateway, "open", 2},
+	} {
+		mu.Lock()
+		failing = tc.failing
+		mu.Unlock()
+
+		hook.Reset()
+		res := send("/")
+		helper.Must(res.Body.Close())
+
+		if res.StatusCode != tc.expStatus {
+			t.Errorf("%d: want status %d, got %d", i, tc.expStatus, res.StatusCode)
+		}
+
+		if state := circuitState(); state != tc.expState {
+			t.Errorf("%d: want circuit state %q, got %q", i, tc.expState, state)
+		}
+
+		mu.Lock()
+		count := requests
+		mu.Unlock()
+		if count != tc.expCount {
+			t.Errorf("%d: want %d origin requests, got %d", i, tc.expCount, count)
+		}
+	}
+
+	if errorType := getAccessLogErrorType(hook); errorType != "backend_unhealthy" {
+		t.Errorf("want error type backend_unhealthy, got %q", errorType)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if state := circuitState(); state != "half_open" {
+		t.Errorf("want circuit state half_open, got %q", state)
+	}
+
+	res := send("/") // trial request
+	helper.Must(res.Body.Close())
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("want status 200, got %d", res.StatusCode)
+	}
+
+	if state := circuitState(); state != "closed" {
+		t.Errorf("want circuit state closed, got %q", state)
+	}
+}
+
+func TestBackend_LoadBalancing(t *testing.T) {
+	helper := test.New(t)
+
+	var (
+		mu        sync.Mutex
+		unhealthy = make(map[string]bool)
+	)
+
+	newOrigin := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			down := unhealthy[name]
+			mu.Unlock()
+
+			if r.URL.Path == "/health" && down {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.Header().Set("X-Origin", name)
+		}))
+	}
+
+	originA, originB := newOrigin("a"), newOrigin("b")
+	defer originA.Close()
+	defer originB.Close()
+
+	shutdown, _, cerr := newCouperWithTemplate("testdata/integration/backends/12_couper.hcl", helper,
+		map[string]interface{}{
+			"origin_a": originA.URL,
+			"origin_b": originB.URL,
+		})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	send := func(path, user string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080"+path, nil)
+		req.Header.Set("X-User", user)
+		res, err := client.Do(req)
+		helper.Must(err)
+		helper.Must(res.Body.Close())
+		return res
+	}
+
+	// wait for the initial health checks
+	time.Sleep(300 * time.Millisecond)
+
+	counts := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		counts[send("/lb", "").Header.Get("X-Origin")]++
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("want round-robin distribution, got %v", counts)
+	}
+
+	for _, user := range []string{"alice", "bob", "carol"} {
+		first := send("/hash", user).Header.Get("X-Origin")
+		for i := 0; i < 3; i++ {
+			if o := send("/hash", user).Header.Get("X-Origin"); o != first {
+				t.Errorf("%s: want stable origin %q, got %q", user, first, o)
+			}
+		}
+	}
+
+	mu.Lock()
+	unhealthy["b"] = true
+	mu.Unlock()
+
+	time.Sleep(300 * time.Millisecond)
+
+	for i := 0; i < 4; i++ {
+		if o := send("/lb", "").Header.Get("X-Origin"); o != "a" {
+			t.Errorf("%d: want healthy origin a, got %q", i, o)
+		}
+	}
+
+	res, err := client.Get("http://couper.dev:8080/state")
+	helper.Must(err)
+	health := make(map[string]interface{})
+	helper.Must(json.NewDecoder(res.Body).Decode(&health))
+	helper.Must(res.Body.Close())
+
+	if health["state"] != "failing" || health["healthy"] != true {
+		t.Errorf("want failing but healthy backend, got %v", health)
+	}
+
+	origins, _ := health["origins"].(map[string]interface{})
+	if b, _ := origins[originB.URL].(map[string]interface{}); b == nil || b["healthy"] != false {
+		t.Errorf("want unhealthy origin %q, got %v", originB.URL, origins)
+	}
+}
+
+func TestBackend_Fallback(t *testing.T) {
+	helper := test.New(t)
+
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	secondary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		rw.Header().Set("X-Body", string(b))
+		rw.Header().Set("X-Origin", "secondary")
+	}))
+	defer secondary.Close()
+
+	shutdown, hook, cerr := newCouperWithTemplate("testdata/integration/backends/13_couper.hcl", helper,
+		map[string]interface{}{
+			"primary":   primary.URL,
+			"secondary": secondary.URL,
+		})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	for _, tc := range []struct {
+		path         string
+		expBackend   string
+		expBody      string
+		expPrimary   string
+		expPrimaryTo int
+	}{
+		{"/proxy", "secondary", "", "primary", http.StatusServiceUnavailable},
+		{"/refused", "anonymous_", "payload", "refused", 0},
+	} {
+		t.Run(tc.path, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080"+tc.path, nil)
+			res, err := client.Do(req)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != http.StatusOK {
+				st.Errorf("want status 200, got %d", res.StatusCode)
+			}
+
+			if res.Header.Get("X-Origin") != "secondary" {
+				st.Errorf("want response from the fallback backend, got %q", res.Header.Get("X-Origin"))
+			}
+
+			if b := res.Header.Get("X-Backend"); !strings.HasPrefix(b, tc.expBackend) {
+				st.Errorf("want backend_name %q, got %q", tc.expBackend, b)
+			}
+
+			if b := res.Header.Get("X-Body"); b != tc.expBody {
+				st.Errorf("want replayed body %q, got %q", tc.expBody, b)
+			}
+
+			var primaryLogged, fallbackLogged bool
+			for _, entry := range hook.AllEntries() {
+				if entry.Data["type"] != "couper_backend" {
+					continue
+				}
+
+				if entry.Data["backend"] == tc.expPrimary {
+					primaryLogged = true
+					if status := entry.Data["status"]; status != tc.expPrimaryTo {
+						st.Errorf("want primary status %d, got %v", tc.expPrimaryTo, status)
+					}
+				} else if entry.Data["fallback_for"] == tc.expPrimary {
+					fallbackLogged = true
+				}
+			}
+
+			if !primaryLogged || !fallbackLogged {
+				st.Errorf("want log entries for the primary and the fallback backend, got primary: %t, fallback: %t", primaryLogged, fallbackLogged)
+			}
+		})
+	}
+}
+
+func TestBackend_GRPC(t *testing.T) {
+	helper := test.New(t)
+
+	// length-prefixed gRPC message
+	message := []byte{0, 0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o'}
+
+	origin := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			rw.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+
+		rw.Header().Set("Content-Type", "application/grpc+proto")
+		if r.URL.Path == "/svc.Echo/Unavailable" {
+			rw.Header().Set("Grpc-Status", "14")
+			rw.Header().Set("Grpc-Message", "maintenance")
+			return
+		}
+
+		rw.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		rw.Header().Set("X-Te", r.Header.Get("Te"))
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(b)
+		rw.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+	}), &http2.Server{}))
+	defer origin.Close()
+
+	shutdown, _, cerr := newCouperWithTemplate("testdata/integration/backends/14_couper.hcl", helper,
+		map[string]interface{}{"origin": origin.URL})
+	helper.Must(cerr)
+	defer shutdown()
+
+	h2cClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "tcp4", "127.0.0.1:8080")
+			},
+		},
+	}
+
+	t.Run("native", func(st *testing.T) {
+		h := test.New(st)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://couper.dev:8080/svc.Echo/Unary", bytes.NewReader(message))
+		req.Header.Set("Content-Type", "application/grpc+proto")
+		req.Header.Set("Te", "trailers")
+
+		res, err := h2cClient.Do(req)
+		h.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		h.Must(err)
+		h.Must(res.Body.Close())
+
+		if res.ProtoMajor != 2 {
+			st.Errorf("want HTTP/2, got %s", res.Proto)
+		}
+
+		if !bytes.Equal(b, message) {
+			st.Errorf("want message %v, got %v", message, b)
+		}
+
+		if te := res.Header.Get("X-Te"); te != "trailers" {
+			st.Errorf("want te header field %q, got %q", "trailers", te)
+		}
+
+		if s := res.Trailer.Get("Grpc-Status"); s != "0" {
+			st.Errorf("want grpc-status trailer 0, got %q", s)
+		}
+	})
+
+	t.Run("native unavailable", func(st *testing.T) {
+		h := test.New(st)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://couper.dev:8080/svc.Echo/Unavailable", bytes.NewReader(message))
+		req.Header.Set("Content-Type", "application/grpc")
+
+		res, err := h2cClient.Do(req)
+		h.Must(err)
+		h.Must(res.Body.Close())
+
+		if res.StatusCode != http.StatusOK {
+			st.Errorf("want status 200, got %d", res.StatusCode)
+		}
+
+		if s := res.Header.Get("Grpc-Status"); s != "14" {
+			st.Errorf("want grpc-status 14, got %q", s)
+		}
+
+		if ce := res.Header.Get("Couper-Error"); ce != "backend error" {
+			st.Errorf("want backend error, got %q", ce)
+		}
+	})
+
+	t.Run("web text", func(st *testing.T) {
+		h := test.New(st)
+
+		// padded base64 chunks
+		body := base64.StdEncoding.EncodeToString(message[:4]) + base64.StdEncoding.EncodeToString(message[4:])
+		req, _ := http.NewRequest(http.MethodPost, "http://couper.dev:8080/web/Unary", strings.NewReader(body))
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Content-Type", "application/grpc-web-text+proto")
+		req.Header.Set("Origin", "https://example.com")
+
+		res, err := test.NewHTTPClient().Do(req)
+		h.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		h.Must(err)
+		h.Must(res.Body.Close())
+
+		if ct := res.Header.Get("Content-Type"); ct != "application/grpc-web-text+proto" {
+			st.Errorf("want gRPC-Web content-type, got %q", ct)
+		}
+
+		if ct := res.Header.Get("X-Content-Type"); ct != "application/grpc+proto" {
+			st.Errorf("want gRPC content-type for the backend, got %q", ct)
+		}
+
+		if eh := res.Header.Get("Access-Control-Expose-Headers"); eh != "grpc-status, grpc-message" {
+			st.Errorf("want exposed status headers, got %q", eh)
+		}
+
+		var decoded []byte
+		for i := 0; i+4 <= len(b); i += 4 {
+			quantum, derr := base64.StdEncoding.DecodeString(string(b[i : i+4]))
+			h.Must(derr)
+			decoded = append(decoded, quantum...)
+		}
+
+		trailerFrame := append([]byte{0x80, 0, 0, 0, 16}, "grpc-status: 0\r\n"...)
+		if exp := append(message, trailerFrame...); !bytes.Equal(decoded, exp) {
+			st.Errorf("want frames %q, got %q", exp, decoded)
+		}
+	})
+
+	t.Run("web unavailable", func(st *testing.T) {
+		h := test.New(st)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://couper.dev:8080/web/Unavailable", bytes.NewReader(message))
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+
+		res, err := test.NewHTTPClient().Do(req)
+		h.Must(err)
+		h.Must(res.Body.Close())
+
+		if ct := res.Header.Get("Content-Type"); ct != "application/grpc-web+proto" {
+			st.Errorf("want gRPC-Web content-type, got %q", ct)
+		}
+
+		if s := res.Header.Get("Grpc-Status"); s != "14" {
+			st.Errorf("want grpc-status 14, got %q", s)
+		}
+	})
+}
+
+func TestBackend_SSE(t *testing.T) {
+	helper := test.New(t)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.WriteHeader(http.StatusOK)
+		rw.(http.Flusher).Flush()
+
+		for i := 1; i <= 3; i++ {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Millisecond * 400):
+			}
+			_, _ = fmt.Fprintf(rw, ": keep-alive\n\nid: %d\ndata: event %d\n\n", i, i)
+			rw.(http.Flusher).Flush()
+		}
+
+		<-r.Context().Done()
+	}))
+	defer origin.Close()
+
+	shutdown, hook, cerr := newCouperWithTemplate("testdata/integration/backends/15_couper.hcl", helper,
+		map[string]interface{}{"origin": origin.URL})
+	helper.Must(cerr)
+	defer shutdown()
+
+	client := test.NewHTTPClient()
+
+	t.Run("sse", func(st *testing.T) {
+		h := test.New(st)
+		hook.Reset()
+
+		req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080/sse", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		res, err := client.Do(req)
+		h.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		h.Must(err)
+		h.Must(res.Body.Close())
+
+		if !strings.HasPrefix(string(b), "retry: 3000\n\n") {
+			st.Errorf("want retry field, got %q", string(b))
+		}
+
+		if !strings.HasSuffix(string(b), "data: event 3\n\n") {
+			st.Errorf("want all events after the backend timeout, got %q", string(b))
+		}
+
+		var sseFields logging.Fields
+		for i := 0; i < 10 && sseFields == nil; i++ {
+			for _, e := range hook.AllEntries() {
+				if e.Data["type"] != "couper_backend" {
+					continue
+				}
+				if response, ok := e.Data["response"].(logging.Fields); ok {
+					sseFields, _ = response["sse"].(logging.Fields)
+				}
+			}
+			time.Sleep(time.Millisecond * 50)
+		}
+
+		if sseFields == nil {
+			st.Fatal("expected sse log fields")
+		}
+
+		if sseFields["events"] != int64(3) || sseFields["last_event_id"] != "3" {
+			st.Errorf("want 3 events with last id 3, got %v", sseFields)
+		}
+	})
+
+	t.Run("no-sse", func(st *testing.T) {
+		h := test.New(st)
+
+		req, _ := http.NewRequest(http.MethodGet, "http://couper.dev:8080/no-sse", nil)
+		res, err := client.Do(req)
+		h.Must(err)
+
+		b, _ := io.ReadAll(res.Body)
+		h.Must(res.Body.Close())
+
+		if strings.Contains(string(b), "event 3") {
+			st.Errorf("expected the backend timeout to close the stream, got %q", string(b))
+		}
+	})
+}
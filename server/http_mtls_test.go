@@ -1,14 +1,19 @@
 package server_test
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/coupergateway/couper/internal/test"
 	"github.com/coupergateway/couper/server"
 )
@@ -311,3 +316,146 @@ func TestHTTPSServer_TLS_ServerBackendClient(t *testing.T) {
 		t.Errorf("Expected statusOK, got: %d", res.StatusCode)
 	}
 }
+
+func TestHTTPSServer_TLS_ClientCertificateAccessControl(t *testing.T) {
+	helper := test.New(t)
+
+	selfSigned, err := server.NewCertificate(time.Minute, nil, nil)
+	helper.Must(err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(selfSigned.CA.Leaf)
+	client := test.NewHTTPSClient(&tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{*selfSigned.Client},
+	})
+
+	shutdown, hook, err := newCouperWithTemplate("testdata/mtls/08_couper.hcl", helper, map[string]interface{}{
+		"publicKey":  string(selfSigned.ServerCertificate.Certificate),             // PEM
+		"privateKey": string(selfSigned.ServerCertificate.PrivateKey),              // PEM
+		"clientCA":   string(selfSigned.ClientIntermediateCertificate.Certificate), // PEM
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	fingerprint := sha256.Sum256(selfSigned.Client.Leaf.Raw)
+
+	for _, tc := range []struct {
+		path      string
+		expStatus int
+		expBody   string
+		expErr    string
+	}{
+		{"/read", http.StatusOK, fmt.Sprintf(`{"fingerprint":"%x","permissions":["develop","read"],"subject":"OU=Development,O=Couper,C=DE"}`, fingerprint), ""},
+		{"/admin", http.StatusForbidden, "", "insufficient_permissions"},
+		{"/partner", http.StatusForbidden, "", "client_certificate"},
+	} {
+		t.Run(tc.path, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			outreq, err := http.NewRequest(http.MethodGet, "https://localhost:4443"+tc.path, nil)
+			h.Must(err)
+
+			res, err := client.Do(outreq)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				st.Errorf("\nwant:\t%s\ngot:\t%s", tc.expBody, string(b))
+			}
+
+			if tc.expErr != "" {
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPSServer_TLS_CertificateBoundToken(t *testing.T) {
+	helper := test.New(t)
+
+	selfSigned, err := server.NewCertificate(time.Minute, nil, nil)
+	helper.Must(err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(selfSigned.CA.Leaf)
+	client := test.NewHTTPSClient(&tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{*selfSigned.Client},
+	})
+
+	shutdown, hook, err := newCouperWithTemplate("testdata/mtls/09_couper.hcl", helper, map[string]interface{}{
+		"publicKey":  string(selfSigned.ServerCertificate.Certificate),             // PEM
+		"privateKey": string(selfSigned.ServerCertificate.PrivateKey),              // PEM
+		"clientCA":   string(selfSigned.ClientIntermediateCertificate.Certificate), // PEM
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	thumbprint := sha256.Sum256(selfSigned.Client.Leaf.Raw)
+	newToken := func(x5t string) string {
+		token, terr := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "me",
+			"cnf": map[string]interface{}{"x5t#S256": x5t},
+		}).SignedString([]byte("y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"))
+		helper.Must(terr)
+		return token
+	}
+
+	for _, tc := range []struct {
+		name      string
+		token     string
+		expStatus int
+		expErr    string
+	}{
+		{"bound token", newToken(base64.RawURLEncoding.EncodeToString(thumbprint[:])), http.StatusOK, ""},
+		{"token bound to other certificate", newToken("c29tZS1vdGhlci1jZXJ0aWZpY2F0ZQ"), http.StatusUnauthorized, "jwt_certificate_binding_invalid"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			outreq, err := http.NewRequest(http.MethodGet, "https://localhost:4443/", nil)
+			h.Must(err)
+			outreq.Header.Set("Authorization", "Bearer "+tc.token)
+
+			res, err := client.Do(outreq)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expErr != "" {
+				if wwwAuth := res.Header.Get("WWW-Authenticate"); !strings.HasPrefix(wwwAuth, `Bearer error="invalid_token"`) {
+					st.Errorf("unexpected WWW-Authenticate header: %q", wwwAuth)
+				}
+
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
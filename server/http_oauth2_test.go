@@ -1423,6 +1423,277 @@ func TestOAuth2_AccessControl(t *testing.T) {
 	}
 }
 
+func TestOAuth2_Session(t *testing.T) {
+	client := newClient()
+
+	var refreshTokens []string
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/token":
+			_ = req.ParseForm()
+			switch req.PostForm.Get("grant_type") {
+			case "authorization_code":
+				_, _ = rw.Write([]byte(`{"access_token":"at1","token_type":"bearer","expires_in":10,"refresh_token":"rt1","id_token":"id.token"}`))
+			case "refresh_token":
+				refreshTokens = append(refreshTokens, req.PostForm.Get("refresh_token"))
+				_, _ = rw.Write([]byte(`{"access_token":"at2","token_type":"bearer","expires_in":3600,"refresh_token":"rt2"}`))
+			default:
+				rw.WriteHeader(http.StatusBadRequest)
+			}
+		case "/resource":
+			b, _ := json.Marshal(map[string]string{
+				"authorization": req.Header.Get("Authorization"),
+				"cookie":        req.Header.Get("Cookie"),
+			})
+			_, _ = rw.Write(b)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer oauthOrigin.Close()
+
+	helper := test.New(t)
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/25_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://back.end:8080/resource", nil)
+	helper.Must(err)
+	res, err := client.Do(req)
+	helper.Must(err)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d without session, got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, "http://back.end:8080/cb?code=qeuboub", nil)
+	helper.Must(err)
+	req.Header.Set("Cookie", "pkcecv=qerbnr")
+	res, err = client.Do(req)
+	helper.Must(err)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var sessionCookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == "couper_session" {
+			sessionCookie = c
+		}
+	}
+	if sessionCookie == nil || sessionCookie.Value == "" || !sessionCookie.HttpOnly {
+		t.Fatalf("expected session cookie, got %#v", res.Header.Values("Set-Cookie"))
+	}
+
+	// the access token expires within the refresh leeway
+	for i := 0; i < 2; i++ {
+		req, err = http.NewRequest(http.MethodGet, "http://back.end:8080/resource", nil)
+		helper.Must(err)
+		req.AddCookie(&http.Cookie{Name: sessionCookie.Name, Value: sessionCookie.Value})
+		res, err = client.Do(req)
+		helper.Must(err)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+
+		var backendReq map[string]string
+		helper.Must(json.NewDecoder(res.Body).Decode(&backendReq))
+		if backendReq["authorization"] != "Bearer at2" {
+			t.Errorf("expected refreshed access token, got %q", backendReq["authorization"])
+		}
+		if backendReq["cookie"] != "" {
+			t.Errorf("expected no cookies, got %q", backendReq["cookie"])
+		}
+	}
+	if len(refreshTokens) != 1 || refreshTokens[0] != "rt1" {
+		t.Errorf("expected one refresh with rt1, got %v", refreshTokens)
+	}
+
+	req, err = http.NewRequest(http.MethodPost, "http://back.end:8080/logout", nil)
+	helper.Must(err)
+	req.AddCookie(&http.Cookie{Name: sessionCookie.Name, Value: sessionCookie.Value})
+	res, err = client.Do(req)
+	helper.Must(err)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+	resBytes, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	if string(resBytes) != `{"id_token":"id.token"}` {
+		t.Errorf("expected id token for logout, got %s", resBytes)
+	}
+	if setCookie := res.Header.Get("Set-Cookie"); !strings.HasPrefix(setCookie, "couper_session=; Path=/; Max-Age=0;") {
+		t.Errorf("expected session cookie to be cleared, got %q", setCookie)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, "http://back.end:8080/resource", nil)
+	helper.Must(err)
+	req.AddCookie(&http.Cookie{Name: sessionCookie.Name, Value: sessionCookie.Value})
+	res, err = client.Do(req)
+	helper.Must(err)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d after logout, got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestOIDC_Logout(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	keyBytes, err := os.ReadFile("testdata/integration/files/pkcs8.key")
+	helper.Must(err)
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyBytes)
+	helper.Must(err)
+
+	st := "qeirtbnpetrbi"
+	newTokenWithTyp := func(claims jwt.MapClaims, typ string) string {
+		claims["iss"] = "https://authorization.server"
+		claims["aud"] = "foo"
+		claims["iat"] = time.Now().Unix()
+		header := map[string]interface{}{"kid": "rs256"}
+		if typ != "" {
+			header["typ"] = typ
+		}
+		token, terr := lib.CreateJWT("RS256", key, claims, header)
+		helper.Must(terr)
+		return token
+	}
+	newToken := func(claims jwt.MapClaims) string {
+		return newTokenWithTyp(claims, "")
+	}
+
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/.well-known/openid-configuration":
+			_, _ = rw.Write([]byte(`{
+			"issuer": "https://authorization.server",
+			"authorization_endpoint": "https://authorization.server/oauth2/authorize",
+			"end_session_endpoint": "https://authorization.server/oauth2/logout",
+			"jwks_uri": "http://` + req.Host + `/jwks",
+			"token_endpoint": "http://` + req.Host + `/token"
+			}`))
+		case "/jwks":
+			http.ServeFile(rw, req, "testdata/integration/files/jwks.json")
+		case "/token":
+			idToken := newToken(jwt.MapClaims{
+				"exp":   time.Now().Add(time.Hour).Unix(),
+				"nonce": oauth2.Base64urlSha256(st),
+				"sid":   "sid1",
+				"sub":   "myself",
+			})
+			_, _ = rw.Write([]byte(`{"access_token":"at","token_type":"bearer","expires_in":3600,"id_token":"` + idToken + `"}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer oauthOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/26_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	login := func() *http.Cookie {
+		req, rerr := http.NewRequest(http.MethodGet, "http://back.end:8080/cb?code=qeuboub", nil)
+		helper.Must(rerr)
+		req.Header.Set("Cookie", "nnc="+st)
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d for login, got %d", http.StatusOK, res.StatusCode)
+		}
+		for _, c := range res.Cookies() {
+			if c.Name == "couper_session" {
+				return &http.Cookie{Name: c.Name, Value: c.Value}
+			}
+		}
+		t.Fatal("expected session cookie")
+		return nil
+	}
+
+	request := func(method, path string, cookie *http.Cookie, body io.Reader) *http.Response {
+		req, rerr := http.NewRequest(method, "http://back.end:8080"+path, body)
+		helper.Must(rerr)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		return res
+	}
+
+	// RP-initiated logout
+	cookie := login()
+	res := request(http.MethodPost, "/logout", cookie, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d for logout, got %d", http.StatusOK, res.StatusCode)
+	}
+	var logout map[string]string
+	helper.Must(json.NewDecoder(res.Body).Decode(&logout))
+	logoutURL, err := url.Parse(logout["url"])
+	helper.Must(err)
+	if logoutURL.Host != "authorization.server" || logoutURL.Path != "/oauth2/logout" {
+		t.Errorf("expected end_session_endpoint, got %q", logout["url"])
+	}
+	query := logoutURL.Query()
+	if query.Get("client_id") != "foo" || query.Get("post_logout_redirect_uri") != "http://back.end:8080/bye" || query.Get("id_token_hint") == "" {
+		t.Errorf("unexpected logout URL query: %v", query)
+	}
+
+	// back-channel logout
+	cookie = login()
+	if res = request(http.MethodGet, "/resource", cookie, nil); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	events := map[string]interface{}{oauth2.BackChannelLogoutEvent: map[string]interface{}{}}
+	exp := time.Now().Add(time.Minute).Unix()
+	logoutToken := newTokenWithTyp(jwt.MapClaims{"jti": "logout1", "exp": exp, "sid": "sid1", "events": events}, "logout+jwt")
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"wrong method", http.MethodGet, "", http.StatusBadRequest},
+		{"missing logout token", http.MethodPost, "foo=bar", http.StatusBadRequest},
+		{"missing event", http.MethodPost, "logout_token=" + newToken(jwt.MapClaims{"jti": "j1", "exp": exp, "sid": "sid1"}), http.StatusBadRequest},
+		{"nonce", http.MethodPost, "logout_token=" + newToken(jwt.MapClaims{"jti": "j2", "exp": exp, "sid": "sid1", "events": events, "nonce": "n"}), http.StatusBadRequest},
+		{"missing sub and sid", http.MethodPost, "logout_token=" + newToken(jwt.MapClaims{"jti": "j3", "exp": exp, "events": events}), http.StatusBadRequest},
+		{"missing jti", http.MethodPost, "logout_token=" + newToken(jwt.MapClaims{"exp": exp, "sid": "sid1", "events": events}), http.StatusBadRequest},
+		{"missing exp", http.MethodPost, "logout_token=" + newToken(jwt.MapClaims{"jti": "j4", "sid": "sid1", "events": events}), http.StatusBadRequest},
+		{"wrong typ", http.MethodPost, "logout_token=" + newTokenWithTyp(jwt.MapClaims{"jti": "j5", "exp": exp, "sid": "sid1", "events": events}, "at+jwt"), http.StatusBadRequest},
+		{"valid", http.MethodPost, "logout_token=" + logoutToken, http.StatusOK},
+		{"replayed", http.MethodPost, "logout_token=" + logoutToken, http.StatusBadRequest},
+	} {
+		var body io.Reader
+		if tc.body != "" {
+			body = strings.NewReader(tc.body)
+		}
+		res = request(tc.method, "/backchannel-logout", nil, body)
+		if res.StatusCode != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, res.StatusCode)
+		}
+		if tc.status == http.StatusOK && !strings.Contains(res.Header.Get("Cache-Control"), "no-store") {
+			t.Errorf("%s: expected Cache-Control no-store, got %q", tc.name, res.Header.Get("Cache-Control"))
+		}
+	}
+
+	if res = request(http.MethodGet, "/resource", cookie, nil); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d after back-channel logout, got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	// a new login is not affected by the former logout
+	time.Sleep(time.Millisecond)
+	if res = request(http.MethodGet, "/resource", login(), nil); res.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d for a new session, got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
 func TestOAuth2_AC_Backend(t *testing.T) {
 	client := newClient()
 	helper := test.New(t)
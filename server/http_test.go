@@ -946,6 +946,93 @@ func TestHTTPServer_RateLimiterBlock(t *testing.T) {
 	mu.Unlock()
 }
 
+func TestHTTPServer_ClientRateLimit(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, hook := newCouper("testdata/integration/ratelimit/02_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name         string
+		path         string
+		apiKey       string
+		expStatus    int
+		expRemaining string
+		expBody      string
+	}
+
+	for _, tc := range []testCase{
+		{"ip 1st", "/ip", "", http.StatusOK, "1", "ok"},
+		{"ip 2nd", "/ip", "", http.StatusOK, "0", "ok"},
+		{"ip exceeded", "/ip", "", http.StatusTooManyRequests, "0", ""},
+		{"key a", "/key", "a", http.StatusOK, "0", "ok"},
+		{"key b", "/key", "b", http.StatusOK, "0", "ok"},
+		{"key a exceeded", "/key", "a", http.StatusTooManyRequests, "0", ""},
+		{"api a", "/api/a", "", http.StatusOK, "1", "a"},
+		{"api b", "/api/b", "", http.StatusOK, "0", "b"},
+		{"api a exceeded", "/api/a", "", http.StatusTooManyRequests, "0", ""},
+		{"custom error_handler", "/api/custom", "", http.StatusServiceUnavailable, "0", "slow down"},
+		{"auth user", "/auth", "pass", http.StatusOK, "1", "auth"},
+		{"auth failed 1st", "/auth", "wrong", http.StatusUnauthorized, "", ""},
+		{"auth failed 2nd", "/auth", "wrong", http.StatusUnauthorized, "", ""},
+		{"auth failed exceeded", "/auth", "wrong", http.StatusTooManyRequests, "0", ""},
+		{"auth user, client ip exceeded", "/auth", "pass", http.StatusTooManyRequests, "0", ""},
+	} {
+		hook.Reset()
+
+		req, err := http.NewRequest(http.MethodGet, "http://anyserver:8080"+tc.path, nil)
+		helper.Must(err)
+
+		if tc.path == "/auth" {
+			req.SetBasicAuth("user", tc.apiKey)
+		} else if tc.apiKey != "" {
+			req.Header.Set("X-Api-Key", tc.apiKey)
+		}
+
+		res, err := client.Do(req)
+		helper.Must(err)
+
+		b, err := io.ReadAll(res.Body)
+		helper.Must(err)
+		helper.Must(res.Body.Close())
+
+		if res.StatusCode != tc.expStatus {
+			t.Errorf("%s: expected status %d, got: %d", tc.name, tc.expStatus, res.StatusCode)
+		}
+
+		if remaining := res.Header.Get("RateLimit-Remaining"); remaining != tc.expRemaining {
+			t.Errorf("%s: expected RateLimit-Remaining %q, got: %q", tc.name, tc.expRemaining, remaining)
+		}
+
+		if tc.expStatus == http.StatusUnauthorized {
+			continue
+		}
+
+		if res.Header.Get("RateLimit-Limit") == "" || res.Header.Get("RateLimit-Reset") == "" {
+			t.Errorf("%s: expected RateLimit-Limit and RateLimit-Reset header", tc.name)
+		}
+
+		if tc.expStatus == http.StatusOK {
+			if retryAfter := res.Header.Get("Retry-After"); retryAfter != "" {
+				t.Errorf("%s: expected no Retry-After header, got: %q", tc.name, retryAfter)
+			}
+		} else {
+			if retryAfter := res.Header.Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+				t.Errorf("%s: expected Retry-After header, got: %q", tc.name, retryAfter)
+			}
+		}
+
+		if tc.expStatus == http.StatusTooManyRequests {
+			if errType := getAccessLogErrorType(hook); errType != "rate_limit_exceeded" {
+				t.Errorf("%s: expected error_type rate_limit_exceeded, got: %q", tc.name, errType)
+			}
+		} else if string(b) != tc.expBody {
+			t.Errorf("%s: expected body %q, got: %q", tc.name, tc.expBody, string(b))
+		}
+	}
+}
+
 func TestHTTPServer_ServerTiming(t *testing.T) {
 	helper := test.New(t)
 	client := newClient()
@@ -0,0 +1,87 @@
+package server_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestIntegration_Introspection(t *testing.T) {
+	client := newClient()
+
+	as := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/introspect" || req.PostFormValue("client_id") != "my-client" ||
+			req.PostFormValue("client_secret") != "my-secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if req.PostFormValue("token") == "opaque" {
+			fmt.Fprint(rw, `{"active":true,"sub":"me","scope":"write"}`)
+			return
+		}
+		fmt.Fprint(rw, `{"active":false}`)
+	}))
+	defer as.Close()
+
+	helper := test.New(t)
+	shutdown, hook, err := newCouperWithTemplate("testdata/integration/introspection/01_couper.hcl", helper,
+		map[string]interface{}{"asOrigin": as.URL})
+	helper.Must(err)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name      string
+		token     string
+		expStatus int
+		expBody   string
+		expErr    string
+	}{
+		{"active", "opaque", http.StatusOK, `{"permissions":["write","read"],"sub":"me"}`, ""},
+		{"inactive", "unknown", http.StatusUnauthorized, "", "introspection"},
+		{"missing", "", http.StatusUnauthorized, "", "introspection_token_missing"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/read", nil)
+			h.Must(err)
+
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				st.Errorf("\nwant:\t%s\ngot:\t%s", tc.expBody, string(b))
+			}
+
+			if tc.expErr != "" {
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
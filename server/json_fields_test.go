@@ -0,0 +1,111 @@
+package server_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestIntegration_JSONFields(t *testing.T) {
+	client := newClient()
+
+	helper := test.New(t)
+	shutdown, hook := newCouper("testdata/integration/json_fields/01_couper.hcl", helper)
+	defer shutdown()
+
+	type testCase struct {
+		name   string
+		path   string
+		ct     string
+		body   string
+		expRes string
+		expLog string
+	}
+
+	for _, tc := range []testCase{
+		{
+			"json",
+			"/",
+			"application/json",
+			`{"user":{"name":"x","secret":"s"},"password":"p","items":["a","b"],"big":12345678901234567890}`,
+			`{"backend":"reflect","big":12345678901234567890,"items":["first","a","b","c"],"meta":{"proxied":true},"user":{"name":"x","role":"admin"}}`,
+			"",
+		},
+		{
+			"json suffix",
+			"/",
+			"application/vnd.api+json",
+			`{"html":"<b>"}`,
+			`{"backend":"reflect","html":"<b>","items":["first","c"],"meta":{"proxied":true},"user":{"role":"admin"}}`,
+			"",
+		},
+		{
+			"no json",
+			"/",
+			"text/plain",
+			`{"password":"p"}`,
+			`{"password":"p"}`,
+			"expression evaluation error: request_json_fields: content-type mismatch: text/plain",
+		},
+		{
+			"invalid json",
+			"/",
+			"application/json",
+			`{"password":`,
+			`{"password":`,
+			"expression evaluation error: request_json_fields: unexpected EOF",
+		},
+		{
+			"response body limit",
+			"/limited",
+			"application/json",
+			`{"items":["a","b","c","d","e","f","g"]}`,
+			`{"backend":"reflect","items":["a","b","c","d","e","f","g"]}`,
+			"expression evaluation error: response_json_fields: body size exceeded: 32B",
+		},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodPost, "http://example.com:8080"+tc.path, bytes.NewBufferString(tc.body))
+			h.Must(err)
+			req.Header.Set("Content-Type", tc.ct)
+			req.Header.Set("X-Item", "c")
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != http.StatusOK {
+				subT.Errorf("expected status 200, got %d", res.StatusCode)
+			}
+
+			if string(b) != tc.expRes {
+				subT.Errorf("\nwant:\t%s\ngot:\t%s", tc.expRes, string(b))
+			}
+
+			if tc.expLog == "" {
+				return
+			}
+
+			var found bool
+			for _, entry := range hook.AllEntries() {
+				if entry.Message == tc.expLog {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				subT.Errorf("expected log message: %s", tc.expLog)
+			}
+		})
+	}
+}
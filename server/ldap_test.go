@@ -0,0 +1,93 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestIntegration_LDAP(t *testing.T) {
+	client := newClient()
+
+	ldapServer := test.NewLDAPServer(nil,
+		test.LDAPEntry{DN: "cn=couper,ou=services,dc=example,dc=com", Password: "s3rv1ce"},
+		test.LDAPEntry{DN: "uid=john,ou=people,dc=example,dc=com", Password: "my-pass", Attributes: map[string][]string{
+			"memberOf": {"cn=admins,ou=groups,dc=example,dc=com"},
+			"uid":      {"john"},
+		}},
+		test.LDAPEntry{DN: "uid=jane,ou=people,dc=example,dc=com", Password: "her-pass", Attributes: map[string][]string{
+			"memberOf": {"cn=users,ou=groups,dc=example,dc=com"},
+			"uid":      {"jane"},
+		}},
+	)
+	defer ldapServer.Close()
+
+	helper := test.New(t)
+	shutdown, hook, err := newCouperWithTemplate("testdata/integration/ldap/01_couper.hcl", helper, map[string]interface{}{
+		"ldapURL": ldapServer.URL(),
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	for _, tc := range []struct {
+		name      string
+		path      string
+		user      string
+		pass      string
+		expStatus int
+		expBody   string
+		expErr    string
+	}{
+		{"group permission", "/read", "jane", "her-pass", http.StatusOK, `{"dn":"uid=jane,ou=people,dc=example,dc=com","groups":["cn=users,ou=groups,dc=example,dc=com"],"user":"jane"}`, ""},
+		{"mapped permission", "/delete", "john", "my-pass", http.StatusOK, `{"dn":"uid=john,ou=people,dc=example,dc=com","groups":["cn=admins,ou=groups,dc=example,dc=com"],"user":"john"}`, ""},
+		{"insufficient permissions", "/delete", "jane", "her-pass", http.StatusForbidden, "", "insufficient_permissions"},
+		{"wrong password", "/read", "jane", "my-pass", http.StatusUnauthorized, "", "ldap"},
+		{"missing credentials", "/read", "", "", http.StatusUnauthorized, "", "ldap_credentials_missing"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			if tc.user != "" {
+				req.SetBasicAuth(tc.user, tc.pass)
+			}
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				st.Errorf("\nwant:\t%s\ngot:\t%s", tc.expBody, string(b))
+			}
+
+			if tc.expStatus == http.StatusUnauthorized {
+				if v := res.Header.Get("Www-Authenticate"); v != `Basic realm="directory"` {
+					st.Errorf("want Www-Authenticate header, got %q", v)
+				}
+			}
+
+			if tc.expErr != "" {
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestIntegration_Signature(t *testing.T) {
+	client := newClient()
+
+	helper := test.New(t)
+	shutdown, hook := newCouper("testdata/integration/signature/01_couper.hcl", helper)
+	defer shutdown()
+
+	slackSignature := func(timestamp, body string) string {
+		mac := hmac.New(sha256.New, []byte("8f742231b10e8888abcd99yyyzzz85a5"))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	for _, tc := range []struct {
+		name      string
+		path      string
+		body      string
+		header    http.Header
+		expStatus int
+		expErr    string
+	}{
+		{"github", "/github", "Hello, World!", http.Header{
+			"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"},
+		}, http.StatusOK, ""},
+		{"github: modified body", "/github", "Hello, World?", http.Header{
+			"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"},
+		}, http.StatusUnauthorized, "signature"},
+		{"github: missing signature", "/github", "Hello, World!", http.Header{}, http.StatusUnauthorized, "signature_missing"},
+		{"slack", "/slack", "token=xyz&team_id=T1", http.Header{
+			"X-Slack-Request-Timestamp": {now},
+			"X-Slack-Signature":         {slackSignature(now, "token=xyz&team_id=T1")},
+		}, http.StatusOK, ""},
+		{"slack: replayed", "/slack", "token=xyz&team_id=T1", http.Header{
+			"X-Slack-Request-Timestamp": {old},
+			"X-Slack-Signature":         {slackSignature(old, "token=xyz&team_id=T1")},
+		}, http.StatusUnauthorized, "signature"},
+		{"slack: modified timestamp", "/slack", "token=xyz&team_id=T1", http.Header{
+			"X-Slack-Request-Timestamp": {now},
+			"X-Slack-Signature":         {slackSignature(old, "token=xyz&team_id=T1")},
+		}, http.StatusUnauthorized, "signature"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodPost, "http://example.com:8080"+tc.path, strings.NewReader(tc.body))
+			h.Must(err)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if res.StatusCode != tc.expStatus {
+				st.Fatalf("want status %d, got %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expStatus == http.StatusOK {
+				switch tc.path {
+				case "/github": // the verified body is passed to the backend
+					var result struct{ Body string }
+					h.Must(json.Unmarshal(b, &result))
+					if result.Body != tc.body {
+						st.Errorf("want backend request body %q, got %q", tc.body, result.Body)
+					}
+				case "/slack":
+					if exp := `{"timestamp":"` + now + `"}`; string(b) != exp {
+						st.Errorf("\nwant:\t%s\ngot:\t%s", exp, string(b))
+					}
+				}
+			}
+
+			if tc.expErr != "" {
+				for _, e := range hook.AllEntries() {
+					if e.Data["type"] != "couper_access" {
+						continue
+					}
+					if e.Data["error_type"] != tc.expErr {
+						st.Errorf("want error type %q, got %q", tc.expErr, e.Data["error_type"])
+					}
+				}
+			}
+		})
+	}
+}
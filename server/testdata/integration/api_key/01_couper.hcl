@@ -0,0 +1,98 @@
+server {
+  api {
+    access_control = ["partners"]
+
+    endpoint "/read" {
+      required_permission = "read"
+
+      response {
+        json_body = request.context.partners
+      }
+    }
+
+    endpoint "/delete" {
+      required_permission = "delete"
+
+      response {
+        json_body = request.context.partners
+      }
+    }
+
+    endpoint "/proxy" {
+      required_permission = "read"
+
+      proxy {
+        backend = "anything"
+      }
+    }
+
+    endpoint "/request" {
+      required_permission = "read"
+
+      request {
+        backend = "anything"
+        headers = request.headers
+      }
+    }
+  }
+
+  endpoint "/cookie" {
+    access_control = ["cookie"]
+
+    proxy {
+      backend = "anything"
+      set_request_headers = {
+        cookie = request.headers.cookie
+      }
+    }
+  }
+
+  endpoint "/query" {
+    access_control = ["query"]
+
+    proxy {
+      backend = "anything"
+    }
+  }
+}
+
+definitions {
+  api_key "partners" {
+    keys = {
+      (env.API_KEY) = {
+        client      = "env-client"
+        permissions = ["read"]
+      }
+    }
+    keys_file = "keys.json"
+
+    permissions_map = {
+      write = ["delete"]
+    }
+  }
+
+  api_key "query" {
+    query_param = "key"
+    keys = {
+      (env.API_KEY) = {}
+    }
+  }
+
+  api_key "cookie" {
+    cookie = "key"
+    keys = {
+      (env.API_KEY) = {}
+    }
+  }
+
+  backend "anything" {
+    path   = "/anything"
+    origin = env.COUPER_TEST_BACKEND_ADDR
+  }
+}
+
+defaults {
+  environment_variables = {
+    API_KEY = "inline-key"
+  }
+}
@@ -0,0 +1,60 @@
+server {
+  endpoint "/users/{user}" {
+    access_control = ["token", "owner"]
+
+    response {
+      json_body = request.context.owner
+    }
+  }
+
+  endpoint "/admin" {
+    access_control = ["token", "admin"]
+
+    response {
+      json_body = request.context.admin
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    signature_algorithm = "HS256"
+    key                 = "s3cr3t"
+  }
+
+  authorization "owner" {
+    log_decisions = true
+
+    rule "admin" {
+      effect    = "allow"
+      condition = contains(request.context.token.roles, "admin")
+    }
+
+    rule "owner" {
+      effect    = "allow"
+      condition = request.context.token.sub == request.path_params.user
+    }
+
+    error_handler "authorization" {
+      response {
+        status    = 404
+        json_body = request.context.owner
+      }
+    }
+  }
+
+  authorization "admin" {
+    order          = "deny_first"
+    default_effect = "deny"
+
+    rule "admin" {
+      effect    = "allow"
+      condition = contains(request.context.token.roles, "admin")
+    }
+
+    rule "read-only" {
+      effect    = "deny"
+      condition = request.method != "GET"
+    }
+  }
+}
@@ -0,0 +1,37 @@
+server {
+  endpoint "/**" {
+    proxy {
+      backend = "cached"
+    }
+
+    set_response_headers = {
+      x-cache-status = backend_responses.default.cache_status
+    }
+  }
+
+  endpoint "/tenant/**" {
+    proxy {
+      backend {
+        origin = "{{ .origin }}"
+      }
+
+      response_cache {
+        cache_key = request.headers.x-tenant
+      }
+    }
+
+    set_response_headers = {
+      x-cache-status = backend_responses.default.cache_status
+    }
+  }
+}
+
+definitions {
+  backend "cached" {
+    origin = "{{ .origin }}"
+
+    response_cache {
+      max_entry_size = "1KiB"
+    }
+  }
+}
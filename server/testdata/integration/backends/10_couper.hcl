@@ -0,0 +1,34 @@
+server {
+  endpoint "/**" {
+    proxy {
+      backend = "retry"
+    }
+  }
+
+  endpoint "/post/**" {
+    request_body_limit = "1KiB"
+
+    proxy {
+      backend {
+        origin = "{{ .origin }}"
+
+        retry {
+          attempts = 2
+          backoff  = "10ms"
+          methods  = ["POST"]
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  backend "retry" {
+    origin = "{{ .origin }}"
+
+    retry {
+      backoff     = "10ms"
+      max_backoff = "20ms"
+    }
+  }
+}
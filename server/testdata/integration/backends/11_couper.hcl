@@ -0,0 +1,24 @@
+server {
+  endpoint "/**" {
+    proxy {
+      backend = "breaker"
+    }
+  }
+
+  endpoint "/state" {
+    response {
+      json_body = backends.breaker.health
+    }
+  }
+}
+
+definitions {
+  backend "breaker" {
+    origin = "{{ .origin }}"
+
+    circuit_breaker {
+      failure_threshold = 2
+      cool_down         = "200ms"
+    }
+  }
+}
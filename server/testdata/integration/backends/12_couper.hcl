@@ -0,0 +1,45 @@
+server {
+  endpoint "/lb" {
+    proxy {
+      backend = "lb"
+    }
+  }
+
+  endpoint "/hash" {
+    proxy {
+      backend = "hash"
+    }
+  }
+
+  endpoint "/state" {
+    response {
+      json_body = backends.lb.health
+    }
+  }
+}
+
+definitions {
+  backend "lb" {
+    origins = ["{{ .origin_a }}", "{{ .origin_b }}"]
+
+    beta_health {
+      path              = "/health"
+      interval          = "100ms"
+      failure_threshold = 1
+    }
+  }
+
+  backend "hash" {
+    load_balancing = "consistent_hash"
+    hash_key       = request.headers.x-user
+
+    upstream {
+      origin = "{{ .origin_a }}"
+    }
+
+    upstream {
+      origin = "{{ .origin_b }}"
+      weight = 2
+    }
+  }
+}
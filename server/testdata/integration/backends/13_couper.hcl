@@ -0,0 +1,50 @@
+server {
+  endpoint "/proxy" {
+    proxy {
+      backend = "primary"
+    }
+
+    set_response_headers = {
+      x-backend = backend_responses.default.backend_name
+    }
+  }
+
+  endpoint "/refused" {
+    request {
+      method = "POST"
+      body   = "payload"
+      backend "refused" {
+        path = "/echo"
+      }
+    }
+
+    set_response_headers = {
+      x-backend = backend_responses.default.backend_name
+    }
+  }
+}
+
+definitions {
+  backend "primary" {
+    origin = "{{ .primary }}"
+
+    fallback {
+      backend = "secondary"
+    }
+  }
+
+  backend "secondary" {
+    origin = "{{ .secondary }}"
+  }
+
+  backend "refused" {
+    origin = "http://127.0.0.1:1"
+
+    fallback {
+      on_errors = ["backend"]
+      backend {
+        origin = "{{ .secondary }}"
+      }
+    }
+  }
+}
@@ -0,0 +1,26 @@
+server {
+  endpoint "/svc.Echo/{method}" {
+    proxy {
+      backend = "grpc"
+    }
+  }
+
+  endpoint "/web/{method}" {
+    proxy {
+      backend  = "grpc"
+      grpc_web = true
+      url      = "/svc.Echo/${request.path_params.method}"
+    }
+  }
+}
+
+definitions {
+  backend "grpc" {
+    origin = "{{ .origin }}"
+    http2  = true
+  }
+}
+
+settings {
+  h2c = true
+}
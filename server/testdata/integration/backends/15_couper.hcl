@@ -0,0 +1,25 @@
+server {
+  endpoint "/sse" {
+    proxy {
+      backend = "events"
+
+      sse {
+        idle_timeout = "500ms"
+        retry        = "3s"
+      }
+    }
+  }
+
+  endpoint "/no-sse" {
+    proxy {
+      backend = "events"
+    }
+  }
+}
+
+definitions {
+  backend "events" {
+    origin  = "{{ .origin }}"
+    timeout = "1s"
+  }
+}
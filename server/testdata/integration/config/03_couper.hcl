@@ -113,6 +113,33 @@ server "acs" {
     }
   }
 
+  endpoint "/jwt/revocation" {
+    disable_access_control = ["ba1"]
+    access_control = ["JWTRevocation"]
+    response {
+      headers = {
+        x-jwt-sub = request.context.JWTRevocation.sub
+        x-granted-permissions = json_encode(request.context.granted_permissions)
+      }
+    }
+  }
+
+  endpoint "/jwt/revocation/sub" {
+    disable_access_control = ["ba1"]
+    access_control = ["JWTRevocationSub"]
+    response {
+      headers = {
+        x-jwt-sub = request.context.JWTRevocationSub.sub
+      }
+    }
+  }
+
+  endpoint "/jwt/dpop" {
+    disable_access_control = ["ba1"]
+    access_control = ["JWTDPoP"]
+    response {}
+  }
+
   endpoint "/jwt/cookie" {
     disable_access_control = ["ba1"]
     access_control = ["JWTTokenCookie"]
@@ -327,6 +354,22 @@ definitions {
     key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
     permissions_claim = "scope"
   }
+  jwt "JWTRevocation" {
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+    permissions_claim = "scope"
+    revocation_url = "file:../files/revocation.json"
+  }
+  jwt "JWTRevocationSub" {
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+    revocation_url = "file:../files/revocation_sub.json"
+  }
+  jwt "JWTDPoP" {
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+    dpop = true
+  }
   jwt "JWTTokenCookie" {
     signature_algorithm = "HS256"
     key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
@@ -0,0 +1,29 @@
+server {
+  api {
+    access_control = ["ti"]
+
+    endpoint "/read" {
+      required_permission = "read"
+
+      response {
+        json_body = {
+          sub         = request.context.ti.sub
+          permissions = request.context.granted_permissions
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  introspection "ti" {
+    introspection_endpoint     = "{{ .asOrigin }}/introspect"
+    client_id                  = "my-client"
+    client_secret              = "my-secret"
+    token_endpoint_auth_method = "client_secret_post"
+
+    permissions_map = {
+      write = ["read"]
+    }
+  }
+}
@@ -0,0 +1,47 @@
+server {
+  endpoint "/" {
+    proxy {
+      backend = "reflect"
+
+      set_request_json_fields = {
+        "/user/role" = "admin"
+      }
+
+      add_request_json_fields = {
+        "$.items[0]" = "first"
+        "/items/-"   = request.headers.x-item
+      }
+
+      remove_request_json_fields = ["$.password"]
+    }
+
+    set_response_json_fields = {
+      "$.meta.proxied" = true
+    }
+
+    remove_response_json_fields = ["/user/secret"]
+  }
+
+  endpoint "/limited" {
+    response_body_limit = "32B"
+
+    proxy {
+      backend = "reflect"
+    }
+
+    set_response_json_fields = {
+      "$.meta.proxied" = true
+    }
+  }
+}
+
+definitions {
+  backend "reflect" {
+    path   = "/reflect"
+    origin = env.COUPER_TEST_BACKEND_ADDR
+
+    set_request_json_fields = {
+      "/backend" = "reflect"
+    }
+  }
+}
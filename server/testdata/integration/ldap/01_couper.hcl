@@ -0,0 +1,41 @@
+server {
+  api {
+    access_control = ["directory"]
+
+    endpoint "/read" {
+      required_permission = "read"
+
+      response {
+        json_body = request.context.directory
+      }
+    }
+
+    endpoint "/delete" {
+      required_permission = "delete"
+
+      response {
+        json_body = request.context.directory
+      }
+    }
+  }
+}
+
+definitions {
+  ldap "directory" {
+    url           = "{{.ldapURL}}"
+    base_dn       = "ou=people,dc=example,dc=com"
+    bind_dn       = "cn=couper,ou=services,dc=example,dc=com"
+    bind_password = "s3rv1ce"
+    user_filter   = "(uid={username})"
+    realm         = "directory"
+
+    group_permissions = {
+      "cn=admins,ou=groups,*" = ["write"]
+      "cn=users,ou=groups,*"  = ["read"]
+    }
+
+    permissions_map = {
+      write = ["delete"]
+    }
+  }
+}
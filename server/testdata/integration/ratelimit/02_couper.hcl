@@ -0,0 +1,86 @@
+server "couper" {
+  endpoint "/ip" {
+    rate_limit {
+      period     = "10s"
+      per_period = 2
+    }
+
+    response {
+      body = "ok"
+    }
+  }
+
+  endpoint "/key" {
+    rate_limit {
+      key           = request.headers.x-api-key
+      period        = "10s"
+      per_period    = 1
+      period_window = "fixed"
+    }
+
+    response {
+      body = "ok"
+    }
+  }
+
+  endpoint "/auth" {
+    access_control = ["ba"]
+
+    rate_limit {
+      key           = request.context.ba.user
+      period        = "10s"
+      per_period    = 2
+      period_window = "fixed"
+    }
+
+    response {
+      body = "auth"
+    }
+  }
+
+  api {
+    base_path = "/api"
+
+    rate_limit {
+      period     = "10s"
+      per_period = 2
+    }
+
+    endpoint "/a" {
+      response {
+        body = "a"
+      }
+    }
+
+    endpoint "/b" {
+      response {
+        body = "b"
+      }
+    }
+
+    endpoint "/custom" {
+      rate_limit {
+        period     = "10s"
+        per_period = 1
+      }
+
+      response {
+        body = "custom"
+      }
+
+      error_handler "rate_limit_exceeded" {
+        response {
+          status = 503
+          body   = "slow down"
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  basic_auth "ba" {
+    user     = "user"
+    password = "pass"
+  }
+}
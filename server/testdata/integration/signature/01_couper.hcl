@@ -0,0 +1,33 @@
+server {
+  endpoint "/github" {
+    access_control = ["github"]
+
+    proxy {
+      url = "${env.COUPER_TEST_BACKEND_ADDR}/anything"
+    }
+  }
+
+  endpoint "/slack" {
+    access_control = ["slack"]
+
+    response {
+      json_body = request.context.slack
+    }
+  }
+}
+
+definitions {
+  signature "github" {
+    key    = "It's a Secret to Everybody"
+    header = "X-Hub-Signature-256"
+    prefix = "sha256="
+  }
+
+  signature "slack" {
+    key              = "8f742231b10e8888abcd99yyyzzz85a5"
+    header           = "X-Slack-Signature"
+    prefix           = "v0="
+    string_to_sign   = "v0:{timestamp}:{body}"
+    timestamp_header = "X-Slack-Request-Timestamp"
+  }
+}
@@ -0,0 +1,67 @@
+server {
+  hosts = ["*:4443"]
+
+  api {
+    access_control = ["mtls"]
+
+    endpoint "/read" {
+      required_permission = "read"
+
+      response {
+        json_body = {
+          subject     = request.context.mtls.subject
+          fingerprint = request.context.mtls.fingerprint
+          permissions = request.context.granted_permissions
+        }
+      }
+    }
+
+    endpoint "/admin" {
+      required_permission = "admin"
+
+      response {}
+    }
+
+    endpoint "/partner" {
+      access_control      = ["partner"]
+      required_permission = ""
+
+      response {}
+    }
+  }
+
+  tls {
+    server_certificate {
+      public_key = <<-EOC
+{{ .publicKey }}
+EOC
+      private_key = <<-EOC
+{{ .privateKey }}
+EOC
+    }
+
+    client_certificate {
+      ca_certificate = <<-EOC
+{{ .clientCA }}
+EOC
+    }
+  }
+}
+
+definitions {
+  client_certificate "mtls" {
+    allowed_subjects = ["*O=Couper*"]
+
+    subject_permissions = {
+      "*OU=Development*" = ["develop"]
+    }
+
+    permissions_map = {
+      develop = ["read"]
+    }
+  }
+
+  client_certificate "partner" {
+    allowed_subjects = ["CN=partner*"]
+  }
+}
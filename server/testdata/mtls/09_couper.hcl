@@ -0,0 +1,40 @@
+server {
+  hosts = ["*:4443"]
+
+  api {
+    access_control = ["bound"]
+
+    endpoint "/" {
+      response {
+        json_body = {
+          sub = request.context.bound.sub
+        }
+      }
+    }
+  }
+
+  tls {
+    server_certificate {
+      public_key = <<-EOC
+{{ .publicKey }}
+EOC
+      private_key = <<-EOC
+{{ .privateKey }}
+EOC
+    }
+
+    client_certificate {
+      ca_certificate = <<-EOC
+{{ .clientCA }}
+EOC
+    }
+  }
+}
+
+definitions {
+  jwt "bound" {
+    signature_algorithm = "HS256"
+    key               = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+    certificate_bound = true
+  }
+}
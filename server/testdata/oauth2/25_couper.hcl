@@ -0,0 +1,43 @@
+server "client" {
+  api {
+    endpoint "/cb" {
+      access_control = ["ac"]
+      response {
+        body = "logged in"
+      }
+    }
+
+    endpoint "/resource" {
+      access_control = ["sess"]
+      proxy {
+        url = "{{.asOrigin}}/resource"
+      }
+    }
+
+    endpoint "/logout" {
+      access_control = ["sess"]
+      response {
+        json_body = {
+          id_token = request.context.sess.id_token
+        }
+      }
+    }
+  }
+}
+definitions {
+  beta_oauth2 "ac" {
+    grant_type = "authorization_code"
+    redirect_uri = "http://localhost:8080/cb" # value is not checked
+    authorization_endpoint = "https://authorization.server/oauth2/authorize"
+    token_endpoint = "{{.asOrigin}}/token"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    verifier_method = "ccm_s256"
+    verifier_value = request.cookies.pkcecv
+  }
+
+  session "sess" {
+    oauth2 = "ac"
+    logout_path = "/logout"
+  }
+}
@@ -0,0 +1,51 @@
+server "client" {
+  api {
+    endpoint "/cb" {
+      access_control = ["ac"]
+      response {
+        body = "logged in"
+      }
+    }
+
+    endpoint "/resource" {
+      access_control = ["sess"]
+      response {
+        json_body = request.context.sess.id_token_claims
+      }
+    }
+
+    endpoint "/logout" {
+      access_control = ["sess"]
+      response {
+        json_body = {
+          url = oidc_logout_url("ac", request.context.sess.id_token)
+        }
+      }
+    }
+
+    endpoint "/backchannel-logout" {
+      access_control = ["sess"]
+      response {
+        json_body = request.context.sess
+      }
+    }
+  }
+}
+
+definitions {
+  oidc "ac" {
+    configuration_url = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    redirect_uri = "http://localhost:8080/cb" # value is not checked
+    post_logout_redirect_uri = "/bye"
+    verifier_method = "nonce"
+    verifier_value = request.cookies.nnc
+  }
+
+  session "sess" {
+    oauth2 = "ac"
+    logout_path = "/logout"
+    backchannel_logout_path = "/backchannel-logout"
+  }
+}
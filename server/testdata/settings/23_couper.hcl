@@ -0,0 +1,23 @@
+server {
+  endpoint "/" {
+    proxy {
+      backend = "a"
+    }
+  }
+}
+
+definitions {
+  backend "a" {
+    origin = "http://a.test"
+    fallback {
+      backend = "b"
+    }
+  }
+
+  backend "b" {
+    origin = "http://b.test"
+    fallback {
+      backend = "a"
+    }
+  }
+}
@@ -8,6 +8,9 @@ import (
 	"net"
 	"net/http"
 	"regexp"
+	"strings"
+
+	"github.com/coupergateway/couper/internal/grpc"
 )
 
 const (
@@ -48,6 +51,10 @@ func NewGzipWriter(rw http.ResponseWriter, header http.Header) *Gzip {
 
 // Write fills a small buffer first to determine if a compression is required or not.
 func (g *Gzip) Write(p []byte) (n int, err error) {
+	if g.bypass() {
+		return g.rw.Write(p)
+	}
+
 	b := p[:]
 	bytesLen := len(p)
 	bufLen := g.buffer.Len()
@@ -146,7 +153,7 @@ func (g *Gzip) writeHeader() {
 }
 
 func (g *Gzip) Flush() {
-	if l := g.buffer.Len(); l < minCompressBodyLength {
+	if l := g.buffer.Len(); l < minCompressBodyLength && !g.bypass() {
 		// We have to wait for minCompressBodyLength bytes to be
 		// able to determine, if we enable GZIP compression or not.
 		return
@@ -163,6 +170,19 @@ func (g *Gzip) Flush() {
 	}
 }
 
+// bypass disables the compression and buffering for gRPC messages and event streams
+// which must be written without delay.
+func (g *Gzip) bypass() bool {
+	if !g.headerSent && g.buffer.Len() == 0 && g.statusCode > 0 {
+		ct := g.rw.Header().Get("Content-Type")
+		if grpc.IsGRPC(ct) || grpc.IsGRPCWeb(ct) || strings.HasPrefix(ct, "text/event-stream") {
+			g.enabled = false
+			g.writeHeader()
+		}
+	}
+	return g.headerSent && !g.enabled
+}
+
 func (g *Gzip) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hijack, ok := g.rw.(http.Hijacker)
 	if !ok {
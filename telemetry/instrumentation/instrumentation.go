@@ -6,6 +6,7 @@ const (
 
 	BackendInstrumentationName = "couper/backend"
 
+	BackendCircuitBreakerState = Prefix + "backend_circuit_breaker_state"
 	BackendConnections         = Prefix + "backend_connections_count"
 	BackendConnectionsLifetime = Prefix + "backend_connections_lifetime_seconds"
 	BackendConnectionsTotal    = Prefix + "backend_connections"
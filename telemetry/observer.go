@@ -12,7 +12,7 @@ import (
 	"github.com/coupergateway/couper/telemetry/provider"
 )
 
-func newBackendsObserver(memStore *cache.MemoryStore) error {
+func newBackendsObserver(memStore cache.Store) error {
 	bs := memStore.GetAllWithPrefix("backend_")
 	var backends []interface{ Value() cty.Value }
 	for _, b := range bs {
@@ -23,16 +23,25 @@ func newBackendsObserver(memStore *cache.MemoryStore) error {
 
 	meter := provider.Meter(instrumentation.BackendInstrumentationName)
 	gauge, _ := meter.Int64ObservableGauge(instrumentation.BackendHealthState)
+	circuitGauge, _ := meter.Int64ObservableGauge(instrumentation.BackendCircuitBreakerState,
+		metric.WithDescription("0: closed, 1: half_open, 2: open"))
 
 	onObserverFn := func(_ context.Context, observer metric.Observer) error {
-		return backendsObserver(gauge, observer, backends)
+		return backendsObserver(gauge, circuitGauge, observer, backends)
 	}
 
-	_, err := meter.RegisterCallback(onObserverFn, gauge)
+	_, err := meter.RegisterCallback(onObserverFn, gauge, circuitGauge)
 	return err
 }
 
-func backendsObserver(gauge metric.Int64Observable, observer metric.Observer, backends []interface{ Value() cty.Value }) error {
+// circuitStates maps the circuit breaker states to their gauge values.
+var circuitStates = map[string]int64{
+	"closed":    0,
+	"half_open": 1,
+	"open":      2,
+}
+
+func backendsObserver(gauge, circuitGauge metric.Int64Observable, observer metric.Observer, backends []interface{ Value() cty.Value }) error {
 	for _, backend := range backends {
 		v := backend.Value().AsValueMap()
 		attrs := []attribute.KeyValue{
@@ -48,6 +57,10 @@ func backendsObserver(gauge metric.Int64Observable, observer metric.Observer, ba
 
 		option := metric.WithAttributes(attrs...)
 		observer.ObserveInt64(gauge, value, option)
+
+		if circuitState, ok := health["circuit_breaker"]; ok {
+			observer.ObserveInt64(circuitGauge, circuitStates[circuitState.AsString()], option)
+		}
 	}
 	return nil
 }
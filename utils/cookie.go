@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RemoveCookies removes the named cookies from the Cookie header fields of the given header.
+// Header fields without remaining cookies are deleted.
+func RemoveCookies(header http.Header, names []string) {
+	values := header.Values("Cookie")
+	if len(values) == 0 || len(names) == 0 {
+		return
+	}
+
+	var result []string
+	for _, value := range values {
+		var cookies []string
+		for _, cookie := range strings.Split(value, ";") {
+			name, _, _ := strings.Cut(strings.TrimSpace(cookie), "=")
+			if name == "" || containsString(names, name) {
+				continue
+			}
+			cookies = append(cookies, strings.TrimSpace(cookie))
+		}
+		if len(cookies) > 0 {
+			result = append(result, strings.Join(cookies, "; "))
+		}
+	}
+
+	header.Del("Cookie")
+	for _, value := range result {
+		header.Add("Cookie", value)
+	}
+}
@@ -0,0 +1,32 @@
+package utils_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/coupergateway/couper/utils"
+)
+
+func TestUtils_RemoveCookies(t *testing.T) {
+	for _, tc := range []struct {
+		cookies, exp []string
+		names        []string
+	}{
+		{nil, nil, []string{"key"}},
+		{[]string{"a=1; key=secret"}, []string{"a=1; key=secret"}, nil},
+		{[]string{"a=1; key=secret; b=2"}, []string{"a=1; b=2"}, []string{"key"}},
+		{[]string{"key=secret", "a=1;key=other"}, []string{"a=1"}, []string{"key"}},
+		{[]string{"key=secret"}, nil, []string{"key"}},
+	} {
+		header := http.Header{}
+		for _, c := range tc.cookies {
+			header.Add("Cookie", c)
+		}
+
+		utils.RemoveCookies(header, tc.names)
+		if got := header.Values("Cookie"); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("%q: want %q, got %q", tc.cookies, tc.exp, got)
+		}
+	}
+}
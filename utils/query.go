@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+const queryValueMask = "***"
+
+// RemoveQueryParams removes the named parameters from the given raw query.
+// The order and the encoding of the remaining parameters are kept.
+func RemoveQueryParams(rawQuery string, names []string) string {
+	return filterQuery(rawQuery, names, false)
+}
+
+// MaskQueryParams replaces the values of the named parameters in the given raw query.
+func MaskQueryParams(rawQuery string, names []string) string {
+	return filterQuery(rawQuery, names, true)
+}
+
+func filterQuery(rawQuery string, names []string, mask bool) string {
+	if rawQuery == "" || len(names) == 0 {
+		return rawQuery
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	result := parts[:0]
+	for _, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && containsString(names, name) {
+			if mask {
+				result = append(result, key+"="+queryValueMask)
+			}
+			continue
+		}
+		result = append(result, part)
+	}
+
+	return strings.Join(result, "&")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/coupergateway/couper/utils"
+)
+
+func TestUtils_RemoveQueryParams(t *testing.T) {
+	for _, tc := range []struct {
+		rawQuery, exp string
+		names         []string
+	}{
+		{"", "", []string{"key"}},
+		{"a=1&key=secret&b=2", "a=1&key=secret&b=2", nil},
+		{"a=1&key=secret&b=2", "a=1&b=2", []string{"key"}},
+		{"key=1&key=2", "", []string{"key"}},
+		{"k%65y=secret&b=c+d", "b=c+d", []string{"key"}},
+		{"key&b=2", "b=2", []string{"key"}},
+	} {
+		if q := utils.RemoveQueryParams(tc.rawQuery, tc.names); q != tc.exp {
+			t.Errorf("%q: want %q, got %q", tc.rawQuery, tc.exp, q)
+		}
+	}
+}
+
+func TestUtils_MaskQueryParams(t *testing.T) {
+	if q := utils.MaskQueryParams("a=1&key=secret&b=2", []string{"key"}); q != "a=1&key=***&b=2" {
+		t.Errorf("Unexpected query %q given", q)
+	}
+}